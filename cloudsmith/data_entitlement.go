@@ -1,70 +1,79 @@
 package cloudsmith
 
 import (
+	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloudsmith-io/cloudsmith-api-go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func flattenEntitlementToken(token []cloudsmith.RepositoryToken) []interface{} {
 	tokenList := make([]interface{}, len(token))
 
 	for i, t := range token {
-		tokenMap := make(map[string]interface{})
-		tokenMap["access_private_broadcasts"] = t.GetAccessPrivateBroadcasts()
-		tokenMap["clients"] = t.GetClients()
-		tokenMap["created_at"] = t.GetCreatedAt().Format(time.RFC3339)
-		tokenMap["created_by"] = t.GetCreatedBy()
-		tokenMap["default"] = t.GetDefault()
-		tokenMap["downloads"] = t.GetDownloads()
-		tokenMap["disable_url"] = t.GetDisableUrl()
-		tokenMap["enable_url"] = t.GetEnableUrl()
-		tokenMap["eula_required"] = t.GetEulaRequired()
-		tokenMap["has_limits"] = t.GetHasLimits()
-		tokenMap["identifier"] = t.GetIdentifier()
-		tokenMap["is_active"] = t.GetIsActive()
-		tokenMap["is_limited"] = t.GetIsLimited()
-		tokenMap["limit_bandwidth"] = t.GetLimitBandwidth()
-		tokenMap["limit_bandwidth_unit"] = t.GetLimitBandwidthUnit()
-		tokenMap["limit_date_range_from"] = t.GetLimitDateRangeFrom().Format(time.RFC3339)
-		tokenMap["limit_date_range_to"] = t.GetLimitDateRangeTo().Format(time.RFC3339)
-		tokenMap["limit_num_clients"] = t.GetLimitNumClients()
-		tokenMap["limit_num_downloads"] = t.GetLimitNumDownloads()
-		tokenMap["limit_package_query"] = t.GetLimitPackageQuery()
-		tokenMap["limit_path_query"] = t.GetLimitPathQuery()
-		tokenMap["metadata"] = t.GetMetadata()
-		tokenMap["name"] = t.GetName()
-		tokenMap["refresh_url"] = t.GetRefreshUrl()
-		tokenMap["reset_url"] = t.GetResetUrl()
-		tokenMap["scheduled_reset_at"] = t.GetScheduledResetAt().Format(time.RFC3339)
-		tokenMap["scheduled_reset_period"] = t.GetScheduledResetPeriod()
-		tokenMap["self_url"] = t.GetSelfUrl()
-		tokenMap["slug_perm"] = t.GetSlugPerm()
-		tokenMap["token"] = t.GetToken()
-		tokenMap["updated_at"] = t.GetUpdatedAt().Format(time.RFC3339)
-		tokenMap["updated_by"] = t.GetUpdatedBy()
-		tokenMap["updated_by_url"] = t.GetUpdatedByUrl()
-		tokenMap["usage"] = t.GetUsage()
-		tokenMap["user"] = t.GetUser()
-		tokenMap["user_url"] = t.GetUserUrl()
-
-		tokenList[i] = tokenMap
+		tokenList[i] = flattenEntitlement(t)
 	}
 
 	return tokenList
 }
 
+// flattenEntitlement returns the attributes of entitlementTokenSchema for a
+// single entitlement token.
+func flattenEntitlement(t cloudsmith.RepositoryToken) map[string]interface{} {
+	tokenMap := make(map[string]interface{})
+	tokenMap["access_private_broadcasts"] = t.GetAccessPrivateBroadcasts()
+	tokenMap["clients"] = t.GetClients()
+	tokenMap["created_at"] = t.GetCreatedAt().Format(time.RFC3339)
+	tokenMap["created_by"] = t.GetCreatedBy()
+	tokenMap["default"] = t.GetDefault()
+	tokenMap["downloads"] = t.GetDownloads()
+	tokenMap["disable_url"] = t.GetDisableUrl()
+	tokenMap["enable_url"] = t.GetEnableUrl()
+	tokenMap["eula_required"] = t.GetEulaRequired()
+	tokenMap["has_limits"] = t.GetHasLimits()
+	tokenMap["identifier"] = t.GetIdentifier()
+	tokenMap["is_active"] = t.GetIsActive()
+	tokenMap["is_limited"] = t.GetIsLimited()
+	tokenMap["last_used_at"] = timeToString(t.GetLastUsedAt())
+	tokenMap["limit_bandwidth"] = t.GetLimitBandwidth()
+	tokenMap["limit_bandwidth_unit"] = t.GetLimitBandwidthUnit()
+	tokenMap["limit_date_range_from"] = t.GetLimitDateRangeFrom().Format(time.RFC3339)
+	tokenMap["limit_date_range_to"] = t.GetLimitDateRangeTo().Format(time.RFC3339)
+	tokenMap["limit_num_clients"] = t.GetLimitNumClients()
+	tokenMap["limit_num_downloads"] = t.GetLimitNumDownloads()
+	tokenMap["limit_package_query"] = t.GetLimitPackageQuery()
+	tokenMap["limit_path_query"] = t.GetLimitPathQuery()
+	tokenMap["metadata"] = t.GetMetadata()
+	tokenMap["name"] = t.GetName()
+	tokenMap["refresh_url"] = t.GetRefreshUrl()
+	tokenMap["reset_url"] = t.GetResetUrl()
+	tokenMap["scheduled_reset_at"] = t.GetScheduledResetAt().Format(time.RFC3339)
+	tokenMap["scheduled_reset_period"] = t.GetScheduledResetPeriod()
+	tokenMap["self_url"] = t.GetSelfUrl()
+	tokenMap["slug_perm"] = t.GetSlugPerm()
+	tokenMap["token"] = t.GetToken()
+	tokenMap["updated_at"] = t.GetUpdatedAt().Format(time.RFC3339)
+	tokenMap["updated_by"] = t.GetUpdatedBy()
+	tokenMap["updated_by_url"] = t.GetUpdatedByUrl()
+	tokenMap["usage"] = t.GetUsage()
+	tokenMap["user"] = t.GetUser()
+	tokenMap["user_url"] = t.GetUserUrl()
+
+	return tokenMap
+}
+
 func dataSourceEntitlementRead(d *schema.ResourceData, m interface{}) error {
 	pc := m.(*providerConfig)
 
 	namespace := requiredString(d, "namespace")
 	repository := requiredString(d, "repository")
 	query := buildQueryString(d.Get("query").(*schema.Set))
-	showTokenVal := *optionalBool(d, "show_token")
-	activeTokenVal := *optionalBool(d, "active_token")
+	showTokenVal := requiredBool(d, "show_token")
+	activeTokenVal := requiredBool(d, "active_token")
 
 	exec := func(page, ps int64) ([]cloudsmith.RepositoryToken, *http.Response, error) {
 		req := pc.APIClient.EntitlementsApi.EntitlementsList(pc.Auth, namespace, repository).
@@ -80,15 +89,43 @@ func dataSourceEntitlementRead(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
+	if limitPackageQuery, ok := d.GetOk("limit_package_query"); ok {
+		entitlementList = filterEntitlementsByPackageQuery(entitlementList, limitPackageQuery.(string))
+	}
+
 	tokens := flattenEntitlementToken(entitlementList)
+	if requiredBool(d, "show_bandwidth") {
+		for i, token := range tokens {
+			tokenMap := token.(map[string]interface{})
+			bandwidth, err := readEntitlementBandwidth(pc, namespace, repository, entitlementList[i].GetSlugPerm())
+			if err != nil {
+				return err
+			}
+			tokenMap["bandwidth_used"] = bandwidth.bandwidth
+			tokenMap["bandwidth_used_unit"] = bandwidth.unit
+		}
+	}
 	if err := d.Set("entitlement_tokens", tokens); err != nil {
 		return err
 	}
 
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(fmt.Sprintf("%s/%s/entitlements", namespace, repository))
 
 	return nil
+}
 
+// filterEntitlementsByPackageQuery returns the entitlements whose
+// limit_package_query is query, ignoring surrounding whitespace.
+func filterEntitlementsByPackageQuery(entitlements []cloudsmith.RepositoryToken, query string) []cloudsmith.RepositoryToken {
+	query = strings.TrimSpace(query)
+
+	var filtered []cloudsmith.RepositoryToken
+	for _, e := range entitlements {
+		if strings.TrimSpace(e.GetLimitPackageQuery()) == query {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
 }
 
 func dataSourceEntitlementList() *schema.Resource {
@@ -124,198 +161,231 @@ func dataSourceEntitlementList() *schema.Resource {
 				Optional:    true,
 				Default:     false,
 			},
+			"limit_package_query": {
+				Type:         schema.TypeString,
+				Description:  "If set, only include tokens restricted to exactly this package query.",
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"show_bandwidth": {
+				Type:        schema.TypeBool,
+				Description: "Include the bandwidth used by each token in results. This reads the metrics of every token, one request per token.",
+				Optional:    true,
+				Default:     false,
+			},
 			"entitlement_tokens": {
 				Type:     schema.TypeList,
 				Computed: true,
 				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"access_private_broadcasts": {
-							Type:        schema.TypeBool,
-							Description: "If enabled, this token can be used for private broadcasts.",
-							Computed:    true,
-						},
-						"clients": {
-							Type:        schema.TypeInt,
-							Description: "Number of clients associated with the entitlement token.",
-							Computed:    true,
-						},
-						"created_at": {
-							Type:        schema.TypeString,
-							Description: "The datetime the token was created at.",
-							Computed:    true,
-						},
-						"created_by": {
-							Type:        schema.TypeString,
-							Description: "The user who created the entitlement token.",
-							Computed:    true,
-						},
-						"default": {
-							Type:        schema.TypeBool,
-							Description: "If selected this is the default token for this repository.",
-							Computed:    true,
-						},
-						"downloads": {
-							Type:        schema.TypeInt,
-							Description: "Number of downloads associated with the entitlement token.",
-							Computed:    true,
-						},
-						"disable_url": {
-							Type:        schema.TypeString,
-							Description: "URL to disable the entitlement token.",
-							Computed:    true,
-						},
-						"enable_url": {
-							Type:        schema.TypeString,
-							Description: "URL to enable the entitlement token.",
-							Computed:    true,
-						},
-						"eula_required": {
-							Type:        schema.TypeBool,
-							Description: "If checked, a EULA acceptance is required for this token.",
-							Computed:    true,
-						},
-						"has_limits": {
-							Type:        schema.TypeBool,
-							Description: "Indicates if there are limits set for the token.",
-							Computed:    true,
-						},
-						"identifier": {
-							Type:        schema.TypeInt,
-							Description: "A unique identifier for the entitlement token.",
-							Computed:    true,
-						},
-						"is_active": {
-							Type:        schema.TypeBool,
-							Description: "If enabled, the token will allow downloads based on configured restrictions (if any).",
-							Computed:    true,
-						},
-						"is_limited": {
-							Type:        schema.TypeBool,
-							Description: "Indicates if the token is limited.",
-							Computed:    true,
-						},
-						"limit_bandwidth": {
-							Type:        schema.TypeInt,
-							Description: "The maximum download bandwidth allowed for the token.",
-							Computed:    true,
-						},
-						"limit_bandwidth_unit": {
-							Type:        schema.TypeString,
-							Description: "Unit of bandwidth for the maximum download bandwidth.",
-							Computed:    true,
-						},
-						"limit_date_range_from": {
-							Type:        schema.TypeString,
-							Description: "The starting date/time the token is allowed to be used from.",
-							Computed:    true,
-						},
-						"limit_date_range_to": {
-							Type:        schema.TypeString,
-							Description: "The ending date/time the token is allowed to be used until.",
-							Computed:    true,
-						},
-						"limit_num_clients": {
-							Type:        schema.TypeInt,
-							Description: "The maximum number of unique clients allowed for the token.",
-							Computed:    true,
-						},
-						"limit_num_downloads": {
-							Type:        schema.TypeInt,
-							Description: "The maximum number of downloads allowed for the token.",
-							Computed:    true,
-						},
-						"limit_package_query": {
-							Type:        schema.TypeString,
-							Description: "The package-based search query to apply to restrict downloads.",
-							Computed:    true,
-						},
-						"limit_path_query": {
-							Type:        schema.TypeString,
-							Description: "The path-based search query to apply to restrict downloads.",
-							Computed:    true,
-						},
-						"metadata": {
-							Type:        schema.TypeMap,
-							Description: "Additional metadata associated with the entitlement token.",
-							Computed:    true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-						},
-						"name": {
-							Type:        schema.TypeString,
-							Description: "The name of the entitlement token.",
-							Computed:    true,
-						},
-						"refresh_url": {
-							Type:        schema.TypeString,
-							Description: "URL to refresh the entitlement token.",
-							Computed:    true,
-						},
-						"reset_url": {
-							Type:        schema.TypeString,
-							Description: "URL to reset the entitlement token.",
-							Computed:    true,
-						},
-						"scheduled_reset_at": {
-							Type:        schema.TypeString,
-							Description: "The time at which the scheduled reset period has elapsed and the token limits were automatically reset to zero.",
-							Computed:    true,
-						},
-						"scheduled_reset_period": {
-							Type:        schema.TypeString,
-							Description: "The period after which the token limits are automatically reset to zero.",
-							Computed:    true,
-						},
-						"self_url": {
-							Type:        schema.TypeString,
-							Description: "URL for the entitlement token itself.",
-							Computed:    true,
-						},
-						"slug_perm": {
-							Type:        schema.TypeString,
-							Description: "Slug permission associated with the entitlement token.",
-							Computed:    true,
-						},
-						"token": {
-							Type:        schema.TypeString,
-							Description: "The entitlement token string.",
-							Computed:    true,
-							Sensitive:   true,
-						},
-						"updated_at": {
-							Type:        schema.TypeString,
-							Description: "The datetime the token was updated at.",
-							Computed:    true,
-						},
-						"updated_by": {
-							Type:        schema.TypeString,
-							Description: "The user who updated the entitlement token.",
-							Computed:    true,
-						},
-						"updated_by_url": {
-							Type:        schema.TypeString,
-							Description: "URL for the user who updated the entitlement token.",
-							Computed:    true,
-						},
-						"usage": {
-							Type:        schema.TypeString,
-							Description: "The usage associated with the token.",
-							Computed:    true,
-						},
-						"user": {
-							Type:        schema.TypeString,
-							Description: "The user associated with the token.",
-							Computed:    true,
-						},
-						"user_url": {
-							Type:        schema.TypeString,
-							Description: "URL for the user associated with the token.",
-							Computed:    true,
-						},
-					},
+					Schema: entitlementTokenSchema(),
 				},
 			},
 		},
 	}
 }
+
+// entitlementTokenSchema returns the computed attributes of an entitlement
+// token, as set by flattenEntitlement.
+func entitlementTokenSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"access_private_broadcasts": {
+			Type:        schema.TypeBool,
+			Description: "If enabled, this token can be used for private broadcasts.",
+			Computed:    true,
+		},
+		"bandwidth_used": {
+			Type:        schema.TypeFloat,
+			Description: "The bandwidth used by the token, in `bandwidth_used_unit`. Only set if `show_bandwidth` is enabled.",
+			Computed:    true,
+		},
+		"bandwidth_used_unit": {
+			Type:        schema.TypeString,
+			Description: "The unit of `bandwidth_used`. Only set if `show_bandwidth` is enabled.",
+			Computed:    true,
+		},
+		"clients": {
+			Type:        schema.TypeInt,
+			Description: "Number of clients associated with the entitlement token.",
+			Computed:    true,
+		},
+		"created_at": {
+			Type:        schema.TypeString,
+			Description: "The datetime the token was created at.",
+			Computed:    true,
+		},
+		"created_by": {
+			Type:        schema.TypeString,
+			Description: "The user who created the entitlement token.",
+			Computed:    true,
+		},
+		"default": {
+			Type:        schema.TypeBool,
+			Description: "If selected this is the default token for this repository.",
+			Computed:    true,
+		},
+		"downloads": {
+			Type:        schema.TypeInt,
+			Description: "Number of downloads associated with the entitlement token.",
+			Computed:    true,
+		},
+		"disable_url": {
+			Type:        schema.TypeString,
+			Description: "URL to disable the entitlement token.",
+			Computed:    true,
+		},
+		"enable_url": {
+			Type:        schema.TypeString,
+			Description: "URL to enable the entitlement token.",
+			Computed:    true,
+		},
+		"eula_required": {
+			Type:        schema.TypeBool,
+			Description: "If checked, a EULA acceptance is required for this token.",
+			Computed:    true,
+		},
+		"has_limits": {
+			Type:        schema.TypeBool,
+			Description: "Indicates if there are limits set for the token.",
+			Computed:    true,
+		},
+		"identifier": {
+			Type:        schema.TypeInt,
+			Description: "A unique identifier for the entitlement token.",
+			Computed:    true,
+		},
+		"is_active": {
+			Type:        schema.TypeBool,
+			Description: "If enabled, the token will allow downloads based on configured restrictions (if any).",
+			Computed:    true,
+		},
+		"is_limited": {
+			Type:        schema.TypeBool,
+			Description: "Indicates if the token is limited.",
+			Computed:    true,
+		},
+		"last_used_at": {
+			Type:        schema.TypeString,
+			Description: "The datetime the token was last used to download a package. Empty if it has never been used.",
+			Computed:    true,
+		},
+		"limit_bandwidth": {
+			Type:        schema.TypeInt,
+			Description: "The maximum download bandwidth allowed for the token.",
+			Computed:    true,
+		},
+		"limit_bandwidth_unit": {
+			Type:        schema.TypeString,
+			Description: "Unit of bandwidth for the maximum download bandwidth.",
+			Computed:    true,
+		},
+		"limit_date_range_from": {
+			Type:        schema.TypeString,
+			Description: "The starting date/time the token is allowed to be used from.",
+			Computed:    true,
+		},
+		"limit_date_range_to": {
+			Type:        schema.TypeString,
+			Description: "The ending date/time the token is allowed to be used until.",
+			Computed:    true,
+		},
+		"limit_num_clients": {
+			Type:        schema.TypeInt,
+			Description: "The maximum number of unique clients allowed for the token.",
+			Computed:    true,
+		},
+		"limit_num_downloads": {
+			Type:        schema.TypeInt,
+			Description: "The maximum number of downloads allowed for the token.",
+			Computed:    true,
+		},
+		"limit_package_query": {
+			Type:        schema.TypeString,
+			Description: "The package-based search query to apply to restrict downloads.",
+			Computed:    true,
+		},
+		"limit_path_query": {
+			Type:        schema.TypeString,
+			Description: "The path-based search query to apply to restrict downloads.",
+			Computed:    true,
+		},
+		"metadata": {
+			Type:        schema.TypeMap,
+			Description: "Additional metadata associated with the entitlement token.",
+			Computed:    true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the entitlement token.",
+			Computed:    true,
+		},
+		"refresh_url": {
+			Type:        schema.TypeString,
+			Description: "URL to refresh the entitlement token.",
+			Computed:    true,
+		},
+		"reset_url": {
+			Type:        schema.TypeString,
+			Description: "URL to reset the entitlement token.",
+			Computed:    true,
+		},
+		"scheduled_reset_at": {
+			Type:        schema.TypeString,
+			Description: "The time at which the scheduled reset period has elapsed and the token limits were automatically reset to zero.",
+			Computed:    true,
+		},
+		"scheduled_reset_period": {
+			Type:        schema.TypeString,
+			Description: "The period after which the token limits are automatically reset to zero.",
+			Computed:    true,
+		},
+		"self_url": {
+			Type:        schema.TypeString,
+			Description: "URL for the entitlement token itself.",
+			Computed:    true,
+		},
+		"slug_perm": {
+			Type:        schema.TypeString,
+			Description: "Slug permission associated with the entitlement token.",
+			Computed:    true,
+		},
+		"token": {
+			Type:        schema.TypeString,
+			Description: "The entitlement token string.",
+			Computed:    true,
+			Sensitive:   true,
+		},
+		"updated_at": {
+			Type:        schema.TypeString,
+			Description: "The datetime the token was updated at.",
+			Computed:    true,
+		},
+		"updated_by": {
+			Type:        schema.TypeString,
+			Description: "The user who updated the entitlement token.",
+			Computed:    true,
+		},
+		"updated_by_url": {
+			Type:        schema.TypeString,
+			Description: "URL for the user who updated the entitlement token.",
+			Computed:    true,
+		},
+		"usage": {
+			Type:        schema.TypeString,
+			Description: "The usage associated with the token.",
+			Computed:    true,
+		},
+		"user": {
+			Type:        schema.TypeString,
+			Description: "The user associated with the token.",
+			Computed:    true,
+		},
+		"user_url": {
+			Type:        schema.TypeString,
+			Description: "URL for the user associated with the token.",
+			Computed:    true,
+		},
+	}
+}
@@ -0,0 +1,179 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// distro is a distribution supported by Cloudsmith.
+type distro struct {
+	slug     string
+	name     string
+	format   string
+	versions []distroVersion
+}
+
+// distroVersion is a version of a distro. Upstreams refer to it as
+// <distro slug>/<version slug>.
+type distroVersion struct {
+	slug string
+	name string
+}
+
+// listDistros returns the distributions supported by Cloudsmith, sorted by
+// slug. They are the same for every organization and rarely change, so they
+// are fetched once per provider instance and cached.
+func listDistros(pc *providerConfig) ([]distro, error) {
+	pc.distrosMu.Lock()
+	defer pc.distrosMu.Unlock()
+
+	if pc.distros != nil {
+		return pc.distros, nil
+	}
+
+	results, _, err := pc.APIClient.DistrosApi.DistrosList(pc.Auth).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("error listing distributions: %w", formatAPIError(err))
+	}
+
+	distros := make([]distro, 0, len(results))
+	for _, result := range results {
+		dist := distro{
+			slug:   result.GetSlug(),
+			name:   result.GetName(),
+			format: result.GetFormat(),
+		}
+		for _, version := range result.GetVersions() {
+			dist.versions = append(dist.versions, distroVersion{slug: version.GetSlug(), name: version.GetName()})
+		}
+		distros = append(distros, dist)
+	}
+	sort.Slice(distros, func(i, j int) bool { return distros[i].slug < distros[j].slug })
+
+	pc.distros = distros
+	return distros, nil
+}
+
+func dataSourceDistrosRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	distros, err := listDistros(pc)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	format := d.Get("format").(string)
+	id := "distros"
+	if format != "" {
+		id = fmt.Sprintf("%s/%s", id, format)
+	}
+
+	results := make([]interface{}, 0, len(distros))
+	distroVersions := []string{}
+	for _, dist := range distros {
+		if format != "" && dist.format != format {
+			continue
+		}
+
+		versions := make([]interface{}, len(dist.versions))
+		for i, version := range dist.versions {
+			distroVersion := fmt.Sprintf("%s/%s", dist.slug, version.slug)
+			versions[i] = map[string]interface{}{
+				"slug":           version.slug,
+				"name":           version.name,
+				"distro_version": distroVersion,
+			}
+			distroVersions = append(distroVersions, distroVersion)
+		}
+		results = append(results, map[string]interface{}{
+			"slug":     dist.slug,
+			"name":     dist.name,
+			"format":   dist.format,
+			"versions": versions,
+		})
+	}
+
+	if err := d.Set("distros", results); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting distros: %w", err))
+	}
+	if err := d.Set("distro_versions", distroVersions); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting distro_versions: %w", err))
+	}
+
+	d.SetId(id)
+	return nil
+}
+
+func dataSourceDistros() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDistrosRead,
+
+		Schema: map[string]*schema.Schema{
+			"format": {
+				Type:         schema.TypeString,
+				Description:  "If set, only distributions for this package format (for example `deb`, `rpm` or `alpine`) are listed.",
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"distros": {
+				Type:        schema.TypeList,
+				Description: "The supported distributions, ordered by slug.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"slug": {
+							Type:        schema.TypeString,
+							Description: "The slug of the distribution, for example `ubuntu`.",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the distribution.",
+							Computed:    true,
+						},
+						"format": {
+							Type:        schema.TypeString,
+							Description: "The package format of the distribution.",
+							Computed:    true,
+						},
+						"versions": {
+							Type:        schema.TypeList,
+							Description: "The supported versions of the distribution.",
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"slug": {
+										Type:        schema.TypeString,
+										Description: "The slug of the version, for example `jammy`.",
+										Computed:    true,
+									},
+									"name": {
+										Type:        schema.TypeString,
+										Description: "The name of the version.",
+										Computed:    true,
+									},
+									"distro_version": {
+										Type:        schema.TypeString,
+										Description: "The distribution version as used by `distro_version` and `distro_versions` of `cloudsmith_repository_upstream`, for example `ubuntu/jammy`.",
+										Computed:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"distro_versions": {
+				Type:        schema.TypeList,
+				Description: "Every `distro_version` of the listed distributions.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const testDistrosBody = `[
+	{"slug":"ubuntu","name":"Ubuntu","format":"deb","self_url":"https://api.cloudsmith.io/distros/ubuntu/","versions":[{"slug":"focal","name":"20.04 Focal Fossa"},{"slug":"jammy","name":"22.04 Jammy Jellyfish"}]},
+	{"slug":"el","name":"Enterprise Linux","format":"rpm","self_url":"https://api.cloudsmith.io/distros/el/","versions":[{"slug":"8","name":"8"},{"slug":"9","name":"9"}]},
+	{"slug":"debian","name":"Debian","format":"deb","self_url":"https://api.cloudsmith.io/distros/debian/","versions":[{"slug":"bookworm","name":"12 Bookworm"}]}
+]`
+
+// distrosTestServer serves testDistrosBody and counts the requests for it.
+func distrosTestServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if strings.TrimSuffix(r.URL.Path, "/") != "/distros" {
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+			return
+		}
+		*requests++
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testDistrosBody)
+	}))
+}
+
+func TestDataSourceDistrosRead(t *testing.T) {
+	t.Parallel()
+
+	var requests int
+	server := distrosTestServer(t, &requests)
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	d := schema.TestResourceDataRaw(t, dataSourceDistros().Schema, map[string]interface{}{
+		"format": "deb",
+	})
+	if diagnostics := dataSourceDistrosRead(context.Background(), d, pc); diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	if d.Id() != "distros/deb" {
+		t.Errorf("unexpected ID %q", d.Id())
+	}
+	if got := d.Get("distros.#").(int); got != 2 {
+		t.Fatalf("expected 2 deb distros, got %d", got)
+	}
+	if got := d.Get("distros.0.slug").(string); got != "debian" {
+		t.Errorf("expected distros to be sorted by slug, got %q first", got)
+	}
+	if got := d.Get("distros.1.versions.1.distro_version").(string); got != "ubuntu/jammy" {
+		t.Errorf("unexpected distro_version %q", got)
+	}
+	expected := []interface{}{"debian/bookworm", "ubuntu/focal", "ubuntu/jammy"}
+	if got := d.Get("distro_versions").([]interface{}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected distro_versions %v, want %v", got, expected)
+	}
+
+	// A second read is served from the provider's cache.
+	d = schema.TestResourceDataRaw(t, dataSourceDistros().Schema, map[string]interface{}{})
+	if diagnostics := dataSourceDistrosRead(context.Background(), d, pc); diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+	if got := d.Get("distros.#").(int); got != 3 {
+		t.Errorf("expected 3 distros, got %d", got)
+	}
+	if requests != 1 {
+		t.Errorf("expected the distros to be listed once, got %d requests", requests)
+	}
+}
@@ -0,0 +1,133 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// findEntitlementByName returns the entitlement of a repository named name.
+// Names aren't unique, so more than one match is an error.
+func findEntitlementByName(pc *providerConfig, namespace, repository, name string) (cloudsmith.RepositoryToken, error) {
+	entitlements, err := listEntitlementTokens(pc, namespace, repository)
+	if err != nil {
+		return cloudsmith.RepositoryToken{}, err
+	}
+
+	var matches []cloudsmith.RepositoryToken
+	for _, e := range entitlements {
+		if e.GetName() == name {
+			matches = append(matches, e)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return cloudsmith.RepositoryToken{}, fmt.Errorf("no entitlement named %q found in repository %s/%s", name, namespace, repository)
+	case 1:
+		return matches[0], nil
+	default:
+		slugPerms := make([]string, len(matches))
+		for i, e := range matches {
+			slugPerms[i] = e.GetSlugPerm()
+		}
+		return cloudsmith.RepositoryToken{}, fmt.Errorf(
+			"%d entitlements named %q found in repository %s/%s, use slug_perm to select one of: %s",
+			len(matches), name, namespace, repository, strings.Join(slugPerms, ", "),
+		)
+	}
+}
+
+func dataSourceEntitlementTokenRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+	repository := requiredString(d, "repository")
+
+	var entitlement cloudsmith.RepositoryToken
+	if slugPerm := d.Get("slug_perm").(string); slugPerm != "" {
+		req := pc.APIClient.EntitlementsApi.EntitlementsRead(pc.Auth, namespace, repository, slugPerm)
+		req = req.ShowTokens(requiredBool(d, "show_token"))
+		read, resp, err := pc.APIClient.EntitlementsApi.EntitlementsReadExecute(req)
+		if err != nil {
+			if is404(resp) {
+				return diag.Errorf("no entitlement with slug_perm %q found in repository %s/%s", slugPerm, namespace, repository)
+			}
+			return diag.FromErr(fmt.Errorf("error reading entitlement (%s): %w", slugPerm, formatAPIError(err)))
+		}
+		entitlement = *read
+	} else {
+		found, err := findEntitlementByName(pc, namespace, repository, d.Get("name").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		entitlement = found
+	}
+
+	fields := flattenEntitlement(entitlement)
+	if !requiredBool(d, "show_token") {
+		// Lookups by name list entitlements with their tokens.
+		fields["token"] = ""
+	}
+	for key, value := range fields {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting entitlement field %q: %w", key, err))
+		}
+	}
+
+	d.SetId(entitlement.GetSlugPerm())
+	return nil
+}
+
+func dataSourceEntitlementToken() *schema.Resource {
+	s := entitlementTokenSchema()
+
+	// Bandwidth is only read by the list data source.
+	delete(s, "bandwidth_used")
+	delete(s, "bandwidth_used_unit")
+
+	s["namespace"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Description:  "The namespace slug.",
+		Required:     true,
+		ValidateFunc: validation.StringIsNotEmpty,
+	}
+	s["repository"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Description:  "The repository slug.",
+		Required:     true,
+		ValidateFunc: validation.StringIsNotEmpty,
+	}
+	s["name"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Description:  "The name of the entitlement token to find. It must match exactly one token.",
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"name", "slug_perm"},
+		ValidateFunc: validation.StringIsNotEmpty,
+	}
+	s["slug_perm"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Description:  "The permanent slug identifier of the entitlement token to find.",
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"name", "slug_perm"},
+		ValidateFunc: validation.StringIsNotEmpty,
+	}
+	s["show_token"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Show the entitlement token string in `token`.",
+		Optional:    true,
+		Default:     false,
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceEntitlementTokenRead,
+		Schema:      s,
+	}
+}
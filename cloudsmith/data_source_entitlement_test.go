@@ -0,0 +1,150 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func entitlementListTestServer(t *testing.T, tokens []map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/entitlements/org/repo/":
+			w.Header().Set("X-Pagination-Count", fmt.Sprint(len(tokens)))
+			w.Header().Set("X-Pagination-Page", "1")
+			w.Header().Set("X-Pagination-PageTotal", "1")
+			w.Header().Set("X-Pagination-PageSize", "100")
+			_ = json.NewEncoder(w).Encode(tokens)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/entitlements/org/repo/"):
+			slugPerm := strings.Trim(strings.TrimPrefix(r.URL.Path, "/entitlements/org/repo/"), "/")
+			for _, token := range tokens {
+				if token["slug_perm"] == slugPerm {
+					_ = json.NewEncoder(w).Encode(token)
+					return
+				}
+			}
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+}
+
+var entitlementListTestTokens = []map[string]interface{}{
+	{"name": "Customer A", "slug_perm": "a", "token": "aaa", "limit_package_query": "name:app"},
+	{"name": "Customer B", "slug_perm": "b", "token": "bbb", "limit_package_query": "name:lib"},
+	{"name": "Shared", "slug_perm": "s1", "token": "sss1"},
+	{"name": "Shared", "slug_perm": "s2", "token": "sss2"},
+}
+
+func TestDataSourceEntitlementTokenRead(t *testing.T) {
+	t.Parallel()
+
+	server := entitlementListTestServer(t, entitlementListTestTokens)
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		wantSlug  string
+		wantToken string
+		wantErr   string
+	}{
+		{
+			name:     "by name",
+			config:   map[string]interface{}{"name": "Customer A"},
+			wantSlug: "a",
+		},
+		{
+			name:      "by name with token",
+			config:    map[string]interface{}{"name": "Customer A", "show_token": true},
+			wantSlug:  "a",
+			wantToken: "aaa",
+		},
+		{
+			name:     "by slug_perm",
+			config:   map[string]interface{}{"slug_perm": "b"},
+			wantSlug: "b",
+		},
+		{
+			name:    "duplicate name",
+			config:  map[string]interface{}{"name": "Shared"},
+			wantErr: "use slug_perm to select one of: s1, s2",
+		},
+		{
+			name:    "unknown name",
+			config:  map[string]interface{}{"name": "Nobody"},
+			wantErr: `no entitlement named "Nobody"`,
+		},
+		{
+			name:    "unknown slug_perm",
+			config:  map[string]interface{}{"slug_perm": "zzz"},
+			wantErr: `no entitlement with slug_perm "zzz"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{"namespace": "org", "repository": "repo"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			d := schema.TestResourceDataRaw(t, dataSourceEntitlementToken().Schema, config)
+
+			diags := dataSourceEntitlementTokenRead(context.Background(), d, pc)
+			if tt.wantErr != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantErr) {
+					t.Fatalf("dataSourceEntitlementTokenRead() = %v, want error containing %q", diags, tt.wantErr)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("dataSourceEntitlementTokenRead() error = %v", diags)
+			}
+
+			if d.Id() != tt.wantSlug || d.Get("slug_perm").(string) != tt.wantSlug {
+				t.Fatalf("id = %q, slug_perm = %q, want %q", d.Id(), d.Get("slug_perm"), tt.wantSlug)
+			}
+			if got := d.Get("token").(string); got != tt.wantToken {
+				t.Fatalf("token = %q, want %q", got, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestDataSourceEntitlementListRead(t *testing.T) {
+	t.Parallel()
+
+	server := entitlementListTestServer(t, entitlementListTestTokens)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceEntitlementList().Schema, map[string]interface{}{
+		"namespace":           "org",
+		"repository":          "repo",
+		"limit_package_query": " name:app ",
+	})
+
+	if err := dataSourceEntitlementRead(d, testPrivilegesProviderConfig(server)); err != nil {
+		t.Fatalf("dataSourceEntitlementRead() error = %v", err)
+	}
+
+	if d.Id() != "org/repo/entitlements" {
+		t.Fatalf("id = %q, want org/repo/entitlements", d.Id())
+	}
+	tokens := d.Get("entitlement_tokens").([]interface{})
+	if len(tokens) != 1 || tokens[0].(map[string]interface{})["slug_perm"] != "a" {
+		t.Fatalf("entitlement_tokens = %v, want only a", tokens)
+	}
+}
@@ -0,0 +1,217 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	usageStart                   = "start"
+	usageFinish                  = "finish"
+	usageHistory                 = "history"
+	usageStorageUsed             = "storage_used"
+	usageStoragePlanLimit        = "storage_plan_limit"
+	usageStorageConfigured       = "storage_configured"
+	usageStoragePercentageUsed   = "storage_percentage_used"
+	usageBandwidthUsed           = "bandwidth_used"
+	usageBandwidthPlanLimit      = "bandwidth_plan_limit"
+	usageBandwidthConfigured     = "bandwidth_configured"
+	usageBandwidthPercentageUsed = "bandwidth_percentage_used"
+)
+
+func dataSourceOrganizationUsage() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOrganizationUsageRead,
+
+		Schema: map[string]*schema.Schema{
+			usageLimitsOrganization: {
+				Type:         schema.TypeString,
+				Description:  "The slug of the Cloudsmith organization whose usage is read.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			usageStart: {
+				Type:         schema.TypeString,
+				Description:  "Only include history buckets that end at or after this RFC 3339 timestamp.",
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			usageFinish: {
+				Type:         schema.TypeString,
+				Description:  "Only include history buckets that start at or before this RFC 3339 timestamp.",
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			usageStorageUsed: {
+				Type:        schema.TypeInt,
+				Description: "The artifact storage currently used by the organization, in bytes.",
+				Computed:    true,
+			},
+			usageStoragePlanLimit: {
+				Type:        schema.TypeInt,
+				Description: "The artifact storage included in the organization's plan, in bytes.",
+				Computed:    true,
+			},
+			usageStorageConfigured: {
+				Type:        schema.TypeInt,
+				Description: "The artifact storage limit configured for the organization, including on-demand usage, in bytes.",
+				Computed:    true,
+			},
+			usageStoragePercentageUsed: {
+				Type:        schema.TypeFloat,
+				Description: "The percentage of the configured artifact storage limit currently used.",
+				Computed:    true,
+			},
+			usageBandwidthUsed: {
+				Type:        schema.TypeInt,
+				Description: "The package delivery bandwidth used by the organization in the current billing period, in bytes.",
+				Computed:    true,
+			},
+			usageBandwidthPlanLimit: {
+				Type:        schema.TypeInt,
+				Description: "The package delivery bandwidth included in the organization's plan, in bytes.",
+				Computed:    true,
+			},
+			usageBandwidthConfigured: {
+				Type:        schema.TypeInt,
+				Description: "The package delivery bandwidth limit configured for the organization, including on-demand usage, in bytes.",
+				Computed:    true,
+			},
+			usageBandwidthPercentageUsed: {
+				Type:        schema.TypeFloat,
+				Description: "The percentage of the configured package delivery bandwidth limit used in the current billing period.",
+				Computed:    true,
+			},
+			usageHistory: {
+				Type:        schema.TypeList,
+				Description: "Historical usage buckets reported for the organization, oldest first.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						usageStart: {
+							Type:        schema.TypeString,
+							Description: "The start of the bucket (RFC 3339).",
+							Computed:    true,
+						},
+						usageFinish: {
+							Type:        schema.TypeString,
+							Description: "The end of the bucket (RFC 3339).",
+							Computed:    true,
+						},
+						"days": {
+							Type:        schema.TypeInt,
+							Description: "The number of days covered by the bucket.",
+							Computed:    true,
+						},
+						"plan": {
+							Type:        schema.TypeString,
+							Description: "The plan the organization was on during the bucket.",
+							Computed:    true,
+						},
+						usageStorageUsed: {
+							Type:        schema.TypeInt,
+							Description: "The artifact storage used at the end of the bucket, in bytes.",
+							Computed:    true,
+						},
+						"downloaded": {
+							Type:        schema.TypeInt,
+							Description: "The package delivery bandwidth used during the bucket, in bytes.",
+							Computed:    true,
+						},
+						"uploaded": {
+							Type:        schema.TypeInt,
+							Description: "The volume of artifacts uploaded during the bucket, in bytes.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceOrganizationUsageRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+	organization := requiredString(d, usageLimitsOrganization)
+
+	quota, _, err := pc.APIClient.QuotaApi.QuotaRead(pc.Auth, organization).Execute()
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error reading usage for organization %q: %w", organization, formatAPIError(err)))
+	}
+
+	usage := quota.GetUsage()
+	raw := usage.GetRaw()
+	storage := raw.GetStorage()
+	bandwidth := raw.GetBandwidth()
+
+	fields := map[string]interface{}{
+		usageStorageUsed:             int64(storage.GetUsed()),
+		usageStoragePlanLimit:        int64(storage.GetPlanLimit()),
+		usageStorageConfigured:       int64(storage.GetConfigured()),
+		usageStoragePercentageUsed:   float64(storage.GetPercentageUsed()),
+		usageBandwidthUsed:           int64(bandwidth.GetUsed()),
+		usageBandwidthPlanLimit:      int64(bandwidth.GetPlanLimit()),
+		usageBandwidthConfigured:     int64(bandwidth.GetConfigured()),
+		usageBandwidthPercentageUsed: float64(bandwidth.GetPercentageUsed()),
+	}
+	for name, value := range fields {
+		if err := d.Set(name, value); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting organization usage field %q: %w", name, err))
+		}
+	}
+
+	history, _, err := pc.APIClient.QuotaApi.QuotaHistoryRead(pc.Auth, organization).Execute()
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error reading usage history for organization %q: %w", organization, formatAPIError(err)))
+	}
+
+	start := stringToTime(d.Get(usageStart).(string))
+	finish := stringToTime(d.Get(usageFinish).(string))
+	if !start.IsZero() && !finish.IsZero() && finish.Before(start) {
+		return diag.Errorf("%q must not be before %q", usageFinish, usageStart)
+	}
+
+	if err := d.Set(usageHistory, flattenQuotaHistory(history.GetHistory(), start, finish)); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting organization usage history: %w", err))
+	}
+
+	d.SetId(organization)
+	return nil
+}
+
+// flattenQuotaHistory converts the quota history buckets returned by the API
+// into state, dropping any bucket that lies entirely outside [start, finish].
+// A zero start or finish leaves that side of the range open.
+func flattenQuotaHistory(history []cloudsmith.History, start, finish time.Time) []interface{} {
+	out := make([]interface{}, 0, len(history))
+	for _, bucket := range history {
+		if !start.IsZero() && bucket.GetEnd().Before(start) {
+			continue
+		}
+		if !finish.IsZero() && bucket.GetStart().After(finish) {
+			continue
+		}
+
+		raw := bucket.GetRaw()
+		storage := raw.GetStorageUsed()
+		downloaded := raw.GetDownloaded()
+		uploaded := raw.GetUploaded()
+
+		out = append(out, map[string]interface{}{
+			usageStart:       timeToString(bucket.GetStart()),
+			usageFinish:      timeToString(bucket.GetEnd()),
+			"days":           bucket.GetDays(),
+			"plan":           bucket.GetPlan(),
+			usageStorageUsed: int64(storage.GetUsed()),
+			"downloaded":     int64(downloaded.GetUsed()),
+			"uploaded":       int64(uploaded.GetUsed()),
+		})
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceOrganizationUsageRead(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/quota/example-org/":
+			fmt.Fprint(w, `{"usage":{"raw":{
+				"storage":{"used":1000,"plan_limit":4000,"configured":5000,"percentage_used":20.0},
+				"bandwidth":{"used":300,"plan_limit":1000,"configured":1200,"percentage_used":25.0}
+			}}}`)
+		case "/quota/history/example-org/":
+			fmt.Fprint(w, `{"history":[
+				{"start":"2026-01-01T00:00:00Z","end":"2026-01-31T23:59:59Z","days":31,"plan":"Velocity",
+				 "raw":{"storage_used":{"used":800},"downloaded":{"used":100},"uploaded":{"used":50}}},
+				{"start":"2026-02-01T00:00:00Z","end":"2026-02-28T23:59:59Z","days":28,"plan":"Velocity",
+				 "raw":{"storage_used":{"used":1000},"downloaded":{"used":300},"uploaded":{"used":200}}}
+			]}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceOrganizationUsage().Schema, map[string]interface{}{
+		usageLimitsOrganization: "example-org",
+		usageStart:              "2026-02-01T00:00:00Z",
+	})
+	diagnostics := dataSourceOrganizationUsageRead(context.Background(), d, usageLimitsTestProviderConfig(server.URL))
+	if diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	expected := map[string]interface{}{
+		usageStorageUsed:             1000,
+		usageStoragePlanLimit:        4000,
+		usageStorageConfigured:       5000,
+		usageStoragePercentageUsed:   20.0,
+		usageBandwidthUsed:           300,
+		usageBandwidthPlanLimit:      1000,
+		usageBandwidthConfigured:     1200,
+		usageBandwidthPercentageUsed: 25.0,
+		"history.#":                  1,
+		"history.0.start":            "2026-02-01T00:00:00Z",
+		"history.0.days":             28,
+		"history.0.storage_used":     1000,
+		"history.0.downloaded":       300,
+		"history.0.uploaded":         200,
+	}
+	for name, want := range expected {
+		if got := d.Get(name); got != want {
+			t.Errorf("unexpected %s: got %v, want %v", name, got, want)
+		}
+	}
+	if d.Id() != "example-org" {
+		t.Errorf("unexpected ID: got %q, want %q", d.Id(), "example-org")
+	}
+}
@@ -0,0 +1,369 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	geoIpEvaluationModeAuto  = "auto"
+	geoIpEvaluationModeAPI   = "api"
+	geoIpEvaluationModeLocal = "local"
+
+	// geoIpRuleCountryUnknown is reported when the decision depends on the
+	// country of an address whose country code isn't known.
+	geoIpRuleCountryUnknown = "country_unknown"
+)
+
+var geoIpEvaluationModes = []string{
+	geoIpEvaluationModeAuto,
+	geoIpEvaluationModeAPI,
+	geoIpEvaluationModeLocal,
+}
+
+// geoIpRules is the subset of a repository's Geo/IP configuration needed to
+// evaluate an address locally.
+type geoIpRules struct {
+	cidrAllow        []string
+	cidrDeny         []string
+	countryCodeAllow []string
+	countryCodeDeny  []string
+}
+
+// geoIpDecision is the outcome of evaluating one address.
+type geoIpDecision struct {
+	address     string
+	allowed     bool
+	determined  bool
+	rule        string
+	countryCode string
+	source      string
+}
+
+// evaluateGeoIp applies the Geo/IP precedence rules to a single address:
+//
+//  1. a matching cidr_deny entry denies,
+//  2. a matching cidr_allow entry allows,
+//  3. a matching country_code_deny entry denies,
+//  4. a matching country_code_allow entry allows,
+//  5. if any allow list is configured, anything else is denied,
+//  6. otherwise the address is allowed.
+//
+// countryCode may be empty when the address' country is unknown. If no CIDR
+// rule matches and there are country rules, the decision then depends on the
+// country, so it is reported as undetermined under the country_unknown rule,
+// and not allowed.
+func evaluateGeoIp(rules geoIpRules, address, countryCode string) (geoIpDecision, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return geoIpDecision{}, fmt.Errorf("invalid IP address %q: %w", address, err)
+	}
+	addr = addr.Unmap()
+	countryCode = strings.ToUpper(countryCode)
+
+	decision := geoIpDecision{
+		address:     address,
+		determined:  true,
+		countryCode: countryCode,
+		source:      geoIpEvaluationModeLocal,
+	}
+
+	if cidr, ok := matchingCidr(rules.cidrDeny, addr); ok {
+		decision.rule = fmt.Sprintf("%s:%s", CidrDeny, cidr)
+		return decision, nil
+	}
+	if cidr, ok := matchingCidr(rules.cidrAllow, addr); ok {
+		decision.allowed = true
+		decision.rule = fmt.Sprintf("%s:%s", CidrAllow, cidr)
+		return decision, nil
+	}
+	if countryCode == "" && (len(rules.countryCodeDeny) > 0 || len(rules.countryCodeAllow) > 0) {
+		decision.determined = false
+		decision.rule = geoIpRuleCountryUnknown
+		return decision, nil
+	}
+	if contains(upperStrings(rules.countryCodeDeny), countryCode) {
+		decision.rule = fmt.Sprintf("%s:%s", CountryCodeDeny, countryCode)
+		return decision, nil
+	}
+	if contains(upperStrings(rules.countryCodeAllow), countryCode) {
+		decision.allowed = true
+		decision.rule = fmt.Sprintf("%s:%s", CountryCodeAllow, countryCode)
+		return decision, nil
+	}
+	if len(rules.cidrAllow) > 0 || len(rules.countryCodeAllow) > 0 {
+		decision.rule = "default_deny"
+		return decision, nil
+	}
+
+	decision.allowed = true
+	decision.rule = "default_allow"
+	return decision, nil
+}
+
+// matchingCidr returns the first CIDR in cidrs containing addr. Entries that
+// fail to parse are ignored, as the API would have rejected them.
+func matchingCidr(cidrs []string, addr netip.Addr) (string, bool) {
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr) {
+			return cidr, true
+		}
+	}
+	return "", false
+}
+
+func upperStrings(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToUpper(v)
+	}
+	return out
+}
+
+func dataSourceRepositoryGeoIpEvaluation() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRepositoryGeoIpEvaluationRead,
+
+		Schema: map[string]*schema.Schema{
+			Namespace: {
+				Type:         schema.TypeString,
+				Description:  "Organization to which the Repository belongs.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			Repository: {
+				Type:         schema.TypeString,
+				Description:  "Repository whose Geo/IP rules are evaluated.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"addresses": {
+				Type:        schema.TypeList,
+				Description: "The IP addresses to evaluate.",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsIPAddress,
+				},
+			},
+			"address_country_codes": {
+				Type:        schema.TypeMap,
+				Description: "ISO 3166-1 country codes for addresses, used by the local evaluator, which has no Geo/IP database of its own.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"mode": {
+				Type:         schema.TypeString,
+				Description:  "How addresses are evaluated: `api` uses the repository's Geo/IP test endpoint, `local` uses the provider's evaluator, and `auto` uses the endpoint and falls back to the local evaluator if it is unavailable.",
+				Optional:     true,
+				Default:      geoIpEvaluationModeAuto,
+				ValidateFunc: validation.StringInSlice(geoIpEvaluationModes, false),
+			},
+			"all_allowed": {
+				Type:        schema.TypeBool,
+				Description: "True if every evaluated address is allowed. False if any result is undetermined.",
+				Computed:    true,
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Description: "The evaluation result for each address, in the order given.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Type:        schema.TypeString,
+							Description: "The evaluated IP address.",
+							Computed:    true,
+						},
+						"allowed": {
+							Type:        schema.TypeBool,
+							Description: "Whether the address would be allowed to access the repository. Always false if the result is undetermined.",
+							Computed:    true,
+						},
+						"determined": {
+							Type:        schema.TypeBool,
+							Description: "False if the result depends on the address' country, which the local evaluator doesn't know. Set `address_country_codes` to evaluate it.",
+							Computed:    true,
+						},
+						"rule": {
+							Type:        schema.TypeString,
+							Description: "The rule that decided the result, e.g. `cidr_deny:10.0.0.0/8`, `country_code_allow:GB`, `default_allow`, `default_deny`, or `country_unknown` if the result is undetermined.",
+							Computed:    true,
+						},
+						"country_code": {
+							Type:        schema.TypeString,
+							Description: "The country code of the address, if known.",
+							Computed:    true,
+						},
+						"source": {
+							Type:        schema.TypeString,
+							Description: "Whether the result came from the `api` or the `local` evaluator.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRepositoryGeoIpEvaluationRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, Namespace)
+	repository := requiredString(d, Repository)
+	mode := requiredString(d, "mode")
+
+	addresses := make([]string, 0)
+	for _, a := range d.Get("addresses").([]interface{}) {
+		addresses = append(addresses, a.(string))
+	}
+	countryCodes := make(map[string]string)
+	for k, v := range d.Get("address_country_codes").(map[string]interface{}) {
+		countryCodes[k] = v.(string)
+	}
+
+	var decisions []geoIpDecision
+	var err error
+	if mode != geoIpEvaluationModeLocal {
+		var resp *http.Response
+		decisions, resp, err = testGeoIpAddresses(pc, namespace, repository, addresses)
+		if err != nil {
+			if mode == geoIpEvaluationModeAPI || !geoIpTestUnavailable(resp) {
+				return diag.FromErr(fmt.Errorf("error testing Geo/IP rules for repository %s/%s: %w", namespace, repository, formatAPIError(err)))
+			}
+			log.Printf("[WARN] Geo/IP test endpoint unavailable for repository %s/%s, falling back to local evaluation: %s", namespace, repository, err)
+			decisions = nil
+		}
+	}
+
+	if decisions == nil {
+		decisions, err = evaluateGeoIpLocally(pc, namespace, repository, addresses, countryCodes)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	allAllowed := true
+	results := make([]interface{}, len(decisions))
+	for i, decision := range decisions {
+		allAllowed = allAllowed && decision.allowed
+		results[i] = map[string]interface{}{
+			"address":      decision.address,
+			"allowed":      decision.allowed,
+			"determined":   decision.determined,
+			"rule":         decision.rule,
+			"country_code": decision.countryCode,
+			"source":       decision.source,
+		}
+	}
+
+	if err := d.Set("results", results); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting Geo/IP evaluation results: %w", err))
+	}
+	_ = d.Set("all_allowed", allAllowed)
+	d.SetId(fmt.Sprintf("%s/%s/geoip/%s", namespace, repository, strings.Join(addresses, ",")))
+
+	return nil
+}
+
+// testGeoIpAddresses evaluates addresses with the repository's Geo/IP test
+// endpoint.
+func testGeoIpAddresses(pc *providerConfig, namespace, repository string, addresses []string) ([]geoIpDecision, *http.Response, error) {
+	req := pc.APIClient.ReposApi.ReposGeoipTest(pc.Auth, namespace, repository)
+	req = req.Data(cloudsmith.RepositoryGeoIpTestAddress{
+		Addresses: addresses,
+	})
+	result, resp, err := pc.APIClient.ReposApi.ReposGeoipTestExecute(req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	// The API may format an address differently from the request, for example
+	// a compressed or upper-case IPv6 address, so match on the parsed address.
+	byAddress := make(map[netip.Addr]geoIpDecision)
+	for _, r := range result.GetAddresses() {
+		ip, err := netip.ParseAddr(r.GetIp())
+		if err != nil {
+			return nil, resp, fmt.Errorf("test response included invalid address %q: %w", r.GetIp(), err)
+		}
+		byAddress[ip.Unmap()] = geoIpDecision{
+			allowed:     r.GetAllowed(),
+			determined:  true,
+			rule:        r.GetReason(),
+			countryCode: r.GetCountryCode(),
+			source:      geoIpEvaluationModeAPI,
+		}
+	}
+
+	decisions := make([]geoIpDecision, len(addresses))
+	for i, address := range addresses {
+		ip, err := netip.ParseAddr(address)
+		if err != nil {
+			return nil, resp, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		decision, ok := byAddress[ip.Unmap()]
+		if !ok {
+			return nil, resp, fmt.Errorf("test response did not include address %q", address)
+		}
+		decision.address = address
+		decisions[i] = decision
+	}
+	return decisions, resp, nil
+}
+
+// geoIpTestUnavailable reports whether a failed test request means the endpoint
+// isn't available (as opposed to the request itself being wrong).
+func geoIpTestUnavailable(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// evaluateGeoIpLocally reads the repository's rules and evaluates addresses
+// with evaluateGeoIp.
+func evaluateGeoIpLocally(pc *providerConfig, namespace, repository string, addresses []string, countryCodes map[string]string) ([]geoIpDecision, error) {
+	req := pc.APIClient.ReposApi.ReposGeoipRead(pc.Auth, namespace, repository)
+	geoIpRulesResp, _, err := pc.APIClient.ReposApi.ReposGeoipReadExecute(req)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Geo/IP rules for repository %s/%s: %w", namespace, repository, formatAPIError(err))
+	}
+
+	cidr := geoIpRulesResp.GetCidr()
+	countryCode := geoIpRulesResp.GetCountryCode()
+	rules := geoIpRules{
+		cidrAllow:        cidr.GetAllow(),
+		cidrDeny:         cidr.GetDeny(),
+		countryCodeAllow: countryCode.GetAllow(),
+		countryCodeDeny:  countryCode.GetDeny(),
+	}
+
+	decisions := make([]geoIpDecision, len(addresses))
+	for i, address := range addresses {
+		decision, err := evaluateGeoIp(rules, address, countryCodes[address])
+		if err != nil {
+			return nil, err
+		}
+		decisions[i] = decision
+	}
+	return decisions, nil
+}
@@ -0,0 +1,167 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEvaluateGeoIp(t *testing.T) {
+	t.Parallel()
+
+	rules := geoIpRules{
+		cidrAllow:        []string{"203.0.113.0/24", "2001:db8::/32"},
+		cidrDeny:         []string{"203.0.113.128/25"},
+		countryCodeAllow: []string{"gb"},
+		countryCodeDeny:  []string{"FR"},
+	}
+
+	tests := []struct {
+		name        string
+		rules       geoIpRules
+		address     string
+		countryCode string
+		allowed     bool
+		rule        string
+	}{
+		{"cidr deny wins over cidr allow", rules, "203.0.113.200", "", false, "cidr_deny:203.0.113.128/25"},
+		{"cidr allow", rules, "203.0.113.10", "", true, "cidr_allow:203.0.113.0/24"},
+		{"cidr allow wins over country deny", rules, "203.0.113.10", "FR", true, "cidr_allow:203.0.113.0/24"},
+		{"ipv6 cidr allow", rules, "2001:db8::1", "", true, "cidr_allow:2001:db8::/32"},
+		{"ipv4-mapped ipv6 address", rules, "::ffff:203.0.113.10", "", true, "cidr_allow:203.0.113.0/24"},
+		{"country deny", rules, "198.51.100.1", "fr", false, "country_code_deny:FR"},
+		{"country allow is case insensitive", rules, "198.51.100.1", "GB", true, "country_code_allow:GB"},
+		{"allow lists deny everything else", rules, "198.51.100.1", "DE", false, "default_deny"},
+		{"cidr allow list denies everything else", geoIpRules{cidrAllow: []string{"203.0.113.0/24"}}, "198.51.100.1", "", false, "default_deny"},
+		{"unknown country with country rules", rules, "198.51.100.1", "", false, "country_unknown"},
+		{"unknown country with country deny rules", geoIpRules{countryCodeDeny: []string{"FR"}}, "198.51.100.1", "", false, "country_unknown"},
+		{"no rules allow everything", geoIpRules{}, "198.51.100.1", "", true, "default_allow"},
+		{"deny-only rules allow everything else", geoIpRules{cidrDeny: []string{"10.0.0.0/8"}}, "198.51.100.1", "", true, "default_allow"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			decision, err := evaluateGeoIp(tc.rules, tc.address, tc.countryCode)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decision.allowed != tc.allowed || decision.rule != tc.rule {
+				t.Fatalf("got allowed=%v rule=%q, want allowed=%v rule=%q", decision.allowed, decision.rule, tc.allowed, tc.rule)
+			}
+			if want := tc.rule != geoIpRuleCountryUnknown; decision.determined != want {
+				t.Fatalf("got determined=%v, want %v", decision.determined, want)
+			}
+		})
+	}
+
+	if _, err := evaluateGeoIp(rules, "not-an-ip", ""); err == nil {
+		t.Fatal("expected an error for an invalid address")
+	}
+}
+
+func TestDataSourceRepositoryGeoIpEvaluationRead(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(testEndpointAvailable bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch strings.TrimSuffix(r.URL.Path, "/") {
+			case "/repos/example-org/example-repo/geoip/test":
+				if !testEndpointAvailable {
+					http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+					return
+				}
+				fmt.Fprint(w, `{"addresses":[
+					{"ip":"203.0.113.10","allowed":true,"country_code":"GB","reason":"cidr_allow:203.0.113.0/24"},
+					{"ip":"198.51.100.1","allowed":false,"country_code":"US","reason":"default_deny"}
+				]}`)
+			case "/repos/example-org/example-repo/geoip":
+				fmt.Fprint(w, `{"cidr":{"allow":["203.0.113.0/24"],"deny":[]},"country_code":{"allow":[],"deny":[]}}`)
+			default:
+				http.Error(w, "unexpected request", http.StatusNotFound)
+			}
+		}))
+	}
+
+	for _, tc := range []struct {
+		name                  string
+		testEndpointAvailable bool
+		wantSource            string
+	}{
+		{"api", true, geoIpEvaluationModeAPI},
+		{"local fallback", false, geoIpEvaluationModeLocal},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newServer(tc.testEndpointAvailable)
+			defer server.Close()
+
+			d := schema.TestResourceDataRaw(t, dataSourceRepositoryGeoIpEvaluation().Schema, map[string]interface{}{
+				Namespace:   "example-org",
+				Repository:  "example-repo",
+				"addresses": []interface{}{"203.0.113.10", "198.51.100.1"},
+			})
+			diagnostics := dataSourceRepositoryGeoIpEvaluationRead(context.Background(), d, testPrivilegesProviderConfig(server))
+			if diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diagnostics)
+			}
+
+			expected := map[string]interface{}{
+				"all_allowed":          false,
+				"results.#":            2,
+				"results.0.address":    "203.0.113.10",
+				"results.0.allowed":    true,
+				"results.0.determined": true,
+				"results.0.rule":       "cidr_allow:203.0.113.0/24",
+				"results.0.source":     tc.wantSource,
+				"results.1.address":    "198.51.100.1",
+				"results.1.allowed":    false,
+				"results.1.rule":       "default_deny",
+				"results.1.determined": true,
+			}
+			for name, want := range expected {
+				if got := d.Get(name); got != want {
+					t.Errorf("unexpected %s: got %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTestGeoIpAddressesMatchesEquivalentAddresses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"addresses":[
+			{"ip":"2001:db8::1","allowed":true,"country_code":"GB","reason":"cidr_allow:2001:db8::/32"},
+			{"ip":"203.0.113.10","allowed":false,"country_code":"US","reason":"default_deny"}
+		]}`)
+	}))
+	defer server.Close()
+
+	addresses := []string{"2001:DB8:0:0:0:0:0:1", "::ffff:203.0.113.10"}
+	decisions, _, err := testGeoIpAddresses(testPrivilegesProviderConfig(server), "example-org", "example-repo", addresses)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decisions) != 2 || !decisions[0].allowed || decisions[1].allowed {
+		t.Fatalf("unexpected decisions: %+v", decisions)
+	}
+	for i, address := range addresses {
+		if decisions[i].address != address {
+			t.Errorf("decision %d address = %q, want the requested %q", i, decisions[i].address, address)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	retentionReasonCountLimit = "count_limit"
+	retentionReasonDaysLimit  = "days_limit"
+	retentionReasonSizeLimit  = "size_limit"
+)
+
+// retentionSizeLimitMax is the largest retention_size_limit the API accepts,
+// in bytes.
+const retentionSizeLimitMax int64 = 21474836480
+
+// validateRetentionSizeLimit checks retention_size_limit is within the range
+// the API accepts. The bound doesn't fit in an int on 32-bit platforms, so
+// validation.IntBetween can't be used.
+func validateRetentionSizeLimit(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(int)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be integer", k)}
+	}
+	if v < 0 || int64(v) > retentionSizeLimitMax {
+		return nil, []error{fmt.Errorf("expected %s to be in the range (0 - %d), got %d", k, retentionSizeLimitMax, v)}
+	}
+	return nil, nil
+}
+
+// retentionPreviewRules mirrors the settings of cloudsmith_repository_retention_rule.
+type retentionPreviewRules struct {
+	countLimit         int64
+	daysLimit          int64
+	sizeLimit          int64
+	groupByName        bool
+	groupByFormat      bool
+	groupByPackageType bool
+}
+
+// retentionPackage is the subset of a package needed to evaluate retention.
+type retentionPackage struct {
+	slugPerm    string
+	name        string
+	version     string
+	format      string
+	packageType string
+	size        int64
+	uploadedAt  time.Time
+}
+
+// retentionOutcome is the result of evaluating retention for one package.
+// reason is empty for kept packages.
+type retentionOutcome struct {
+	pkg    retentionPackage
+	group  string
+	reason string
+}
+
+func (r retentionPreviewRules) groupKey(pkg retentionPackage) string {
+	var parts []string
+	if r.groupByFormat {
+		parts = append(parts, pkg.format)
+	}
+	if r.groupByPackageType {
+		parts = append(parts, pkg.packageType)
+	}
+	if r.groupByName {
+		parts = append(parts, pkg.name)
+	}
+	return strings.Join(parts, "/")
+}
+
+// previewRetention applies retention rules to packages as of now. Packages are
+// grouped according to the group_by settings, then within each group, newest
+// first, a package is removed if it falls outside the count limit, is older
+// than the days limit, or would take the group over the size limit. A limit of
+// zero is not applied. Once a package would take the group over the size
+// limit, every older package in the group is removed too, as the limit keeps
+// the newest packages that fit rather than filling gaps with older ones.
+func previewRetention(packages []retentionPackage, rules retentionPreviewRules, now time.Time) []retentionOutcome {
+	groups := make(map[string][]retentionPackage)
+	var keys []string
+	for _, pkg := range packages {
+		key := rules.groupKey(pkg)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], pkg)
+	}
+	sort.Strings(keys)
+
+	cutoff := now.AddDate(0, 0, -int(rules.daysLimit))
+
+	outcomes := make([]retentionOutcome, 0, len(packages))
+	for _, key := range keys {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			if !group[i].uploadedAt.Equal(group[j].uploadedAt) {
+				return group[i].uploadedAt.After(group[j].uploadedAt)
+			}
+			return group[i].slugPerm < group[j].slugPerm
+		})
+
+		var kept, keptSize int64
+		sizeExceeded := false
+		for _, pkg := range group {
+			outcome := retentionOutcome{pkg: pkg, group: key}
+			switch {
+			case rules.countLimit > 0 && kept >= rules.countLimit:
+				outcome.reason = retentionReasonCountLimit
+			case rules.daysLimit > 0 && pkg.uploadedAt.Before(cutoff):
+				outcome.reason = retentionReasonDaysLimit
+			case rules.sizeLimit > 0 && (sizeExceeded || keptSize+pkg.size > rules.sizeLimit):
+				sizeExceeded = true
+				outcome.reason = retentionReasonSizeLimit
+			default:
+				kept++
+				keptSize += pkg.size
+			}
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+func retentionPreviewPackageSchema(removed bool) *schema.Resource {
+	s := map[string]*schema.Schema{
+		"slug_perm": {
+			Type:        schema.TypeString,
+			Description: "The slug_perm of the package.",
+			Computed:    true,
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the package.",
+			Computed:    true,
+		},
+		"version": {
+			Type:        schema.TypeString,
+			Description: "The version of the package.",
+			Computed:    true,
+		},
+		"format": {
+			Type:        schema.TypeString,
+			Description: "The format of the package.",
+			Computed:    true,
+		},
+		"size": {
+			Type:        schema.TypeInt,
+			Description: "The size of the package, in bytes.",
+			Computed:    true,
+		},
+		"uploaded_at": {
+			Type:        schema.TypeString,
+			Description: "When the package was uploaded (RFC 3339).",
+			Computed:    true,
+		},
+		"group": {
+			Type:        schema.TypeString,
+			Description: "The retention group the package belongs to, built from the enabled group_by settings. Empty if packages aren't grouped.",
+			Computed:    true,
+		},
+	}
+	if removed {
+		s["reason"] = &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The limit that removes the package: `count_limit`, `days_limit` or `size_limit`.",
+			Computed:    true,
+		}
+	}
+	return &schema.Resource{Schema: s}
+}
+
+//nolint:funlen
+func dataSourceRepositoryRetentionPreview() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRepositoryRetentionPreviewRead,
+
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The namespace of the repository.",
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"repository": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The name of the repository.",
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"retention_count_limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				Description:  "The maximum number of packages to retain. Must be between 0 and 10000.",
+				ValidateFunc: validation.IntBetween(0, 10000),
+			},
+			"retention_days_limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      28,
+				Description:  "The number of days of packages to retain. Must be between 0 and 180. Defaults to 28 days.",
+				ValidateFunc: validation.IntBetween(0, 180),
+			},
+			"retention_group_by_format": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, retention will apply to packages by package formats rather than across all package formats.",
+			},
+			"retention_group_by_name": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, retention will apply to groups of packages by name rather than all packages.",
+			},
+			"retention_group_by_package_type": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, retention will apply to packages by package type rather than across all package types for one or more formats.",
+			},
+			"retention_size_limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "The maximum total size (in bytes) of packages to retain. Must be between 0 and 21474836480 (21.47 GB / 21474.83 MB).",
+				ValidateFunc: validateRetentionSizeLimit,
+			},
+			"retention_package_query_string": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "A package search expression which, if provided, filters the packages to be deleted.",
+			},
+			"kept": {
+				Type:        schema.TypeList,
+				Description: "The packages that would be kept, grouped and newest first.",
+				Computed:    true,
+				Elem:        retentionPreviewPackageSchema(false),
+			},
+			"removed": {
+				Type:        schema.TypeList,
+				Description: "The packages that would be deleted, grouped and newest first.",
+				Computed:    true,
+				Elem:        retentionPreviewPackageSchema(true),
+			},
+			"kept_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of packages that would be kept.",
+				Computed:    true,
+			},
+			"kept_size": {
+				Type:        schema.TypeInt,
+				Description: "The total size of the packages that would be kept, in bytes.",
+				Computed:    true,
+			},
+			"removed_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of packages that would be deleted.",
+				Computed:    true,
+			},
+			"removed_size": {
+				Type:        schema.TypeInt,
+				Description: "The total size of the packages that would be deleted, in bytes.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceRepositoryRetentionPreviewRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+	repository := requiredString(d, "repository")
+	query := d.Get("retention_package_query_string").(string)
+
+	exec := func(page, ps int64) ([]cloudsmith.Package, *http.Response, error) {
+		req := pc.APIClient.PackagesApi.PackagesList(pc.Auth, namespace, repository).
+			Page(page).
+			PageSize(ps).
+			Query(query)
+		return pc.APIClient.PackagesApi.PackagesListExecute(req)
+	}
+	packagesList, err := PaginateAllHTTP[cloudsmith.Package](exec, PaginationOptions{})
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error listing packages in repository %s/%s: %w", namespace, repository, formatAPIError(err)))
+	}
+
+	packages := make([]retentionPackage, len(packagesList))
+	for i, p := range packagesList {
+		packages[i] = retentionPackage{
+			slugPerm:    p.GetSlugPerm(),
+			name:        p.GetName(),
+			version:     p.GetVersion(),
+			format:      p.GetFormat(),
+			packageType: fmt.Sprint(p.GetPackageType()),
+			size:        int64(p.GetSize()),
+			uploadedAt:  p.GetUploadedAt(),
+		}
+	}
+
+	rules := retentionPreviewRules{
+		countLimit:         int64(d.Get("retention_count_limit").(int)),
+		daysLimit:          int64(d.Get("retention_days_limit").(int)),
+		sizeLimit:          int64(d.Get("retention_size_limit").(int)),
+		groupByName:        requiredBool(d, "retention_group_by_name"),
+		groupByFormat:      requiredBool(d, "retention_group_by_format"),
+		groupByPackageType: requiredBool(d, "retention_group_by_package_type"),
+	}
+
+	kept := make([]interface{}, 0)
+	removed := make([]interface{}, 0)
+	var keptSize, removedSize int64
+	for _, outcome := range previewRetention(packages, rules, time.Now().UTC()) {
+		pkg := map[string]interface{}{
+			"slug_perm":   outcome.pkg.slugPerm,
+			"name":        outcome.pkg.name,
+			"version":     outcome.pkg.version,
+			"format":      outcome.pkg.format,
+			"size":        outcome.pkg.size,
+			"uploaded_at": timeToString(outcome.pkg.uploadedAt),
+			"group":       outcome.group,
+		}
+		if outcome.reason == "" {
+			kept = append(kept, pkg)
+			keptSize += outcome.pkg.size
+			continue
+		}
+		pkg["reason"] = outcome.reason
+		removed = append(removed, pkg)
+		removedSize += outcome.pkg.size
+	}
+
+	fields := map[string]interface{}{
+		"kept":          kept,
+		"removed":       removed,
+		"kept_count":    len(kept),
+		"kept_size":     keptSize,
+		"removed_count": len(removed),
+		"removed_size":  removedSize,
+	}
+	for name, value := range fields {
+		if err := d.Set(name, value); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting retention preview field %q: %w", name, err))
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s", namespace, repository))
+	return nil
+}
@@ -0,0 +1,186 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPreviewRetention(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	packages := []retentionPackage{
+		{slugPerm: "a1", name: "app", format: "python", size: 10, uploadedAt: daysAgo(1)},
+		{slugPerm: "a2", name: "app", format: "python", size: 10, uploadedAt: daysAgo(2)},
+		{slugPerm: "a3", name: "app", format: "python", size: 10, uploadedAt: daysAgo(40)},
+		{slugPerm: "l1", name: "lib", format: "npm", size: 50, uploadedAt: daysAgo(3)},
+		{slugPerm: "l2", name: "lib", format: "npm", size: 50, uploadedAt: daysAgo(4)},
+	}
+
+	removed := func(outcomes []retentionOutcome) map[string]string {
+		out := make(map[string]string)
+		for _, o := range outcomes {
+			if o.reason != "" {
+				out[o.pkg.slugPerm] = o.reason
+			}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		rules   retentionPreviewRules
+		removed map[string]string
+	}{
+		{
+			name:    "no limits",
+			rules:   retentionPreviewRules{},
+			removed: map[string]string{},
+		},
+		{
+			name:  "count limit across all packages",
+			rules: retentionPreviewRules{countLimit: 2},
+			removed: map[string]string{
+				"l1": retentionReasonCountLimit,
+				"l2": retentionReasonCountLimit,
+				"a3": retentionReasonCountLimit,
+			},
+		},
+		{
+			name:  "count limit grouped by name",
+			rules: retentionPreviewRules{countLimit: 1, groupByName: true},
+			removed: map[string]string{
+				"a2": retentionReasonCountLimit,
+				"a3": retentionReasonCountLimit,
+				"l2": retentionReasonCountLimit,
+			},
+		},
+		{
+			name:    "days limit",
+			rules:   retentionPreviewRules{daysLimit: 28},
+			removed: map[string]string{"a3": retentionReasonDaysLimit},
+		},
+		{
+			name:  "size limit grouped by format",
+			rules: retentionPreviewRules{sizeLimit: 60, groupByFormat: true},
+			removed: map[string]string{
+				"l2": retentionReasonSizeLimit,
+			},
+		},
+		{
+			// a3 would fit after l1 and l2 are removed, but is older than
+			// packages already over the limit.
+			name:  "size limit removes everything older",
+			rules: retentionPreviewRules{sizeLimit: 55},
+			removed: map[string]string{
+				"l1": retentionReasonSizeLimit,
+				"l2": retentionReasonSizeLimit,
+				"a3": retentionReasonSizeLimit,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			outcomes := previewRetention(packages, tc.rules, now)
+			if len(outcomes) != len(packages) {
+				t.Fatalf("expected %d outcomes, got %d", len(packages), len(outcomes))
+			}
+			got := removed(outcomes)
+			if len(got) != len(tc.removed) {
+				t.Fatalf("unexpected removed packages: got %v, want %v", got, tc.removed)
+			}
+			for slug, reason := range tc.removed {
+				if got[slug] != reason {
+					t.Errorf("package %s: got reason %q, want %q", slug, got[slug], reason)
+				}
+			}
+		})
+	}
+}
+
+func TestDataSourceRepositoryRetentionPreviewRead(t *testing.T) {
+	t.Parallel()
+
+	recent := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	old := time.Now().UTC().AddDate(0, 0, -60).Format(time.RFC3339)
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimSuffix(r.URL.Path, "/") != "/packages/example-org/example-repo" {
+			http.Error(w, "unexpected request", http.StatusNotFound)
+			return
+		}
+		query = r.URL.Query().Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Pagination-Count", "2")
+		w.Header().Set("X-Pagination-Page", "1")
+		w.Header().Set("X-Pagination-PageTotal", "1")
+		w.Header().Set("X-Pagination-PageSize", "100")
+		fmt.Fprintf(w, `[
+			{"slug_perm":"new","name":"app","version":"2.0.0","format":"python","size":100,"uploaded_at":%q},
+			{"slug_perm":"old","name":"app","version":"1.0.0","format":"python","size":200,"uploaded_at":%q}
+		]`, recent, old)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceRepositoryRetentionPreview().Schema, map[string]interface{}{
+		"namespace":                      "example-org",
+		"repository":                     "example-repo",
+		"retention_package_query_string": "name:app",
+	})
+	diagnostics := dataSourceRepositoryRetentionPreviewRead(context.Background(), d, testPrivilegesProviderConfig(server))
+	if diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	if query != "name:app" {
+		t.Errorf("expected package query %q, got %q", "name:app", query)
+	}
+
+	expected := map[string]interface{}{
+		"kept_count":          1,
+		"kept_size":           100,
+		"kept.0.slug_perm":    "new",
+		"removed_count":       1,
+		"removed_size":        200,
+		"removed.0.slug_perm": "old",
+		"removed.0.reason":    retentionReasonDaysLimit,
+	}
+	for name, want := range expected {
+		if got := d.Get(name); got != want {
+			t.Errorf("unexpected %s: got %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestValidateRetentionSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	for value, wantErr := range map[int]bool{0: false, 200000: false, -1: true} {
+		if _, errs := validateRetentionSizeLimit(value, "retention_size_limit"); (len(errs) > 0) != wantErr {
+			t.Errorf("validateRetentionSizeLimit(%d) errors = %v, want error %v", value, errs, wantErr)
+		}
+	}
+
+	limit := dataSourceRepositoryRetentionPreview().Schema["retention_size_limit"]
+	if limit.ValidateFunc == nil {
+		t.Fatal("retention_size_limit has no ValidateFunc")
+	}
+	if _, errs := limit.ValidateFunc(-1, "retention_size_limit"); len(errs) == 0 {
+		t.Error("expected a negative retention_size_limit to be rejected")
+	}
+}
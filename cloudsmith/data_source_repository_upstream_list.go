@@ -0,0 +1,196 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceRepositoryUpstreamList() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRepositoryUpstreamListRead,
+
+		Schema: map[string]*schema.Schema{
+			Namespace: {
+				Type:         schema.TypeString,
+				Description:  "The Organization to which the Repository belongs.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			Repository: {
+				Type:         schema.TypeString,
+				Description:  "The Repository whose upstreams are listed.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			UpstreamType: {
+				Type:         schema.TypeString,
+				Description:  "If set, only upstreams of this type are listed. Otherwise upstreams of every type are listed.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(upstreamTypes, false),
+			},
+			"upstreams": {
+				Type:        schema.TypeList,
+				Description: "The upstreams configured on the repository, ordered by type, then priority.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						UpstreamType: {
+							Type:        schema.TypeString,
+							Description: "The type of the upstream.",
+							Computed:    true,
+						},
+						SlugPerm: {
+							Type:        schema.TypeString,
+							Description: "The unique identifier for the upstream.",
+							Computed:    true,
+						},
+						Name: {
+							Type:        schema.TypeString,
+							Description: "The name of the upstream.",
+							Computed:    true,
+						},
+						UpstreamUrl: {
+							Type:        schema.TypeString,
+							Description: "The URL of the upstream.",
+							Computed:    true,
+						},
+						Mode: {
+							Type:        schema.TypeString,
+							Description: "The mode the upstream operates in.",
+							Computed:    true,
+						},
+						Priority: {
+							Type:        schema.TypeInt,
+							Description: "The priority of the upstream.",
+							Computed:    true,
+						},
+						IsActive: {
+							Type:        schema.TypeBool,
+							Description: "Whether the upstream is active.",
+							Computed:    true,
+						},
+						DisableReason: {
+							Type:        schema.TypeString,
+							Description: "Why the upstream was disabled, if it is inactive.",
+							Computed:    true,
+						},
+						CreatedAt: {
+							Type:        schema.TypeString,
+							Description: "ISO 8601 timestamp at which the upstream was created.",
+							Computed:    true,
+						},
+						UpdatedAt: {
+							Type:        schema.TypeString,
+							Description: "ISO 8601 timestamp at which the upstream was updated.",
+							Computed:    true,
+						},
+						"import_id": {
+							Type:        schema.TypeString,
+							Description: "The ID to use when importing the upstream as a `cloudsmith_repository_upstream` resource.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type listedUpstream struct {
+	upstreamType string
+	upstream     Upstream
+}
+
+// listUpstreams returns every upstream of the given types on a repository,
+// paging through each format's list endpoint.
+func listUpstreams(pc *providerConfig, namespace, repository string, types []string) ([]listedUpstream, error) {
+	// The list endpoints also return 404 for a repository that doesn't exist,
+	// so read the repository first instead of listing no upstreams for it.
+	req := pc.APIClient.ReposApi.ReposRead(pc.Auth, namespace, repository)
+	if _, _, err := pc.APIClient.ReposApi.ReposReadExecute(req); err != nil {
+		return nil, fmt.Errorf("error reading repository %s/%s: %w", namespace, repository, formatAPIError(err))
+	}
+
+	var listed []listedUpstream
+	for _, upstreamType := range types {
+		format, err := lookupUpstreamFormat(upstreamType)
+		if err != nil {
+			return nil, err
+		}
+
+		exec := func(page, pageSize int64) ([]Upstream, *http.Response, error) {
+			upstreams, resp, err := format.list(pc, namespace, repository, page, pageSize)
+			if err != nil && is404(resp) {
+				// The repository doesn't support upstreams of this type.
+				return nil, resp, nil
+			}
+			return upstreams, resp, err
+		}
+		upstreams, err := PaginateAllHTTP[Upstream](exec, PaginationOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s upstreams for repository %s/%s: %w", upstreamType, namespace, repository, formatAPIError(err))
+		}
+
+		for _, upstream := range upstreams {
+			listed = append(listed, listedUpstream{upstreamType: upstreamType, upstream: upstream})
+		}
+	}
+
+	sort.SliceStable(listed, func(i, j int) bool {
+		if listed[i].upstreamType != listed[j].upstreamType {
+			return listed[i].upstreamType < listed[j].upstreamType
+		}
+		return listed[i].upstream.GetPriority() < listed[j].upstream.GetPriority()
+	})
+	return listed, nil
+}
+
+func dataSourceRepositoryUpstreamListRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, Namespace)
+	repository := requiredString(d, Repository)
+
+	types := upstreamTypes
+	id := fmt.Sprintf("%s/%s/upstreams", namespace, repository)
+	if upstreamType := d.Get(UpstreamType).(string); upstreamType != "" {
+		types = []string{upstreamType}
+		id = fmt.Sprintf("%s/%s", id, upstreamType)
+	}
+
+	listed, err := listUpstreams(pc, namespace, repository, types)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	upstreams := make([]interface{}, len(listed))
+	for i, l := range listed {
+		u := l.upstream
+		upstreams[i] = map[string]interface{}{
+			UpstreamType:  l.upstreamType,
+			SlugPerm:      u.GetSlugPerm(),
+			Name:          u.GetName(),
+			UpstreamUrl:   u.GetUpstreamUrl(),
+			Mode:          u.GetMode(),
+			Priority:      u.GetPriority(),
+			IsActive:      u.GetIsActive(),
+			DisableReason: actionableUpstreamDisableReason(u.GetDisableReasonText(), u.GetDisableReason()),
+			CreatedAt:     timeToString(u.GetCreatedAt()),
+			UpdatedAt:     timeToString(u.GetUpdatedAt()),
+			"import_id":   fmt.Sprintf("%s.%s.%s.%s", namespace, repository, l.upstreamType, u.GetSlugPerm()),
+		}
+	}
+
+	if err := d.Set("upstreams", upstreams); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting upstreams: %w", err))
+	}
+
+	d.SetId(id)
+	return nil
+}
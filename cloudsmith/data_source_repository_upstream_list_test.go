@@ -0,0 +1,163 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// upstreamListTestServer serves example-org/example-repo with two pages of
+// python upstreams and one npm upstream. Every other format's list endpoint
+// returns 404. Requests other than the repository read are recorded.
+func upstreamListTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := strings.TrimSuffix(r.URL.Path, "/")
+		if path == "/repos/example-org/example-repo" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"slug":"example-repo","slug_perm":"example-repo"}`)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		*requests = append(*requests, fmt.Sprintf("%s?page=%s", path, page))
+
+		var body string
+		pageTotal := 1
+		switch path {
+		case "/repos/example-org/example-repo/upstream/python":
+			pageTotal = 2
+			if page == "2" {
+				body = `[{"slug_perm":"py-first","name":"pypi","upstream_url":"https://pypi.org","mode":"Proxy Only","priority":1,"is_active":true}]`
+			} else {
+				body = `[{"slug_perm":"py-second","name":"mirror","upstream_url":"https://mirror.example.com","mode":"Cache and Proxy","priority":2,"is_active":false,"disable_reason":"N/A","disable_reason_text":"Upstream returned 401"}]`
+			}
+		case "/repos/example-org/example-repo/upstream/npm":
+			body = `[{"slug_perm":"npm-only","name":"npmjs","upstream_url":"https://registry.npmjs.org","mode":"Proxy Only","priority":1,"is_active":true}]`
+		default:
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Pagination-Count", "2")
+		w.Header().Set("X-Pagination-Page", page)
+		w.Header().Set("X-Pagination-PageTotal", strconv.Itoa(pageTotal))
+		w.Header().Set("X-Pagination-PageSize", "1")
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestDataSourceRepositoryUpstreamListRead(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	server := upstreamListTestServer(t, &requests)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceRepositoryUpstreamList().Schema, map[string]interface{}{
+		Namespace:  "example-org",
+		Repository: "example-repo",
+	})
+	diagnostics := dataSourceRepositoryUpstreamListRead(context.Background(), d, testPrivilegesProviderConfig(server))
+	if diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	if d.Id() != "example-org/example-repo/upstreams" {
+		t.Errorf("unexpected ID %q", d.Id())
+	}
+	if len(requests) != len(upstreamTypes)+1 {
+		t.Errorf("expected one request per format plus one for the second python page, got %d", len(requests))
+	}
+
+	expected := map[string]interface{}{
+		"upstreams.#":                "3",
+		"upstreams.0.upstream_type":  Npm,
+		"upstreams.0.slug_perm":      "npm-only",
+		"upstreams.0.import_id":      "example-org.example-repo.npm.npm-only",
+		"upstreams.1.slug_perm":      "py-first",
+		"upstreams.1.name":           "pypi",
+		"upstreams.1.upstream_url":   "https://pypi.org",
+		"upstreams.1.mode":           "Proxy Only",
+		"upstreams.1.priority":       "1",
+		"upstreams.1.is_active":      "true",
+		"upstreams.1.disable_reason": "",
+		"upstreams.2.slug_perm":      "py-second",
+		"upstreams.2.priority":       "2",
+		"upstreams.2.is_active":      "false",
+		"upstreams.2.disable_reason": "Upstream returned 401",
+	}
+	state := d.State()
+	for key, want := range expected {
+		if got := state.Attributes[key]; got != want {
+			t.Errorf("unexpected %s: got %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestDataSourceRepositoryUpstreamListReadFiltered(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	server := upstreamListTestServer(t, &requests)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceRepositoryUpstreamList().Schema, map[string]interface{}{
+		Namespace:    "example-org",
+		Repository:   "example-repo",
+		UpstreamType: Python,
+	})
+	diagnostics := dataSourceRepositoryUpstreamListRead(context.Background(), d, testPrivilegesProviderConfig(server))
+	if diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	if d.Id() != "example-org/example-repo/upstreams/python" {
+		t.Errorf("unexpected ID %q", d.Id())
+	}
+	expectedRequests := []string{
+		"/repos/example-org/example-repo/upstream/python?page=1",
+		"/repos/example-org/example-repo/upstream/python?page=2",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expectedRequests, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(requests, "\n"), strings.Join(expectedRequests, "\n"))
+	}
+	if got := d.Get("upstreams.#").(int); got != 2 {
+		t.Errorf("expected 2 upstreams, got %d", got)
+	}
+}
+
+func TestDataSourceRepositoryUpstreamListReadMissingRepository(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	server := upstreamListTestServer(t, &requests)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceRepositoryUpstreamList().Schema, map[string]interface{}{
+		Namespace:  "example-org",
+		Repository: "exmaple-repo",
+	})
+	diagnostics := dataSourceRepositoryUpstreamListRead(context.Background(), d, testPrivilegesProviderConfig(server))
+	if !diagnostics.HasError() {
+		t.Fatal("expected an error for a repository that doesn't exist")
+	}
+	for _, request := range requests {
+		if strings.Contains(request, "/upstream/") {
+			t.Errorf("expected no upstreams to be listed, got request %s", request)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	usageInterval  = "interval"
+	usageDownloads = "downloads"
+
+	// defaultRepositoryUsageWindow is used when no start is configured.
+	defaultRepositoryUsageWindow = 30 * 24 * time.Hour
+
+	// maxRepositoryUsageBuckets bounds the number of metrics requests a single
+	// read can issue when history buckets are requested.
+	maxRepositoryUsageBuckets = 366
+)
+
+var repositoryUsageIntervals = map[string]func(time.Time) time.Time{
+	"day":   func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	"week":  func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	"month": func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+}
+
+func dataSourceRepositoryUsage() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRepositoryUsageRead,
+
+		Schema: map[string]*schema.Schema{
+			Namespace: {
+				Type:         schema.TypeString,
+				Description:  "Organization to which the Repository belongs.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			Repository: {
+				Type:         schema.TypeString,
+				Description:  "Repository whose usage is read.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			usageStart: {
+				Type:         schema.TypeString,
+				Description:  "The start of the reporting window (RFC 3339). Defaults to 30 days before `finish`.",
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			usageFinish: {
+				Type:         schema.TypeString,
+				Description:  "The end of the reporting window (RFC 3339). Defaults to the time of the read.",
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			usageInterval: {
+				Type:         schema.TypeString,
+				Description:  "If set, the reporting window is split into `day`, `week` or `month` buckets returned in `history`.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"day", "week", "month"}, false),
+			},
+			usageStorageUsed: {
+				Type:        schema.TypeInt,
+				Description: "The storage currently used by the repository, in bytes.",
+				Computed:    true,
+			},
+			"storage_used_display": {
+				Type:        schema.TypeString,
+				Description: "The storage currently used by the repository, in human-readable form.",
+				Computed:    true,
+			},
+			"package_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of packages in the repository.",
+				Computed:    true,
+			},
+			usageBandwidthUsed: {
+				Type:        schema.TypeFloat,
+				Description: "The package delivery bandwidth used by the repository within the reporting window, in `bandwidth_unit`.",
+				Computed:    true,
+			},
+			"bandwidth_unit": {
+				Type:        schema.TypeString,
+				Description: "The unit reported by the API for the bandwidth values.",
+				Computed:    true,
+			},
+			usageDownloads: {
+				Type:        schema.TypeInt,
+				Description: "The number of package downloads from the repository within the reporting window.",
+				Computed:    true,
+			},
+			usageHistory: {
+				Type:        schema.TypeList,
+				Description: "Usage for each `interval` bucket within the reporting window, oldest first.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						usageStart: {
+							Type:        schema.TypeString,
+							Description: "The start of the bucket (RFC 3339).",
+							Computed:    true,
+						},
+						usageFinish: {
+							Type:        schema.TypeString,
+							Description: "The end of the bucket (RFC 3339).",
+							Computed:    true,
+						},
+						usageBandwidthUsed: {
+							Type:        schema.TypeFloat,
+							Description: "The package delivery bandwidth used during the bucket, in the bucket's `bandwidth_unit`.",
+							Computed:    true,
+						},
+						"bandwidth_unit": {
+							Type:        schema.TypeString,
+							Description: "The unit reported by the API for the bucket's bandwidth, which can differ between buckets.",
+							Computed:    true,
+						},
+						usageDownloads: {
+							Type:        schema.TypeInt,
+							Description: "The number of package downloads during the bucket.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRepositoryUsageRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, Namespace)
+	repository := requiredString(d, Repository)
+
+	finish := stringToTime(d.Get(usageFinish).(string))
+	if finish.IsZero() {
+		finish = time.Now().UTC()
+	}
+	start := stringToTime(d.Get(usageStart).(string))
+	if start.IsZero() {
+		start = finish.Add(-defaultRepositoryUsageWindow)
+	}
+	if !start.Before(finish) {
+		return diag.Errorf("%q must be before %q", usageStart, usageFinish)
+	}
+
+	req := pc.APIClient.ReposApi.ReposRead(pc.Auth, namespace, repository)
+	repo, _, err := pc.APIClient.ReposApi.ReposReadExecute(req)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error reading repository %s/%s: %w", namespace, repository, formatAPIError(err)))
+	}
+
+	total, err := readRepositoryBandwidth(pc, namespace, repository, start, finish)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var history []interface{}
+	if interval := d.Get(usageInterval).(string); interval != "" {
+		buckets, err := repositoryUsageBuckets(start, finish, repositoryUsageIntervals[interval])
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, bucket := range buckets {
+			usage, err := readRepositoryBandwidth(pc, namespace, repository, bucket[0], bucket[1])
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			history = append(history, map[string]interface{}{
+				usageStart:         timeToString(bucket[0]),
+				usageFinish:        timeToString(bucket[1]),
+				usageBandwidthUsed: usage.bandwidth,
+				"bandwidth_unit":   usage.unit,
+				usageDownloads:     usage.downloads,
+			})
+		}
+	}
+
+	fields := map[string]interface{}{
+		usageStart:             timeToString(start),
+		usageFinish:            timeToString(finish),
+		usageStorageUsed:       repo.GetSize(),
+		"storage_used_display": repo.GetSizeStr(),
+		"package_count":        repo.GetPackageCount(),
+		usageBandwidthUsed:     total.bandwidth,
+		"bandwidth_unit":       total.unit,
+		usageDownloads:         total.downloads,
+		usageHistory:           history,
+	}
+	for name, value := range fields {
+		if err := d.Set(name, value); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting repository usage field %q: %w", name, err))
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/usage", namespace, repository))
+	return nil
+}
+
+type repositoryBandwidth struct {
+	bandwidth float64
+	unit      string
+	downloads int64
+}
+
+// readRepositoryBandwidth returns the package delivery metrics for a
+// repository between start and finish.
+func readRepositoryBandwidth(pc *providerConfig, namespace, repository string, start, finish time.Time) (repositoryBandwidth, error) {
+	req := pc.APIClient.MetricsApi.MetricsPackagesList(pc.Auth, namespace, repository).
+		Start(timeToString(start)).
+		Finish(timeToString(finish))
+	metrics, _, err := pc.APIClient.MetricsApi.MetricsPackagesListExecute(req)
+	if err != nil {
+		return repositoryBandwidth{}, fmt.Errorf("error reading package metrics for repository %s/%s: %w", namespace, repository, formatAPIError(err))
+	}
+
+	bandwidth := metrics.GetBandwidth()
+	bandwidthTotal := bandwidth.GetTotal()
+	downloads := metrics.GetDownloads()
+	downloadsTotal := downloads.GetTotal()
+
+	return repositoryBandwidth{
+		bandwidth: float64(bandwidthTotal.GetValue()),
+		unit:      bandwidthTotal.GetUnits(),
+		downloads: int64(downloadsTotal.GetValue()),
+	}, nil
+}
+
+// repositoryUsageBuckets splits [start, finish) into consecutive buckets using
+// next to advance the bucket start. The final bucket is truncated at finish.
+func repositoryUsageBuckets(start, finish time.Time, next func(time.Time) time.Time) ([][2]time.Time, error) {
+	var buckets [][2]time.Time
+	for bucketStart := start; bucketStart.Before(finish); bucketStart = next(bucketStart) {
+		if len(buckets) == maxRepositoryUsageBuckets {
+			return nil, fmt.Errorf("reporting window contains more than %d buckets, use a larger interval or a shorter window", maxRepositoryUsageBuckets)
+		}
+		bucketFinish := next(bucketStart)
+		if bucketFinish.After(finish) {
+			bucketFinish = finish
+		}
+		buckets = append(buckets, [2]time.Time{bucketStart, bucketFinish})
+	}
+	return buckets, nil
+}
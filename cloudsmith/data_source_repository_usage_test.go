@@ -0,0 +1,101 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRepositoryUsageBuckets(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	finish := time.Date(2026, 1, 17, 12, 0, 0, 0, time.UTC)
+
+	buckets, err := repositoryUsageBuckets(start, finish, repositoryUsageIntervals["week"])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(buckets) != 3 {
+		t.Fatalf("expected 3 buckets, got %d", len(buckets))
+	}
+	if !buckets[0][0].Equal(start) || !buckets[0][1].Equal(start.AddDate(0, 0, 7)) {
+		t.Errorf("unexpected first bucket: %v", buckets[0])
+	}
+	if !buckets[2][1].Equal(finish) {
+		t.Errorf("expected last bucket to be truncated at finish, got %v", buckets[2][1])
+	}
+
+	if _, err := repositoryUsageBuckets(start, start.AddDate(2, 0, 0), repositoryUsageIntervals["day"]); err == nil {
+		t.Fatal("expected an error when the window exceeds the bucket limit")
+	}
+}
+
+func TestDataSourceRepositoryUsageRead(t *testing.T) {
+	t.Parallel()
+
+	var metricsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/example-org/example-repo/":
+			fmt.Fprint(w, `{"name":"example-repo","slug":"example-repo","size":2048,"size_str":"2.0 KB","package_count":3}`)
+		case "/metrics/packages/example-org/example-repo/":
+			metricsRequests.Add(1)
+			if r.URL.Query().Get("start") == "" || r.URL.Query().Get("finish") == "" {
+				http.Error(w, "missing window", http.StatusBadRequest)
+				return
+			}
+			if r.URL.Query().Get("start") == "2026-01-02T00:00:00Z" {
+				fmt.Fprint(w, `{"bandwidth":{"total":{"value":2.5,"units":"MB"}},"downloads":{"total":{"value":4}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"bandwidth":{"total":{"value":1.5,"units":"KB"}},"downloads":{"total":{"value":4}}}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceRepositoryUsage().Schema, map[string]interface{}{
+		Namespace:     "example-org",
+		Repository:    "example-repo",
+		usageStart:    "2026-01-01T00:00:00Z",
+		usageFinish:   "2026-01-03T00:00:00Z",
+		usageInterval: "day",
+	})
+	diagnostics := dataSourceRepositoryUsageRead(context.Background(), d, usageLimitsTestProviderConfig(server.URL))
+	if diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	expected := map[string]interface{}{
+		usageStorageUsed:           2048,
+		"storage_used_display":     "2.0 KB",
+		"package_count":            3,
+		usageBandwidthUsed:         1.5,
+		"bandwidth_unit":           "KB",
+		usageDownloads:             4,
+		"history.#":                2,
+		"history.1.start":          "2026-01-02T00:00:00Z",
+		"history.1.finish":         "2026-01-03T00:00:00Z",
+		"history.0.bandwidth_used": 1.5,
+		"history.0.bandwidth_unit": "KB",
+		"history.1.bandwidth_used": 2.5,
+		"history.1.bandwidth_unit": "MB",
+	}
+	for name, want := range expected {
+		if got := d.Get(name); got != want {
+			t.Errorf("unexpected %s: got %v, want %v", name, got, want)
+		}
+	}
+	if got := metricsRequests.Load(); got != 3 {
+		t.Errorf("expected 3 metrics requests (total plus 2 buckets), got %d", got)
+	}
+}
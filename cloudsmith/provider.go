@@ -35,37 +35,48 @@ func Provider() *schema.Provider {
 			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"cloudsmith_namespace":                 dataSourceNamespace(),
-			"cloudsmith_oidc":                      dataSourceOidc(),
-			"cloudsmith_organization":              dataSourceOrganization(),
-			"cloudsmith_package":                   dataSourcePackage(),
-			"cloudsmith_package_list":              dataSourcePackageList(),
-			"cloudsmith_repository":                dataSourceRepository(),
-			"cloudsmith_repository_connected_list": dataSourceRepositoryConnectedList(),
-			"cloudsmith_repository_privileges":     dataSourceRepositoryPrivileges(),
-			"cloudsmith_package_deny_policy":       dataSourcePackageDenyPolicy(),
-			"cloudsmith_policy":                    dataSourcePolicy(),
-			"cloudsmith_policy_list":               dataSourcePolicyList(),
-			"cloudsmith_entitlement_list":          dataSourceEntitlementList(),
-			"cloudsmith_list_org_members":          dataSourceOrganizationMembersList(),
-			"cloudsmith_org_member_details":        dataSourceMemberDetails(),
-			"cloudsmith_user_self":                 dataSourceUserSelf(),
-			"cloudsmith_team_list":                 dataSourceTeamList(),
-			"cloudsmith_team_members":              dataSourceTeamMembers(),
-			"cloudsmith_service_list":              dataSourceServiceList(),
-			"cloudsmith_service_details":           dataSourceServiceDetails(),
-			"cloudsmith_usage_limits":              dataSourceUsageLimits(),
+			"cloudsmith_distros":                      dataSourceDistros(),
+			"cloudsmith_namespace":                    dataSourceNamespace(),
+			"cloudsmith_oidc":                         dataSourceOidc(),
+			"cloudsmith_organization":                 dataSourceOrganization(),
+			"cloudsmith_package":                      dataSourcePackage(),
+			"cloudsmith_package_list":                 dataSourcePackageList(),
+			"cloudsmith_repository":                   dataSourceRepository(),
+			"cloudsmith_repository_connected_list":    dataSourceRepositoryConnectedList(),
+			"cloudsmith_repository_privileges":        dataSourceRepositoryPrivileges(),
+			"cloudsmith_repository_geo_ip_evaluation": dataSourceRepositoryGeoIpEvaluation(),
+			"cloudsmith_package_deny_policy":          dataSourcePackageDenyPolicy(),
+			"cloudsmith_policy":                       dataSourcePolicy(),
+			"cloudsmith_policy_list":                  dataSourcePolicyList(),
+			"cloudsmith_entitlement":                  dataSourceEntitlementToken(),
+			"cloudsmith_entitlement_list":             dataSourceEntitlementList(),
+			"cloudsmith_list_org_members":             dataSourceOrganizationMembersList(),
+			"cloudsmith_org_member_details":           dataSourceMemberDetails(),
+			"cloudsmith_user_self":                    dataSourceUserSelf(),
+			"cloudsmith_team_list":                    dataSourceTeamList(),
+			"cloudsmith_team_members":                 dataSourceTeamMembers(),
+			"cloudsmith_service_list":                 dataSourceServiceList(),
+			"cloudsmith_service_details":              dataSourceServiceDetails(),
+			"cloudsmith_usage_limits":                 dataSourceUsageLimits(),
+			"cloudsmith_organization_usage":           dataSourceOrganizationUsage(),
+			"cloudsmith_repository_usage":             dataSourceRepositoryUsage(),
+			"cloudsmith_repository_retention_preview": dataSourceRepositoryRetentionPreview(),
+			"cloudsmith_repository_upstream_list":     dataSourceRepositoryUpstreamList(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"cloudsmith_entitlement":               resourceEntitlement(),
+			"cloudsmith_customer_access":           resourceCustomerAccess(),
 			"cloudsmith_license_policy":            resourceLicensePolicy(),
 			"cloudsmith_repository":                resourceRepository(),
 			"cloudsmith_repository_connected":      resourceRepositoryConnected(),
 			"cloudsmith_repository_geo_ip_rules":   resourceRepositoryGeoIpRules(),
 			"cloudsmith_repository_privileges":     resourceRepositoryPrivileges(),
+			"cloudsmith_repository_privilege":      resourceRepositoryPrivilege(),
 			"cloudsmith_repository_upstream":       resourceRepositoryUpstream(),
+			"cloudsmith_repository_upstream_order": resourceRepositoryUpstreamOrder(),
 			"cloudsmith_service":                   resourceService(),
 			"cloudsmith_team":                      resourceTeam(),
+			"cloudsmith_team_member":               resourceTeamMember(),
 			"cloudsmith_vulnerability_policy":      resourceVulnerabilityPolicy(),
 			"cloudsmith_webhook":                   resourceWebhook(),
 			"cloudsmith_package_deny_policy":       packageDenyPolicy(),
@@ -73,10 +84,13 @@ func Provider() *schema.Provider {
 			"cloudsmith_policy":                    resourcePolicy(),
 			"cloudsmith_policy_action":             resourcePolicyAction(),
 			"cloudsmith_manage_team":               resourceManageTeam(),
+			"cloudsmith_org_invite":                resourceOrgInvite(),
+			"cloudsmith_org_member":                resourceOrgMember(),
 			"cloudsmith_saml":                      resourceSAML(),
 			"cloudsmith_saml_auth":                 resourceSAMLAuth(),
 			"cloudsmith_repository_retention_rule": resourceRepoRetentionRule(),
 			"cloudsmith_entitlement_control":       resourceEntitlementControl(),
+			"cloudsmith_entitlement_sync":          resourceEntitlementSync(),
 			"cloudsmith_usage_limits":              resourceUsageLimits(),
 		},
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/cloudsmith-io/cloudsmith-api-go"
 	cloudsmithv2 "github.com/cloudsmith-io/cloudsmith-go-v2"
@@ -22,6 +23,10 @@ type providerConfig struct {
 	APIClient *cloudsmith.APIClient
 
 	V2ApiClient *cloudsmithv2.Cloudsmith
+
+	// distributions supported by Cloudsmith, fetched once by listDistros
+	distrosMu sync.Mutex
+	distros   []distro
 }
 
 func newProviderConfig(apiHost, apiKey string, headers map[string]interface{}, userAgent string) (*providerConfig, diag.Diagnostics) {
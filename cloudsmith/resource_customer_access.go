@@ -0,0 +1,450 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// customerAccessSharedKeys are the attributes applied to the entitlement of
+// every repository.
+var customerAccessSharedKeys = []string{
+	"access_private_broadcasts",
+	"is_active",
+	"limit_date_range_from",
+	"limit_date_range_to",
+	"limit_num_clients",
+	"limit_num_downloads",
+	"limit_package_query",
+	"name",
+}
+
+// customerAccessEntitlements returns the slug_perm of the entitlement of each
+// repository, as recorded in state before this apply.
+func customerAccessEntitlements(d *schema.ResourceData) map[string]string {
+	old, _ := d.GetChange("entitlements")
+
+	entitlements := make(map[string]string)
+	for repository, slugPerm := range old.(map[string]interface{}) {
+		entitlements[repository] = slugPerm.(string)
+	}
+	return entitlements
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sharedCustomerAccessValue returns the value to record for an attribute shared
+// by every entitlement: the first value that differs from current, so that a
+// change to any single entitlement shows as drift, or current if none differ.
+func sharedCustomerAccessValue(current interface{}, values []interface{}) interface{} {
+	for _, v := range values {
+		if v != current {
+			return v
+		}
+	}
+	return current
+}
+
+// importCustomerAccess imports existing entitlements from an ID of the form
+// <namespace>.<repository>=<slug_perm>,<repository>=<slug_perm>,...
+func importCustomerAccess(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	invalid := fmt.Errorf(
+		"invalid import ID, must be of the form <namespace_slug>.<repository_slug>=<entitlement_slug_perm>,..., got: %s", d.Id(),
+	)
+
+	namespace, pairs, ok := strings.Cut(d.Id(), ".")
+	if !ok || namespace == "" {
+		return nil, invalid
+	}
+	entitlements := make(map[string]string)
+	for _, pair := range strings.Split(pairs, ",") {
+		repository, slugPerm, ok := strings.Cut(pair, "=")
+		if !ok || repository == "" || slugPerm == "" {
+			return nil, invalid
+		}
+		if _, ok := entitlements[repository]; ok {
+			return nil, fmt.Errorf("repository %s is listed more than once in import ID: %s", repository, d.Id())
+		}
+		entitlements[repository] = slugPerm
+	}
+
+	_ = d.Set("namespace", namespace)
+	if err := d.Set("entitlements", entitlements); err != nil {
+		return nil, err
+	}
+	d.SetId(id.UniqueId())
+	return []*schema.ResourceData{d}, nil
+}
+
+func createCustomerAccessEntitlement(pc *providerConfig, d *schema.ResourceData, namespace, repository string) (string, error) {
+	limitDateRangeFrom, limitDateRangeTo, err := expandEntitlementDateRange(d)
+	if err != nil {
+		return "", err
+	}
+
+	req := pc.APIClient.EntitlementsApi.EntitlementsCreate(pc.Auth, namespace, repository)
+	req = req.Data(cloudsmith.RepositoryTokenRequest{
+		IsActive:           cloudsmith.PtrBool(requiredBool(d, "is_active")),
+		LimitDateRangeFrom: limitDateRangeFrom,
+		LimitDateRangeTo:   limitDateRangeTo,
+		LimitNumClients:    nullableInt64(d, "limit_num_clients"),
+		LimitNumDownloads:  nullableInt64(d, "limit_num_downloads"),
+		LimitPackageQuery:  nullableString(d, "limit_package_query"),
+		Name:               requiredString(d, "name"),
+	})
+
+	entitlement, _, err := pc.APIClient.EntitlementsApi.EntitlementsCreateExecute(req)
+	if err != nil {
+		return "", fmt.Errorf("error creating entitlement in repository %s/%s: %w", namespace, repository, formatAPIError(err))
+	}
+	slugPerm := entitlement.GetSlugPerm()
+
+	if err := waitForCreation(func() (*http.Response, error) {
+		req := pc.APIClient.EntitlementsApi.EntitlementsRead(pc.Auth, namespace, repository, slugPerm)
+		_, resp, err := pc.APIClient.EntitlementsApi.EntitlementsReadExecute(req)
+		return resp, err
+	}, "entitlement", slugPerm); err != nil {
+		return slugPerm, err
+	}
+
+	if requiredBool(d, "access_private_broadcasts") {
+		if err := setEntitlementPrivateBroadcasts(pc, namespace, repository, slugPerm, true); err != nil {
+			return slugPerm, fmt.Errorf("error enabling private broadcasts for entitlement (%s): %w", slugPerm, formatAPIError(err))
+		}
+	}
+	return slugPerm, nil
+}
+
+func updateCustomerAccessEntitlement(pc *providerConfig, d *schema.ResourceData, namespace, repository, slugPerm string) error {
+	limitDateRangeFrom, limitDateRangeTo, err := expandEntitlementDateRange(d)
+	if err != nil {
+		return err
+	}
+
+	req := pc.APIClient.EntitlementsApi.EntitlementsPartialUpdate(pc.Auth, namespace, repository, slugPerm)
+	req = req.Data(cloudsmith.RepositoryTokenRequestPatch{
+		IsActive:           cloudsmith.PtrBool(requiredBool(d, "is_active")),
+		LimitDateRangeFrom: limitDateRangeFrom,
+		LimitDateRangeTo:   limitDateRangeTo,
+		LimitNumClients:    nullableInt64(d, "limit_num_clients"),
+		LimitNumDownloads:  nullableInt64(d, "limit_num_downloads"),
+		LimitPackageQuery:  nullableString(d, "limit_package_query"),
+		Name:               optionalString(d, "name"),
+	})
+
+	if _, _, err := pc.APIClient.EntitlementsApi.EntitlementsPartialUpdateExecute(req); err != nil {
+		return fmt.Errorf("error updating entitlement (%s) in repository %s/%s: %w", slugPerm, namespace, repository, formatAPIError(err))
+	}
+
+	if d.HasChange("access_private_broadcasts") {
+		if err := setEntitlementPrivateBroadcasts(pc, namespace, repository, slugPerm, requiredBool(d, "access_private_broadcasts")); err != nil {
+			return fmt.Errorf("error setting private broadcasts for entitlement (%s): %w", slugPerm, formatAPIError(err))
+		}
+	}
+	return nil
+}
+
+func deleteCustomerAccessEntitlement(pc *providerConfig, namespace, repository, slugPerm string) error {
+	req := pc.APIClient.EntitlementsApi.EntitlementsDelete(pc.Auth, namespace, repository, slugPerm)
+	if resp, err := pc.APIClient.EntitlementsApi.EntitlementsDeleteExecute(req); err != nil {
+		if is404(resp) {
+			return nil
+		}
+		return fmt.Errorf("error deleting entitlement (%s) in repository %s/%s: %w", slugPerm, namespace, repository, formatAPIError(err))
+	}
+
+	return waitForDeletion(func() (*http.Response, error) {
+		req := pc.APIClient.EntitlementsApi.EntitlementsRead(pc.Auth, namespace, repository, slugPerm)
+		_, resp, err := pc.APIClient.EntitlementsApi.EntitlementsReadExecute(req)
+		return resp, err
+	}, "entitlement", slugPerm)
+}
+
+// resourceCustomerAccessUpdate brings the entitlements in line with the
+// configuration: entitlements of repositories no longer listed are deleted,
+// those of remaining repositories are updated if a shared setting changed,
+// and newly listed repositories get an entitlement. entitlements is recorded
+// after every change, so that a failure part way through leaves state
+// matching what was done.
+func resourceCustomerAccessUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+	entitlements := customerAccessEntitlements(d)
+	repositories := expandStrings(d, "repositories")
+
+	if d.Id() == "" {
+		d.SetId(id.UniqueId())
+	}
+
+	record := func() diag.Diagnostics {
+		if err := d.Set("entitlements", entitlements); err != nil {
+			return diag.FromErr(err)
+		}
+		return nil
+	}
+
+	for _, repository := range sortedKeys(entitlements) {
+		if contains(repositories, repository) {
+			continue
+		}
+		if err := deleteCustomerAccessEntitlement(pc, namespace, repository, entitlements[repository]); err != nil {
+			return append(record(), diag.FromErr(err)...)
+		}
+		delete(entitlements, repository)
+	}
+
+	updated := false
+	if d.HasChanges(customerAccessSharedKeys...) {
+		for _, repository := range sortedKeys(entitlements) {
+			if err := updateCustomerAccessEntitlement(pc, d, namespace, repository, entitlements[repository]); err != nil {
+				return append(record(), diag.FromErr(err)...)
+			}
+			updated = true
+		}
+	}
+
+	sort.Strings(repositories)
+	for _, repository := range repositories {
+		if _, ok := entitlements[repository]; ok {
+			continue
+		}
+		slugPerm, err := createCustomerAccessEntitlement(pc, d, namespace, repository)
+		if slugPerm != "" {
+			entitlements[repository] = slugPerm
+		}
+		if err != nil {
+			return append(record(), diag.FromErr(err)...)
+		}
+	}
+
+	if diags := record(); diags.HasError() {
+		return diags
+	}
+
+	if updated {
+		if err := waitForUpdate("customer access", d.Id()); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceCustomerAccessRead(ctx, d, m)
+}
+
+// resourceCustomerAccessRead records the entitlements that still exist. Those
+// deleted outside of Terraform are dropped with a warning, so that the next
+// apply recreates them.
+func resourceCustomerAccessRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+
+	var diags diag.Diagnostics
+	found := make(map[string]string)
+	tokens := make(map[string]string)
+	values := make(map[string][]interface{})
+	for repository, slugPerm := range d.Get("entitlements").(map[string]interface{}) {
+		req := pc.APIClient.EntitlementsApi.EntitlementsRead(pc.Auth, namespace, repository, slugPerm.(string))
+		req = req.ShowTokens(true)
+		entitlement, resp, err := pc.APIClient.EntitlementsApi.EntitlementsReadExecute(req)
+		if err != nil {
+			if is404(resp) {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  fmt.Sprintf("Entitlement (%s) in repository %s/%s not found", slugPerm, namespace, repository),
+					Detail:   "The entitlement was deleted outside of Terraform. It will be recreated on the next apply.",
+				})
+				continue
+			}
+			return append(diags, diag.FromErr(fmt.Errorf("error reading entitlement (%s) in repository %s/%s: %w", slugPerm, namespace, repository, formatAPIError(err)))...)
+		}
+
+		found[repository] = entitlement.GetSlugPerm()
+		tokens[repository] = entitlement.GetToken()
+		values["access_private_broadcasts"] = append(values["access_private_broadcasts"], entitlement.GetAccessPrivateBroadcasts())
+		values["is_active"] = append(values["is_active"], entitlement.GetIsActive())
+		values["limit_date_range_from"] = append(values["limit_date_range_from"], timeToString(entitlement.GetLimitDateRangeFrom()))
+		values["limit_date_range_to"] = append(values["limit_date_range_to"], timeToString(entitlement.GetLimitDateRangeTo()))
+		values["limit_num_clients"] = append(values["limit_num_clients"], int(entitlement.GetLimitNumClients()))
+		values["limit_num_downloads"] = append(values["limit_num_downloads"], int(entitlement.GetLimitNumDownloads()))
+		values["limit_package_query"] = append(values["limit_package_query"], entitlement.GetLimitPackageQuery())
+		values["name"] = append(values["name"], entitlement.GetName())
+	}
+
+	if len(found) == 0 {
+		d.SetId("")
+		return diags
+	}
+
+	repositories := make([]string, 0, len(found))
+	for repository := range found {
+		repositories = append(repositories, repository)
+	}
+
+	if err := d.Set("repositories", repositories); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	if err := d.Set("entitlements", found); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	if err := d.Set("tokens", tokens); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	for _, key := range customerAccessSharedKeys {
+		if err := d.Set(key, sharedCustomerAccessValue(d.Get(key), values[key])); err != nil {
+			return append(diags, diag.FromErr(fmt.Errorf("error setting %s: %w", key, err))...)
+		}
+	}
+
+	return diags
+}
+
+func resourceCustomerAccessDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+	entitlements := customerAccessEntitlements(d)
+
+	for _, repository := range sortedKeys(entitlements) {
+		if err := deleteCustomerAccessEntitlement(pc, namespace, repository, entitlements[repository]); err != nil {
+			// Keep the entitlements that weren't deleted in state.
+			_ = d.Set("entitlements", entitlements)
+			return diag.FromErr(err)
+		}
+		delete(entitlements, repository)
+	}
+
+	return nil
+}
+
+// customizeDiffCustomerAccess checks the date range, and plans new
+// entitlements and tokens when the set of repositories changes.
+func customizeDiffCustomerAccess(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if err := customizeDiffDateRange(d); err != nil {
+		return err
+	}
+
+	if d.Id() == "" || !d.HasChange("repositories") {
+		return nil
+	}
+	for _, key := range []string{"entitlements", "tokens"} {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resourceCustomerAccess() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCustomerAccessUpdate,
+		ReadContext:   resourceCustomerAccessRead,
+		UpdateContext: resourceCustomerAccessUpdate,
+		DeleteContext: resourceCustomerAccessDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importCustomerAccess,
+		},
+
+		CustomizeDiff: customizeDiffCustomerAccess,
+
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:         schema.TypeString,
+				Description:  "The Organization to which the repositories belong.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name of the customer, used as the name of every entitlement.",
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"repositories": {
+				Type:        schema.TypeSet,
+				Description: "The repositories the customer can access. Each gets its own entitlement.",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsNotEmpty,
+				},
+			},
+			"access_private_broadcasts": {
+				Type:        schema.TypeBool,
+				Description: "If enabled, the tokens can be used for private broadcasts.",
+				Optional:    true,
+				Default:     false,
+			},
+			"is_active": {
+				Type:        schema.TypeBool,
+				Description: "If enabled, the tokens will allow downloads based on the configured limits.",
+				Optional:    true,
+				Default:     true,
+			},
+			"limit_date_range_from": {
+				Type:             schema.TypeString,
+				Description:      "The starting date/time the tokens are allowed to be used from.",
+				Optional:         true,
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: suppressEquivalentTimes,
+			},
+			"limit_date_range_to": {
+				Type:             schema.TypeString,
+				Description:      "The ending date/time the tokens are allowed to be used until.",
+				Optional:         true,
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: suppressEquivalentTimes,
+			},
+			"limit_num_clients": {
+				Type:         schema.TypeInt,
+				Description:  "The maximum number of unique clients allowed for each token.",
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"limit_num_downloads": {
+				Type:         schema.TypeInt,
+				Description:  "The maximum number of downloads allowed for each token.",
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"limit_package_query": {
+				Type:        schema.TypeString,
+				Description: "The package-based search query to restrict downloads of every token to.",
+				Optional:    true,
+			},
+			"entitlements": {
+				Type:        schema.TypeMap,
+				Description: "The slug_perm of the entitlement of each repository, keyed by repository.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"tokens": {
+				Type:        schema.TypeMap,
+				Description: "The token of each repository, keyed by repository.",
+				Computed:    true,
+				Sensitive:   true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
@@ -0,0 +1,140 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSharedCustomerAccessValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current interface{}
+		values  []interface{}
+		want    interface{}
+	}{
+		{name: "all match", current: 10, values: []interface{}{10, 10}, want: 10},
+		{name: "one differs", current: 10, values: []interface{}{10, 5}, want: 5},
+		{name: "no entitlements", current: "name:app", values: nil, want: "name:app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sharedCustomerAccessValue(tt.current, tt.values); got != tt.want {
+				t.Fatalf("sharedCustomerAccessValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceCustomerAccessRead(t *testing.T) {
+	t.Parallel()
+
+	entitlements := map[string]map[string]interface{}{
+		"/entitlements/org/repo-a/a/": {"slug_perm": "a", "name": "Acme", "token": "aaa", "is_active": true, "limit_num_downloads": 100},
+		"/entitlements/org/repo-b/b/": {"slug_perm": "b", "name": "Acme", "token": "bbb", "is_active": true, "limit_num_downloads": 50},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entitlement, ok := entitlements[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entitlement)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceCustomerAccess().Schema, map[string]interface{}{
+		"namespace":           "org",
+		"name":                "Acme",
+		"repositories":        []interface{}{"repo-a", "repo-b", "repo-c"},
+		"limit_num_downloads": 100,
+	})
+	d.SetId("customer")
+	if err := d.Set("entitlements", map[string]interface{}{"repo-a": "a", "repo-b": "b", "repo-c": "c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	diags := resourceCustomerAccessRead(context.Background(), d, testPrivilegesProviderConfig(server))
+	if diags.HasError() {
+		t.Fatalf("resourceCustomerAccessRead() error = %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Summary, "repository org/repo-c") {
+		t.Fatalf("resourceCustomerAccessRead() diagnostics = %v, want a warning for the entitlement of repo-c", diags)
+	}
+
+	repositories := expandStrings(d, "repositories")
+	sort.Strings(repositories)
+	if !reflect.DeepEqual(repositories, []string{"repo-a", "repo-b"}) {
+		t.Fatalf("repositories = %v, want the repositories whose entitlement exists", repositories)
+	}
+	if got := d.Get("tokens").(map[string]interface{}); !reflect.DeepEqual(got, map[string]interface{}{"repo-a": "aaa", "repo-b": "bbb"}) {
+		t.Fatalf("tokens = %v", got)
+	}
+	if got := d.Get("limit_num_downloads").(int); got != 50 {
+		t.Fatalf("limit_num_downloads = %d, want the drifted value 50", got)
+	}
+	if got := d.Get("name").(string); got != "Acme" {
+		t.Fatalf("name = %q, want Acme", got)
+	}
+}
+
+func TestImportCustomerAccess(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		id      string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "several repositories",
+			id:   "org.repo-a=a,repo-b=b",
+			want: map[string]interface{}{"repo-a": "a", "repo-b": "b"},
+		},
+		{name: "no entitlements", id: "org", wantErr: "invalid import ID"},
+		{name: "missing slug_perm", id: "org.repo-a=", wantErr: "invalid import ID"},
+		{name: "repeated repository", id: "org.repo-a=a,repo-a=b", wantErr: "listed more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceCustomerAccess().Schema, map[string]interface{}{})
+			d.SetId(tt.id)
+
+			_, err := importCustomerAccess(context.Background(), d, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("importCustomerAccess() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("importCustomerAccess() error = %v", err)
+			}
+			if got := d.Get("namespace").(string); got != "org" {
+				t.Fatalf("namespace = %q, want org", got)
+			}
+			if got := d.Get("entitlements").(map[string]interface{}); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("entitlements = %v, want %v", got, tt.want)
+			}
+			if d.Id() == tt.id {
+				t.Fatal("id is still the import ID")
+			}
+		})
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -32,15 +34,25 @@ func resourceEntitlementCreate(d *schema.ResourceData, m interface{}) error {
 	repository := requiredString(d, "repository")
 	accessPrivateBroadcasts := optionalBool(d, "access_private_broadcasts")
 
+	limitDateRangeFrom, limitDateRangeTo, err := expandEntitlementDateRange(d)
+	if err != nil {
+		return err
+	}
+	if duration := requiredString(d, "limit_duration"); duration != "" {
+		limitDateRangeTo, err = entitlementDurationEnd(time.Now(), duration)
+		if err != nil {
+			return err
+		}
+	}
+
 	req := pc.APIClient.EntitlementsApi.EntitlementsCreate(pc.Auth, namespace, repository)
 	req = req.Data(cloudsmith.RepositoryTokenRequest{
 		IsActive:           optionalBool(d, "is_active"),
-		LimitDateRangeFrom: nullableTime(d, "limit_date_range_from"),
-		LimitDateRangeTo:   nullableTime(d, "limit_date_range_to"),
+		LimitDateRangeFrom: limitDateRangeFrom,
+		LimitDateRangeTo:   limitDateRangeTo,
 		LimitNumClients:    nullableInt64(d, "limit_num_clients"),
 		LimitNumDownloads:  nullableInt64(d, "limit_num_downloads"),
 		LimitPackageQuery:  nullableString(d, "limit_package_query"),
-		LimitPathQuery:     nullableString(d, "limitPathQuery"),
 		Name:               requiredString(d, "name"),
 		Token:              optionalString(d, "token"),
 	})
@@ -74,6 +86,26 @@ func resourceEntitlementCreate(d *schema.ResourceData, m interface{}) error {
 }
 
 func resourceEntitlementRead(d *schema.ResourceData, m interface{}) error {
+	_, err := readEntitlement(d, m)
+	return err
+}
+
+// resourceEntitlementReadContext reads the entitlement, warning if it still
+// has a limit_path_query.
+func resourceEntitlementReadContext(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	entitlement, err := readEntitlement(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if entitlement == nil {
+		return nil
+	}
+	return limitPathQueryDiagnostics(d.Id(), entitlement.GetLimitPathQuery())
+}
+
+// readEntitlement reads the entitlement into d and returns it, or nil if it no
+// longer exists.
+func readEntitlement(d *schema.ResourceData, m interface{}) (*cloudsmith.RepositoryToken, error) {
 	pc := m.(*providerConfig)
 
 	namespace := requiredString(d, "namespace")
@@ -86,23 +118,28 @@ func resourceEntitlementRead(d *schema.ResourceData, m interface{}) error {
 	if err != nil {
 		if is404(resp) {
 			d.SetId("")
-			return nil
+			return nil, nil
 		}
 
-		return err
+		return nil, err
 	}
 
 	d.Set("access_private_broadcasts", entitlement.GetAccessPrivateBroadcasts())
+	d.Set("clients", entitlement.GetClients())
+	d.Set("downloads", entitlement.GetDownloads())
 	d.Set("is_active", entitlement.GetIsActive())
+	d.Set("last_used_at", timeToString(entitlement.GetLastUsedAt()))
 	d.Set("limit_date_range_from", timeToString(entitlement.GetLimitDateRangeFrom()))
 	d.Set("limit_date_range_to", timeToString(entitlement.GetLimitDateRangeTo()))
 	d.Set("limit_num_clients", entitlement.GetLimitNumClients())
 	d.Set("limit_num_downloads", entitlement.GetLimitNumDownloads())
 	d.Set("limit_package_query", entitlement.GetLimitPackageQuery())
-	d.Set("limit_path_query", entitlement.GetLimitPathQuery())
 	d.Set("name", entitlement.GetName())
 	d.Set("token", entitlement.GetToken())
 	d.Set("slug_perm", entitlement.GetSlugPerm())
+	d.Set("usage", entitlement.GetUsage())
+
+	setEntitlementBandwidth(pc, d, namespace, repository)
 
 	// namespace and repository are not returned from the entitlement read
 	// endpoint, so we can use the values stored in resource state. We rely on
@@ -110,7 +147,7 @@ func resourceEntitlementRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("namespace", namespace)
 	d.Set("repository", repository)
 
-	return nil
+	return entitlement, nil
 }
 
 func resourceEntitlementUpdate(d *schema.ResourceData, m interface{}) error {
@@ -121,15 +158,31 @@ func resourceEntitlementUpdate(d *schema.ResourceData, m interface{}) error {
 	desiredAccessPrivateBroadcasts := requiredBool(d, "access_private_broadcasts")
 	accessPrivateBroadcastsChanged := d.HasChange("access_private_broadcasts")
 
+	limitDateRangeFrom, limitDateRangeTo, err := expandEntitlementDateRange(d)
+	if err != nil {
+		return err
+	}
+	if duration := requiredString(d, "limit_duration"); duration != "" && d.HasChange("limit_duration") {
+		readReq := pc.APIClient.EntitlementsApi.EntitlementsRead(pc.Auth, namespace, repository, d.Id())
+		current, _, err := pc.APIClient.EntitlementsApi.EntitlementsReadExecute(readReq)
+		if err != nil {
+			return err
+		}
+		limitDateRangeTo, err = entitlementDurationEnd(current.GetCreatedAt(), duration)
+		if err != nil {
+			return err
+		}
+	}
+
 	req := pc.APIClient.EntitlementsApi.EntitlementsPartialUpdate(pc.Auth, namespace, repository, d.Id())
 	req = req.Data(cloudsmith.RepositoryTokenRequestPatch{
 		IsActive:           optionalBool(d, "is_active"),
-		LimitDateRangeFrom: nullableTime(d, "limit_date_range_from"),
-		LimitDateRangeTo:   nullableTime(d, "limit_date_range_to"),
+		LimitDateRangeFrom: limitDateRangeFrom,
+		LimitDateRangeTo:   limitDateRangeTo,
 		LimitNumClients:    nullableInt64(d, "limit_num_clients"),
 		LimitNumDownloads:  nullableInt64(d, "limit_num_downloads"),
 		LimitPackageQuery:  nullableString(d, "limit_package_query"),
-		LimitPathQuery:     nullableString(d, "limit_path_query"),
+		LimitPathQuery:     clearedLimitPathQuery(d),
 		Name:               optionalString(d, "name"),
 		Token:              optionalString(d, "token"),
 	})
@@ -155,15 +208,302 @@ func resourceEntitlementUpdate(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
+	// The entitlement holding the previous token stops working when its grace
+	// period ends, and is then no longer needed. A rotation replaces it anyway.
+	rotate := d.HasChange("rotation_trigger") && requiredString(d, "rotation_trigger") != ""
+	if !rotate && previousEntitlementTokenExpired(d, time.Now()) {
+		if err := deletePreviousEntitlementToken(pc, d, namespace, repository); err != nil {
+			return err
+		}
+	}
+
+	if rotate {
+		previousToken, _ := d.GetChange("token")
+		if err := rotateEntitlementToken(pc, d, namespace, repository, previousToken.(string)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("reset_usage_trigger") && requiredString(d, "reset_usage_trigger") != "" {
+		if err := resetEntitlementUsage(pc, namespace, repository, d.Id()); err != nil {
+			return err
+		}
+	}
+
 	return resourceEntitlementRead(d, m)
 }
 
+// refreshEntitlementToken replaces the token of an entitlement with a new
+// random one, which it returns. The old token stops working immediately.
+func refreshEntitlementToken(pc *providerConfig, namespace, repository, entitlement string) (string, error) {
+	req := pc.APIClient.EntitlementsApi.EntitlementsRefresh(pc.Auth, namespace, repository, entitlement)
+	req = req.Data(cloudsmith.RepositoryTokenRefreshRequest{})
+	req = req.ShowTokens(true)
+
+	refreshed, _, err := pc.APIClient.EntitlementsApi.EntitlementsRefreshExecute(req)
+	if err != nil {
+		return "", err
+	}
+	return refreshed.GetToken(), nil
+}
+
+// deletePreviousEntitlementToken deletes the entitlement holding the previous
+// token, if there is one. Its slug_perm is read from state, as the plan clears
+// it when the previous token is replaced or has expired.
+func deletePreviousEntitlementToken(pc *providerConfig, d *schema.ResourceData, namespace, repository string) error {
+	state, _ := d.GetChange("previous_token_slug_perm")
+	slugPerm := state.(string)
+	if slugPerm == "" {
+		return nil
+	}
+
+	req := pc.APIClient.EntitlementsApi.EntitlementsDelete(pc.Auth, namespace, repository, slugPerm)
+	if resp, err := pc.APIClient.EntitlementsApi.EntitlementsDeleteExecute(req); err != nil && !is404(resp) {
+		return fmt.Errorf("error deleting entitlement (%s) holding the previous token: %w", slugPerm, err)
+	}
+
+	d.Set("previous_token", "")
+	d.Set("previous_token_expires_at", "")
+	d.Set("previous_token_slug_perm", "")
+	return nil
+}
+
+// entitlementChange is implemented by both schema.ResourceDiff and
+// schema.ResourceData, so the previous token can be checked when planning and
+// when applying.
+type entitlementChange interface {
+	GetChange(key string) (interface{}, interface{})
+}
+
+// previousEntitlementTokenKeys are the attributes describing the previous
+// token.
+var previousEntitlementTokenKeys = []string{"previous_token", "previous_token_expires_at", "previous_token_slug_perm"}
+
+// previousEntitlementTokenExpired reports whether the grace period of the
+// previous token in state has ended.
+func previousEntitlementTokenExpired(d entitlementChange, now time.Time) bool {
+	state, _ := d.GetChange("previous_token_expires_at")
+	expiresAt := state.(string)
+	return expiresAt != "" && !now.Before(stringToTime(expiresAt))
+}
+
+// expandPreviousEntitlementToken returns the request for the entitlement that
+// keeps previousToken valid until expiresAt, with the same limits as the
+// entitlement. It stops working at expiresAt, or earlier if the entitlement
+// itself does.
+func expandPreviousEntitlementToken(d *schema.ResourceData, previousToken string, expiresAt time.Time) (cloudsmith.RepositoryTokenRequest, error) {
+	limitDateRangeFrom, limitDateRangeTo, err := expandEntitlementDateRange(d)
+	if err != nil {
+		return cloudsmith.RepositoryTokenRequest{}, err
+	}
+	if to := limitDateRangeTo.Get(); to == nil || expiresAt.Before(*to) {
+		limitDateRangeTo = *cloudsmith.NewNullableTime(&expiresAt)
+	}
+
+	return cloudsmith.RepositoryTokenRequest{
+		IsActive:           cloudsmith.PtrBool(true),
+		LimitDateRangeFrom: limitDateRangeFrom,
+		LimitDateRangeTo:   limitDateRangeTo,
+		LimitNumClients:    nullableInt64(d, "limit_num_clients"),
+		LimitNumDownloads:  nullableInt64(d, "limit_num_downloads"),
+		LimitPackageQuery:  nullableString(d, "limit_package_query"),
+		Name:               fmt.Sprintf("%s (previous token)", requiredString(d, "name")),
+		Token:              cloudsmith.PtrString(previousToken),
+	}, nil
+}
+
+// rotateEntitlementToken refreshes the token of the entitlement. If
+// previous_token_grace_period is set, previousToken is kept valid until the
+// grace period ends by a separate entitlement with the same limits, replacing
+// any such entitlement from an earlier rotation.
+func rotateEntitlementToken(pc *providerConfig, d *schema.ResourceData, namespace, repository, previousToken string) error {
+	token, err := refreshEntitlementToken(pc, namespace, repository, d.Id())
+	if err != nil {
+		return fmt.Errorf("error rotating token of entitlement (%s): %w", d.Id(), err)
+	}
+	d.Set("token", token)
+
+	if err := deletePreviousEntitlementToken(pc, d, namespace, repository); err != nil {
+		return err
+	}
+
+	gracePeriod := requiredString(d, "previous_token_grace_period")
+	if gracePeriod == "" || previousToken == "" {
+		return nil
+	}
+	period, err := time.ParseDuration(gracePeriod)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(period).UTC()
+
+	data, err := expandPreviousEntitlementToken(d, previousToken, expiresAt)
+	if err != nil {
+		return err
+	}
+	req := pc.APIClient.EntitlementsApi.EntitlementsCreate(pc.Auth, namespace, repository)
+	req = req.Data(data)
+	req = req.ShowTokens(true)
+
+	previous, _, err := pc.APIClient.EntitlementsApi.EntitlementsCreateExecute(req)
+	if err != nil {
+		return fmt.Errorf("error creating entitlement for the previous token of entitlement (%s): %w", d.Id(), err)
+	}
+
+	d.Set("previous_token", previousToken)
+	d.Set("previous_token_expires_at", timeToString(expiresAt))
+	d.Set("previous_token_slug_perm", previous.GetSlugPerm())
+
+	if requiredBool(d, "access_private_broadcasts") {
+		if err := setEntitlementPrivateBroadcasts(pc, namespace, repository, previous.GetSlugPerm(), true); err != nil {
+			return fmt.Errorf("error allowing private broadcasts for the previous token of entitlement (%s): %w", d.Id(), err)
+		}
+	}
+	return nil
+}
+
+// expandEntitlementDateRange returns limit_date_range_from and
+// limit_date_range_to for an entitlement request.
+func expandEntitlementDateRange(d *schema.ResourceData) (from, to cloudsmith.NullableTime, err error) {
+	if from, err = nullableTime(d, "limit_date_range_from"); err != nil {
+		return from, to, err
+	}
+	to, err = nullableTime(d, "limit_date_range_to")
+	return from, to, err
+}
+
+// entitlementDurationEnd returns the end of a limit_duration starting at start.
+func entitlementDurationEnd(start time.Time, duration string) (cloudsmith.NullableTime, error) {
+	period, err := time.ParseDuration(duration)
+	if err != nil {
+		return cloudsmith.NullableTime{}, err
+	}
+	end := start.Add(period).UTC()
+	return *cloudsmith.NewNullableTime(&end), nil
+}
+
+// suppressLimitDateRangeTo suppresses diffs between equivalent timestamps, and
+// the diff from the end date set by limit_duration to an unset
+// limit_date_range_to.
+func suppressLimitDateRangeTo(k, old, new string, d *schema.ResourceData) bool {
+	if new == "" && d.Get("limit_duration").(string) != "" {
+		return true
+	}
+	return suppressEquivalentTimes(k, old, new, d)
+}
+
+// plannedTime returns the planned timestamp of key, if it is set and known.
+// Malformed timestamps are left to the attribute's validation.
+func plannedTime(d *schema.ResourceDiff, key string) (time.Time, bool) {
+	if !d.NewValueKnown(key) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, d.Get(key).(string))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// checkDateRange checks that from is before to and, if to changes, that it
+// isn't already past, as the token would never allow downloads. An expired
+// range that doesn't change is left alone so that it doesn't block plans.
+func checkDateRange(from, to *time.Time, toChanged bool, now time.Time) error {
+	if from != nil && to != nil && !from.Before(*to) {
+		return fmt.Errorf(
+			"limit_date_range_from (%s) must be before limit_date_range_to (%s)",
+			from.Format(time.RFC3339), to.Format(time.RFC3339),
+		)
+	}
+	if to != nil && toChanged && !to.After(now) {
+		return fmt.Errorf("limit_date_range_to (%s) is in the past, so the token would never allow downloads", to.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// customizeDiffDateRange checks the planned limit_date_range_from and
+// limit_date_range_to.
+func customizeDiffDateRange(d *schema.ResourceDiff) error {
+	var from, to *time.Time
+	if t, ok := plannedTime(d, "limit_date_range_from"); ok {
+		from = &t
+	}
+	if t, ok := plannedTime(d, "limit_date_range_to"); ok {
+		to = &t
+	}
+	return checkDateRange(from, to, d.HasChange("limit_date_range_to"), time.Now())
+}
+
+// customizeDiffEntitlementDateRange checks the date range of an entitlement,
+// which on create ends limit_duration from now if that is set. On update the
+// end depends on when the entitlement was created, so only limit_date_range_to
+// is checked.
+func customizeDiffEntitlementDateRange(d *schema.ResourceDiff) error {
+	duration, ok := d.GetOk("limit_duration")
+	if !ok || !d.NewValueKnown("limit_duration") {
+		return customizeDiffDateRange(d)
+	}
+	if d.Id() != "" {
+		return nil
+	}
+
+	period, err := time.ParseDuration(duration.(string))
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	to := now.Add(period)
+	if from, ok := plannedTime(d, "limit_date_range_from"); ok {
+		return checkDateRange(&from, &to, true, now)
+	}
+	return nil
+}
+
+// customizeDiffEntitlement checks the date range, plans a new token when
+// rotation_trigger changes, the deletion of the previous token once its grace
+// period has ended, and new usage counters when reset_usage_trigger changes.
+func customizeDiffEntitlement(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if err := customizeDiffEntitlementDateRange(d); err != nil {
+		return err
+	}
+
+	var keys []string
+	if triggerChanged(d, "rotation_trigger") {
+		keys = append(keys, "token")
+		keys = append(keys, previousEntitlementTokenKeys...)
+	} else if d.Id() != "" && previousEntitlementTokenExpired(d, time.Now()) {
+		keys = append(keys, previousEntitlementTokenKeys...)
+	}
+	if triggerChanged(d, "reset_usage_trigger") {
+		keys = append(keys, entitlementUsageKeys...)
+	}
+
+	for _, key := range keys {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateDuration(v interface{}, k string) (warnings []string, errors []error) {
+	duration, err := time.ParseDuration(v.(string))
+	if err != nil || duration <= 0 {
+		errors = append(errors, fmt.Errorf("%q must be a positive duration such as \"24h\", got: %s", k, v))
+	}
+	return
+}
+
 func resourceEntitlementDelete(d *schema.ResourceData, m interface{}) error {
 	pc := m.(*providerConfig)
 
 	namespace := requiredString(d, "namespace")
 	repository := requiredString(d, "repository")
 
+	if err := deletePreviousEntitlementToken(pc, d, namespace, repository); err != nil {
+		return err
+	}
+
 	req := pc.APIClient.EntitlementsApi.EntitlementsDelete(pc.Auth, namespace, repository, d.Id())
 	_, err := pc.APIClient.EntitlementsApi.EntitlementsDeleteExecute(req)
 	if err != nil {
@@ -191,15 +531,26 @@ func setEntitlementPrivateBroadcasts(pc *providerConfig, namespace, repository,
 //nolint:funlen
 func resourceEntitlement() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceEntitlementCreate,
-		Read:   resourceEntitlementRead,
-		Update: resourceEntitlementUpdate,
-		Delete: resourceEntitlementDelete,
+		Create:      resourceEntitlementCreate,
+		ReadContext: resourceEntitlementReadContext,
+		Update:      resourceEntitlementUpdate,
+		Delete:      resourceEntitlementDelete,
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceEntitlementV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceEntitlementStateUpgradeV0,
+			},
+		},
 
 		Importer: &schema.ResourceImporter{
 			StateContext: importEntitlement,
 		},
 
+		CustomizeDiff: customizeDiffEntitlement,
+
 		Schema: map[string]*schema.Schema{
 			"access_private_broadcasts": {
 				Type:        schema.TypeBool,
@@ -207,23 +558,59 @@ func resourceEntitlement() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
+			"bandwidth_used": {
+				Type:        schema.TypeFloat,
+				Description: "The bandwidth used by the token, in `bandwidth_used_unit`.",
+				Computed:    true,
+			},
+			"bandwidth_used_unit": {
+				Type:        schema.TypeString,
+				Description: "The unit of `bandwidth_used`.",
+				Computed:    true,
+			},
+			"clients": {
+				Type:        schema.TypeInt,
+				Description: "The number of unique clients that have used the token since its usage was last reset.",
+				Computed:    true,
+			},
+			"downloads": {
+				Type:        schema.TypeInt,
+				Description: "The number of downloads made with the token since its usage was last reset.",
+				Computed:    true,
+			},
 			"is_active": {
 				Type:        schema.TypeBool,
 				Description: "If enabled, the token will allow downloads based on configured restrictions (if any).",
 				Optional:    true,
 				Computed:    true,
 			},
+			"last_used_at": {
+				Type:        schema.TypeString,
+				Description: "ISO 8601 timestamp at which the token was last used to download a package. Empty if it has never been used.",
+				Computed:    true,
+			},
 			"limit_date_range_from": {
-				Type:         schema.TypeString,
-				Description:  "The starting date/time the token is allowed to be used from.",
-				Optional:     true,
-				ValidateFunc: validation.IsRFC3339Time,
+				Type:             schema.TypeString,
+				Description:      "The starting date/time the token is allowed to be used from.",
+				Optional:         true,
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: suppressEquivalentTimes,
 			},
 			"limit_date_range_to": {
-				Type:         schema.TypeString,
-				Description:  "The ending date/time the token is allowed to be used until.",
+				Type:             schema.TypeString,
+				Description:      "The ending date/time the token is allowed to be used until.",
+				Optional:         true,
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: suppressLimitDateRangeTo,
+				ConflictsWith:    []string{"limit_duration"},
+			},
+			"limit_duration": {
+				Type: schema.TypeString,
+				Description: "How long the token is allowed to be used for after the entitlement is " +
+					"created, as a duration such as `720h`. Sets limit_date_range_to to the end of " +
+					"that period.",
 				Optional:     true,
-				ValidateFunc: validation.IsRFC3339Time,
+				ValidateFunc: validateDuration,
 			},
 			"limit_num_clients": {
 				Type: schema.TypeInt,
@@ -249,16 +636,6 @@ func resourceEntitlement() *schema.Resource {
 					"grouping. This will still allow access to non-package files, such as metadata.",
 				Optional: true,
 			},
-			"limit_path_query": {
-				Type: schema.TypeString,
-				Description: "The path-based search query to apply to restrict downloads to. This " +
-					"supports boolean logic operators such as OR/AND/NOT and parentheses for " +
-					"grouping. The path evaluated does not include the domain name, the namespace, " +
-					"the entitlement code used, the package format, etc. and it always starts with " +
-					"a forward slash.",
-				Optional:   true,
-				Deprecated: "`limit_path_query` is deprecated and should not be used in new configurations, please use `limit_package_query` instead.",
-			},
 			"name": {
 				Type:         schema.TypeString,
 				Description:  "A descriptive name for the entitlement.",
@@ -279,6 +656,44 @@ func resourceEntitlement() *schema.Resource {
 				ForceNew:     true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
+			"previous_token": {
+				Type:        schema.TypeString,
+				Description: "The token replaced by the last rotation, while it is kept valid by `previous_token_grace_period`.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"previous_token_expires_at": {
+				Type:        schema.TypeString,
+				Description: "ISO 8601 timestamp at which `previous_token` stops working.",
+				Computed:    true,
+			},
+			"previous_token_grace_period": {
+				Type: schema.TypeString,
+				Description: "How long the previous token keeps working after a rotation, as a duration " +
+					"such as `24h`. The previous token is kept valid by a separate entitlement, which " +
+					"is deleted on the next rotation, by the first apply after the grace period ends, " +
+					"or when this entitlement is destroyed.",
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+			"previous_token_slug_perm": {
+				Type:        schema.TypeString,
+				Description: "The permanent slug identifier of the entitlement that keeps `previous_token` valid.",
+				Computed:    true,
+			},
+			"reset_usage_trigger": {
+				Type: schema.TypeString,
+				Description: "An arbitrary value which, when changed, resets the usage counters of the " +
+					"token (downloads, clients and bandwidth) to zero.",
+				Optional: true,
+			},
+			"rotation_trigger": {
+				Type: schema.TypeString,
+				Description: "An arbitrary value which, when changed, rotates the token in place, for " +
+					"example the `id` of a `time_rotating` resource.",
+				Optional:      true,
+				ConflictsWith: []string{"token"},
+			},
 			"slug_perm": {
 				Type:        schema.TypeString,
 				Description: "The permanent slug identifier for the entitlement.",
@@ -292,6 +707,11 @@ func resourceEntitlement() *schema.Resource {
 				Sensitive:    true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
+			"usage": {
+				Type:        schema.TypeString,
+				Description: "A summary of the usage of the token against its limits.",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -0,0 +1,146 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceEntitlementV0 is the schema of cloudsmith_entitlement before
+// limit_path_query was removed.
+func resourceEntitlementV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"access_private_broadcasts": {Type: schema.TypeBool, Optional: true, Computed: true},
+			"is_active":                 {Type: schema.TypeBool, Optional: true, Computed: true},
+			"limit_date_range_from":     {Type: schema.TypeString, Optional: true},
+			"limit_date_range_to":       {Type: schema.TypeString, Optional: true},
+			"limit_num_clients":         {Type: schema.TypeInt, Optional: true, Computed: true},
+			"limit_num_downloads":       {Type: schema.TypeInt, Optional: true, Computed: true},
+			"limit_package_query":       {Type: schema.TypeString, Optional: true},
+			"limit_path_query":          {Type: schema.TypeString, Optional: true},
+			"name":                      {Type: schema.TypeString, Required: true},
+			"namespace":                 {Type: schema.TypeString, Required: true, ForceNew: true},
+			"repository":                {Type: schema.TypeString, Required: true, ForceNew: true},
+			"slug_perm":                 {Type: schema.TypeString, Computed: true},
+			"token":                     {Type: schema.TypeString, Optional: true, Computed: true, Sensitive: true},
+		},
+	}
+}
+
+// translateLimitPathQueryTerm translates a single path glob into a package
+// query. Only globs that match on the filename alone, with every directory
+// being a wildcard, have an equivalent.
+func translateLimitPathQueryTerm(term string) (string, bool) {
+	if !strings.HasPrefix(term, "/") {
+		return "", false
+	}
+
+	segments := strings.Split(strings.TrimPrefix(term, "/"), "/")
+	filename := segments[len(segments)-1]
+	if filename == "" {
+		return "", false
+	}
+	for _, dir := range segments[:len(segments)-1] {
+		if dir != "*" && dir != "**" {
+			return "", false
+		}
+	}
+	return "filename:" + filename, true
+}
+
+// translateLimitPathQuery translates a limit_path_query into the equivalent
+// limit_package_query, keeping boolean operators and parentheses. It reports
+// false if any term has no equivalent.
+func translateLimitPathQuery(pathQuery string) (string, bool) {
+	fields := strings.Fields(pathQuery)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	translated := make([]string, len(fields))
+	for i, field := range fields {
+		switch strings.ToUpper(field) {
+		case "AND", "OR", "NOT":
+			translated[i] = strings.ToUpper(field)
+			continue
+		}
+
+		term := strings.TrimLeft(field, "(")
+		open := field[:len(field)-len(term)]
+		inner := strings.TrimRight(term, ")")
+		closing := term[len(inner):]
+		if inner == "" {
+			translated[i] = field
+			continue
+		}
+
+		query, ok := translateLimitPathQueryTerm(inner)
+		if !ok {
+			return "", false
+		}
+		translated[i] = open + query + closing
+	}
+	return strings.Join(translated, " "), true
+}
+
+// resourceEntitlementStateUpgradeV0 removes limit_path_query. If
+// limit_package_query is empty, the path query is moved into it where it has
+// an equivalent package query, and a warning is logged where it hasn't.
+func resourceEntitlementStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	pathQuery, _ := rawState["limit_path_query"].(string)
+	delete(rawState, "limit_path_query")
+	if pathQuery == "" {
+		return rawState, nil
+	}
+
+	if packageQuery, _ := rawState["limit_package_query"].(string); packageQuery != "" {
+		log.Printf("[WARN] entitlement (%v): dropping limit_path_query %q from state, as limit_package_query is already set", rawState["id"], pathQuery)
+		return rawState, nil
+	}
+
+	packageQuery, ok := translateLimitPathQuery(pathQuery)
+	if !ok {
+		log.Printf("[WARN] entitlement (%v): limit_path_query %q has no equivalent limit_package_query, dropping it from state", rawState["id"], pathQuery)
+		return rawState, nil
+	}
+	rawState["limit_package_query"] = packageQuery
+	return rawState, nil
+}
+
+// limitPathQueryDiagnostics warns about an entitlement that still has a
+// limit_path_query, which can no longer be managed, suggesting the equivalent
+// limit_package_query where there is one.
+func limitPathQueryDiagnostics(id, pathQuery string) diag.Diagnostics {
+	if pathQuery == "" {
+		return nil
+	}
+
+	detail := "limit_path_query is no longer supported. Setting limit_package_query removes the path query from the entitlement, "
+	if packageQuery, ok := translateLimitPathQuery(pathQuery); ok {
+		detail += fmt.Sprintf("so set limit_package_query = %q to keep the same restriction.", packageQuery)
+	} else {
+		detail += "but this path query has no direct equivalent, so write a limit_package_query that restricts downloads in the same way."
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Entitlement (%s) restricts downloads with limit_path_query %q", id, pathQuery),
+		Detail:   detail,
+	}}
+}
+
+// clearedLimitPathQuery returns the limit_path_query to send with an update:
+// null to remove any path query once limit_package_query is set, and
+// otherwise unset, leaving the entitlement's path query alone.
+func clearedLimitPathQuery(d *schema.ResourceData) cloudsmith.NullableString {
+	if optionalString(d, "limit_package_query") == nil {
+		return cloudsmith.NullableString{}
+	}
+	return *cloudsmith.NewNullableString(nil)
+}
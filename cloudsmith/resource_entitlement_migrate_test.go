@@ -0,0 +1,115 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestTranslateLimitPathQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pathQuery string
+		want      string
+		wantOK    bool
+	}{
+		{pathQuery: "/*.deb", want: "filename:*.deb", wantOK: true},
+		{pathQuery: "/*/*/app-*.tar.gz", want: "filename:app-*.tar.gz", wantOK: true},
+		{pathQuery: "/*.deb or /*.rpm", want: "filename:*.deb OR filename:*.rpm", wantOK: true},
+		{pathQuery: "(/*.deb OR /*.rpm) AND NOT /**/*-dev*", want: "(filename:*.deb OR filename:*.rpm) AND NOT filename:*-dev*", wantOK: true},
+		{pathQuery: "/my-package/*", wantOK: false},
+		{pathQuery: "/*.deb OR /internal/*", wantOK: false},
+		{pathQuery: "*.deb", wantOK: false},
+		{pathQuery: "/*/", wantOK: false},
+		{pathQuery: "  ", wantOK: false},
+	}
+
+	for _, tc := range tests {
+		got, ok := translateLimitPathQuery(tc.pathQuery)
+		if ok != tc.wantOK || got != tc.want {
+			t.Errorf("translateLimitPathQuery(%q) = %q, %v, want %q, %v", tc.pathQuery, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestResourceEntitlementStateUpgradeV0(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rawState map[string]interface{}
+		want     map[string]interface{}
+	}{
+		{
+			name:     "path query",
+			rawState: map[string]interface{}{"id": "abc", "limit_path_query": "/*.deb", "limit_package_query": "tag:stable"},
+			want:     map[string]interface{}{"id": "abc", "limit_package_query": "tag:stable"},
+		},
+		{
+			name:     "translated path query",
+			rawState: map[string]interface{}{"id": "abc", "limit_path_query": "/*.deb OR /*.rpm", "limit_package_query": ""},
+			want:     map[string]interface{}{"id": "abc", "limit_package_query": "filename:*.deb OR filename:*.rpm"},
+		},
+		{
+			name:     "untranslatable path query",
+			rawState: map[string]interface{}{"id": "abc", "limit_path_query": "/internal/*", "limit_package_query": ""},
+			want:     map[string]interface{}{"id": "abc", "limit_package_query": ""},
+		},
+		{
+			name:     "no path query",
+			rawState: map[string]interface{}{"id": "abc", "limit_path_query": "", "limit_package_query": "tag:stable"},
+			want:     map[string]interface{}{"id": "abc", "limit_package_query": "tag:stable"},
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := resourceEntitlementStateUpgradeV0(context.Background(), tc.rawState, nil)
+		if err != nil {
+			t.Fatalf("%s: resourceEntitlementStateUpgradeV0() error = %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: resourceEntitlementStateUpgradeV0() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestResourceEntitlementStateUpgraders(t *testing.T) {
+	t.Parallel()
+
+	r := resourceEntitlement()
+	if r.SchemaVersion != 1 || len(r.StateUpgraders) != 1 || r.StateUpgraders[0].Version != 0 {
+		t.Fatalf("unexpected schema version %d with upgraders %v", r.SchemaVersion, r.StateUpgraders)
+	}
+	if _, ok := r.Schema["limit_path_query"]; ok {
+		t.Fatal("limit_path_query is still in the schema")
+	}
+	if err := r.InternalValidate(nil, true); err != nil {
+		t.Fatalf("InternalValidate() error = %v", err)
+	}
+}
+
+func TestLimitPathQueryDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	if diags := limitPathQueryDiagnostics("abc", ""); diags != nil {
+		t.Fatalf("expected no diagnostics without a path query, got %v", diags)
+	}
+
+	diags := limitPathQueryDiagnostics("abc", "/*.deb")
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Detail, `limit_package_query = "filename:*.deb"`) {
+		t.Fatalf("expected the warning to suggest the equivalent package query, got %q", diags[0].Detail)
+	}
+
+	diags = limitPathQueryDiagnostics("abc", "/internal/*")
+	if len(diags) != 1 || !strings.Contains(diags[0].Detail, "no direct equivalent") {
+		t.Fatalf("expected a warning without a suggestion, got %v", diags)
+	}
+}
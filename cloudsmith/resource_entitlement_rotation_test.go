@@ -0,0 +1,251 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestRotateEntitlementToken(t *testing.T) {
+	t.Parallel()
+
+	var created map[string]interface{}
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/entitlements/org/repo/abc/refresh/":
+			_, _ = w.Write([]byte(`{"slug_perm":"abc","token":"new-token"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/entitlements/org/repo/old-grace/":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/entitlements/org/repo/":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("failed to decode request body: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"slug_perm":"grace","token":"old-token"}`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/entitlements/org/repo/grace/"):
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	// The plan makes previous_token_slug_perm unknown, so the entitlement
+	// from the earlier rotation is found through the state.
+	d := testEntitlementData(t, map[string]interface{}{
+		"name":                        "Deploy",
+		"namespace":                   "org",
+		"repository":                  "repo",
+		"limit_package_query":         "name:app",
+		"limit_num_clients":           5,
+		"limit_num_downloads":         100,
+		"limit_date_range_from":       "2026-01-01T00:00:00Z",
+		"limit_date_range_to":         "2099-01-01T00:00:00Z",
+		"access_private_broadcasts":   true,
+		"previous_token_grace_period": "24h",
+		"rotation_trigger":            "2",
+	}, map[string]string{
+		"previous_token_slug_perm": "old-grace",
+		"rotation_trigger":         "1",
+	})
+
+	before := time.Now()
+	if err := rotateEntitlementToken(testPrivilegesProviderConfig(server), d, "org", "repo", "old-token"); err != nil {
+		t.Fatalf("rotateEntitlementToken() error = %v", err)
+	}
+
+	want := []string{
+		"POST /entitlements/org/repo/abc/refresh/",
+		"DELETE /entitlements/org/repo/old-grace/",
+		"POST /entitlements/org/repo/",
+	}
+	// The previous token entitlement is then allowed private broadcasts.
+	if len(requests) != len(want)+1 || !strings.HasPrefix(requests[len(want)], "POST /entitlements/org/repo/grace/") {
+		t.Fatalf("requests = %v, want %v followed by allowing private broadcasts", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Fatalf("requests = %v, want %v", requests, want)
+		}
+	}
+
+	if created["token"] != "old-token" || created["name"] != "Deploy (previous token)" || created["limit_package_query"] != "name:app" {
+		t.Fatalf("unexpected previous token entitlement %v", created)
+	}
+	if created["limit_num_clients"] != float64(5) || created["limit_num_downloads"] != float64(100) || created["limit_date_range_from"] != "2026-01-01T00:00:00Z" {
+		t.Fatalf("previous token entitlement %v doesn't have the entitlement's limits", created)
+	}
+	createdTo, err := time.Parse(time.RFC3339, created["limit_date_range_to"].(string))
+	if err != nil || createdTo.After(time.Now().Add(24*time.Hour)) {
+		t.Fatalf("previous token entitlement limit_date_range_to = %v, want the end of the grace period", created["limit_date_range_to"])
+	}
+
+	if got := d.Get("token").(string); got != "new-token" {
+		t.Fatalf("token = %q, want new-token", got)
+	}
+	if got := d.Get("previous_token").(string); got != "old-token" {
+		t.Fatalf("previous_token = %q, want old-token", got)
+	}
+	if got := d.Get("previous_token_slug_perm").(string); got != "grace" {
+		t.Fatalf("previous_token_slug_perm = %q, want grace", got)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, d.Get("previous_token_expires_at").(string))
+	if err != nil {
+		t.Fatalf("failed to parse previous_token_expires_at: %v", err)
+	}
+	if expiresAt.Before(before.Add(24*time.Hour).Truncate(time.Second)) || expiresAt.After(time.Now().Add(24*time.Hour)) {
+		t.Fatalf("previous_token_expires_at = %s, want 24h from now", expiresAt)
+	}
+}
+
+func TestRotateEntitlementTokenWithoutGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"slug_perm":"abc","token":"new-token"}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceEntitlement().Schema, map[string]interface{}{
+		"name":       "Deploy",
+		"namespace":  "org",
+		"repository": "repo",
+	})
+	d.SetId("abc")
+
+	if err := rotateEntitlementToken(testPrivilegesProviderConfig(server), d, "org", "repo", "old-token"); err != nil {
+		t.Fatalf("rotateEntitlementToken() error = %v", err)
+	}
+
+	if len(requests) != 1 || requests[0] != "POST /entitlements/org/repo/abc/refresh/" {
+		t.Fatalf("requests = %v, want only the refresh", requests)
+	}
+	if got := d.Get("token").(string); got != "new-token" {
+		t.Fatalf("token = %q, want new-token", got)
+	}
+	if got := d.Get("previous_token").(string); got != "" {
+		t.Fatalf("previous_token = %q, want it empty", got)
+	}
+}
+
+func TestExpandPreviousEntitlementTokenEndsWithEntitlement(t *testing.T) {
+	t.Parallel()
+
+	d := schema.TestResourceDataRaw(t, resourceEntitlement().Schema, map[string]interface{}{
+		"name":                "Deploy",
+		"namespace":           "org",
+		"repository":          "repo",
+		"limit_date_range_to": "2026-01-02T00:00:00Z",
+	})
+
+	data, err := expandPreviousEntitlementToken(d, "old-token", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expandPreviousEntitlementToken() error = %v", err)
+	}
+	if to := data.LimitDateRangeTo.Get(); to == nil || !to.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("limit_date_range_to = %v, want the entitlement's own end", to)
+	}
+}
+
+func TestPreviousEntitlementTokenExpired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	for expiresAt, want := range map[string]bool{
+		"":                     false,
+		"2026-06-02T00:00:00Z": false,
+		"2026-05-31T00:00:00Z": true,
+	} {
+		d := resourceEntitlement().Data(&terraform.InstanceState{
+			ID:         "abc",
+			Attributes: map[string]string{"previous_token_expires_at": expiresAt},
+		})
+		if got := previousEntitlementTokenExpired(d, now); got != want {
+			t.Errorf("previousEntitlementTokenExpired(%q) = %v, want %v", expiresAt, got, want)
+		}
+	}
+}
+
+func TestCustomizeDiffEntitlementExpiredPreviousToken(t *testing.T) {
+	t.Parallel()
+
+	config := map[string]interface{}{
+		"name":       "Deploy",
+		"namespace":  "org",
+		"repository": "repo",
+	}
+	for name, tc := range map[string]struct {
+		expiresAt  string
+		wantUpdate bool
+	}{
+		"expired":        {expiresAt: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339), wantUpdate: true},
+		"grace period":   {expiresAt: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)},
+		"no prior token": {},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			state := &terraform.InstanceState{
+				ID: "abc",
+				Attributes: map[string]string{
+					"name":       "Deploy",
+					"namespace":  "org",
+					"repository": "repo",
+				},
+			}
+			if tc.expiresAt != "" {
+				state.Attributes["previous_token"] = "old-token"
+				state.Attributes["previous_token_expires_at"] = tc.expiresAt
+				state.Attributes["previous_token_slug_perm"] = "grace"
+			}
+
+			diff, err := resourceEntitlement().Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var attr *terraform.ResourceAttrDiff
+			if diff != nil {
+				attr = diff.Attributes["previous_token_slug_perm"]
+			}
+			if planned := attr != nil && attr.Old == "grace" && attr.NewComputed; planned != tc.wantUpdate {
+				t.Fatalf("previous_token_slug_perm planned as %+v, want an update %v", attr, tc.wantUpdate)
+			}
+		})
+	}
+}
+
+// testEntitlementData returns the resource data for applying config to the
+// entitlement abc with the given state.
+func testEntitlementData(t *testing.T, config map[string]interface{}, state map[string]string) *schema.ResourceData {
+	t.Helper()
+
+	r := resourceEntitlement()
+	instance := &terraform.InstanceState{ID: "abc", Attributes: state}
+	diff, err := r.Diff(context.Background(), instance, terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	d, err := schema.InternalMap(r.Schema).Data(instance, diff)
+	if err != nil {
+		t.Fatalf("Data() error = %v", err)
+	}
+	return d
+}
@@ -0,0 +1,294 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// listEntitlementTokens returns every entitlement of a repository, including
+// the token values.
+func listEntitlementTokens(pc *providerConfig, namespace, repository string) ([]cloudsmith.RepositoryToken, error) {
+	tokens, exists, err := listEntitlementTokensIfExists(pc, namespace, repository)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("repository %s/%s not found", namespace, repository)
+	}
+	return tokens, nil
+}
+
+// listEntitlementTokensIfExists returns every entitlement of a repository,
+// including the token values, and whether the repository exists.
+func listEntitlementTokensIfExists(pc *providerConfig, namespace, repository string) ([]cloudsmith.RepositoryToken, bool, error) {
+	exists := true
+	exec := func(page, ps int64) ([]cloudsmith.RepositoryToken, *http.Response, error) {
+		req := pc.APIClient.EntitlementsApi.EntitlementsList(pc.Auth, namespace, repository).
+			Page(page).
+			PageSize(ps).
+			ShowTokens(true)
+		tokens, resp, err := pc.APIClient.EntitlementsApi.EntitlementsListExecute(req)
+		if err != nil && is404(resp) {
+			exists = false
+			return nil, resp, nil
+		}
+		return tokens, resp, err
+	}
+	tokens, err := PaginateAllHTTP[cloudsmith.RepositoryToken](exec, PaginationOptions{})
+	if err != nil {
+		return nil, false, fmt.Errorf("error listing entitlements of repository %s/%s: %w", namespace, repository, formatAPIError(err))
+	}
+	return tokens, exists, nil
+}
+
+// diffEntitlementTokens returns the entitlements in after whose token isn't
+// in before, and those in before whose token isn't in after, each ordered by
+// name. Entitlements are matched by token because synchronized copies get new
+// slugs.
+func diffEntitlementTokens(before, after []cloudsmith.RepositoryToken) (added, removed []cloudsmith.RepositoryToken) {
+	missingFrom := func(tokens, other []cloudsmith.RepositoryToken) []cloudsmith.RepositoryToken {
+		present := make(map[string]bool, len(other))
+		for _, t := range other {
+			present[t.GetToken()] = true
+		}
+		var missing []cloudsmith.RepositoryToken
+		for _, t := range tokens {
+			if !present[t.GetToken()] {
+				missing = append(missing, t)
+			}
+		}
+		sort.SliceStable(missing, func(i, j int) bool { return missing[i].GetName() < missing[j].GetName() })
+		return missing
+	}
+	return missingFrom(after, before), missingFrom(before, after)
+}
+
+func flattenSyncedEntitlements(tokens []cloudsmith.RepositoryToken) []interface{} {
+	flattened := make([]interface{}, len(tokens))
+	for i, t := range tokens {
+		flattened[i] = map[string]interface{}{
+			"name":      t.GetName(),
+			"slug_perm": t.GetSlugPerm(),
+		}
+	}
+	return flattened
+}
+
+func importEntitlementSync(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	idParts := strings.Split(d.Id(), ".")
+	if len(idParts) != 3 {
+		return nil, fmt.Errorf(
+			"invalid import ID, must be of the form <namespace_slug>.<repository_slug>.<source_repository_slug>, got: %s", d.Id(),
+		)
+	}
+
+	_ = d.Set("namespace", idParts[0])
+	_ = d.Set("repository", idParts[1])
+	_ = d.Set("source_repository", idParts[2])
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceEntitlementSyncUpdate synchronizes the entitlements of the target
+// repository from the source repository and records which were added or
+// removed.
+func resourceEntitlementSyncUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+	repository := requiredString(d, "repository")
+	source := requiredString(d, "source_repository")
+
+	// Only toggling keep_synced doesn't need a sync.
+	if d.Id() != "" && !d.HasChanges("missing_tokens", "extra_tokens") {
+		return resourceEntitlementSyncRead(ctx, d, m)
+	}
+
+	before, err := listEntitlementTokens(pc, namespace, repository)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	req := pc.APIClient.EntitlementsApi.EntitlementsSync(pc.Auth, namespace, repository)
+	req = req.Data(cloudsmith.RepositoryTokenSyncRequest{Source: source})
+	req = req.ShowTokens(true)
+	if _, _, err := pc.APIClient.EntitlementsApi.EntitlementsSyncExecute(req); err != nil {
+		return diag.FromErr(fmt.Errorf("error synchronizing entitlements of repository %s/%s from %s: %w", namespace, repository, source, formatAPIError(err)))
+	}
+
+	after, err := listEntitlementTokens(pc, namespace, repository)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s.%s", namespace, repository, source))
+
+	added, removed := diffEntitlementTokens(before, after)
+	if err := d.Set("added_tokens", flattenSyncedEntitlements(added)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("removed_tokens", flattenSyncedEntitlements(removed)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("last_synced_at", timeToString(time.Now().UTC())); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceEntitlementSyncRead(ctx, d, m)
+}
+
+// resourceEntitlementSyncRead records the entitlements of the source
+// repository whose tokens are missing from the target repository, and those of
+// the target repository whose tokens aren't in the source repository. If either
+// repository no longer exists, the resource is removed from state.
+func resourceEntitlementSyncRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+
+	sourceTokens, sourceExists, err := listEntitlementTokensIfExists(pc, namespace, requiredString(d, "source_repository"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	targetTokens, targetExists, err := listEntitlementTokensIfExists(pc, namespace, requiredString(d, "repository"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !sourceExists || !targetExists {
+		d.SetId("")
+		return nil
+	}
+
+	extra, missing := diffEntitlementTokens(sourceTokens, targetTokens)
+	if err := d.Set("missing_tokens", flattenSyncedEntitlements(missing)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("extra_tokens", flattenSyncedEntitlements(extra)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// resourceEntitlementSyncDelete only removes the resource from state; the
+// synchronized entitlements are kept.
+func resourceEntitlementSyncDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// customizeDiffEntitlementSync rejects a repository synchronized from itself
+// and, when keep_synced is enabled, plans a sync if the entitlements of the
+// target repository don't match those of the source repository.
+func customizeDiffEntitlementSync(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.NewValueKnown("repository") && d.NewValueKnown("source_repository") &&
+		d.Get("repository").(string) == d.Get("source_repository").(string) {
+		return fmt.Errorf("%q must differ from %q", "source_repository", "repository")
+	}
+
+	if d.Id() == "" || !d.Get("keep_synced").(bool) {
+		return nil
+	}
+	if len(d.Get("missing_tokens").([]interface{})) == 0 && len(d.Get("extra_tokens").([]interface{})) == 0 {
+		return nil
+	}
+
+	for _, key := range []string{"missing_tokens", "extra_tokens"} {
+		if err := d.SetNew(key, []interface{}{}); err != nil {
+			return err
+		}
+	}
+	for _, key := range []string{"added_tokens", "removed_tokens", "last_synced_at"} {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func syncedEntitlementsSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: description,
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Description: "The name of the entitlement.",
+					Computed:    true,
+				},
+				"slug_perm": {
+					Type:        schema.TypeString,
+					Description: "The permanent slug identifier of the entitlement.",
+					Computed:    true,
+				},
+			},
+		},
+	}
+}
+
+func resourceEntitlementSync() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceEntitlementSyncUpdate,
+		ReadContext:   resourceEntitlementSyncRead,
+		UpdateContext: resourceEntitlementSyncUpdate,
+		DeleteContext: resourceEntitlementSyncDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importEntitlementSync,
+		},
+
+		CustomizeDiff: customizeDiffEntitlementSync,
+
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:         schema.TypeString,
+				Description:  "The Organization to which both repositories belong.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"repository": {
+				Type:         schema.TypeString,
+				Description:  "The Repository to which entitlements are synchronized.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"source_repository": {
+				Type:         schema.TypeString,
+				Description:  "The Repository from which entitlements are synchronized.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"keep_synced": {
+				Type: schema.TypeBool,
+				Description: "If enabled, entitlements are synchronized again on any apply where entitlements " +
+					"of the source repository are missing from the target repository, or the target repository " +
+					"has entitlements the source repository doesn't. Otherwise they are only synchronized when " +
+					"the resource is created.",
+				Optional: true,
+				Default:  false,
+			},
+			"added_tokens":   syncedEntitlementsSchema("The entitlements added to the target repository by the last synchronization."),
+			"removed_tokens": syncedEntitlementsSchema("The entitlements removed from the target repository by the last synchronization."),
+			"missing_tokens": syncedEntitlementsSchema("The entitlements of the source repository whose tokens are missing from the target repository."),
+			"extra_tokens":   syncedEntitlementsSchema("The entitlements of the target repository whose tokens aren't in the source repository."),
+			"last_synced_at": {
+				Type:        schema.TypeString,
+				Description: "ISO 8601 timestamp of the last synchronization.",
+				Computed:    true,
+			},
+		},
+	}
+}
@@ -0,0 +1,184 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type fakeEntitlementSyncServer struct {
+	mu      sync.Mutex
+	tokens  map[string][]map[string]interface{}
+	syncs   int
+	lastSrc string
+}
+
+func (s *fakeEntitlementSyncServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/entitlements/org/"):
+		repository := strings.Trim(strings.TrimPrefix(r.URL.Path, "/entitlements/org/"), "/")
+		tokens, ok := s.tokens[repository]
+		if !ok {
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Pagination-Count", fmt.Sprint(len(tokens)))
+		w.Header().Set("X-Pagination-Page", "1")
+		w.Header().Set("X-Pagination-PageTotal", "1")
+		w.Header().Set("X-Pagination-PageSize", "100")
+		_ = json.NewEncoder(w).Encode(tokens)
+	case r.Method == http.MethodPost && r.URL.Path == "/entitlements/org/target/sync/":
+		var body struct {
+			Source string `json:"source"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.syncs++
+		s.lastSrc = body.Source
+
+		var synced []map[string]interface{}
+		for i, t := range s.tokens[body.Source] {
+			synced = append(synced, map[string]interface{}{
+				"name":      t["name"],
+				"token":     t["token"],
+				"slug_perm": fmt.Sprintf("copy%d", i),
+			})
+		}
+		s.tokens["target"] = synced
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"tokens": synced})
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func syncedNames(d *schema.ResourceData, key string) []string {
+	var names []string
+	for _, v := range d.Get(key).([]interface{}) {
+		names = append(names, v.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func TestResourceEntitlementSyncCreate(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEntitlementSyncServer{tokens: map[string][]map[string]interface{}{
+		"source": {
+			{"name": "Customer A", "token": "aaa", "slug_perm": "a"},
+			{"name": "Customer B", "token": "bbb", "slug_perm": "b"},
+		},
+		"target": {
+			{"name": "Customer B", "token": "bbb", "slug_perm": "b2"},
+			{"name": "Old", "token": "old", "slug_perm": "o"},
+		},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceEntitlementSync().Schema, map[string]interface{}{
+		"namespace":         "org",
+		"repository":        "target",
+		"source_repository": "source",
+	})
+
+	if diags := resourceEntitlementSyncUpdate(context.Background(), d, testPrivilegesProviderConfig(server)); diags.HasError() {
+		t.Fatalf("resourceEntitlementSyncUpdate() error = %v", diags)
+	}
+
+	if fake.syncs != 1 || fake.lastSrc != "source" {
+		t.Fatalf("syncs = %d from %q, want 1 from source", fake.syncs, fake.lastSrc)
+	}
+	if d.Id() != "org.target.source" {
+		t.Fatalf("id = %q, want org.target.source", d.Id())
+	}
+	if got := syncedNames(d, "added_tokens"); len(got) != 1 || got[0] != "Customer A" {
+		t.Fatalf("added_tokens = %v, want [Customer A]", got)
+	}
+	if got := syncedNames(d, "removed_tokens"); len(got) != 1 || got[0] != "Old" {
+		t.Fatalf("removed_tokens = %v, want [Old]", got)
+	}
+	if got := syncedNames(d, "missing_tokens"); len(got) != 0 {
+		t.Fatalf("missing_tokens = %v, want none", got)
+	}
+	if d.Get("last_synced_at").(string) == "" {
+		t.Fatal("last_synced_at is not set")
+	}
+}
+
+func TestResourceEntitlementSyncRead(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEntitlementSyncServer{tokens: map[string][]map[string]interface{}{
+		"source": {
+			{"name": "Customer A", "token": "aaa", "slug_perm": "a"},
+			{"name": "Customer C", "token": "ccc", "slug_perm": "c"},
+		},
+		"target": {
+			{"name": "Customer A", "token": "aaa", "slug_perm": "a2"},
+			{"name": "Old", "token": "old", "slug_perm": "o"},
+		},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceEntitlementSync().Schema, map[string]interface{}{
+		"namespace":         "org",
+		"repository":        "target",
+		"source_repository": "source",
+	})
+	d.SetId("org.target.source")
+
+	if diags := resourceEntitlementSyncRead(context.Background(), d, testPrivilegesProviderConfig(server)); diags.HasError() {
+		t.Fatalf("resourceEntitlementSyncRead() error = %v", diags)
+	}
+
+	if fake.syncs != 0 {
+		t.Fatalf("read synchronized entitlements %d times", fake.syncs)
+	}
+	if got := syncedNames(d, "missing_tokens"); len(got) != 1 || got[0] != "Customer C" {
+		t.Fatalf("missing_tokens = %v, want [Customer C]", got)
+	}
+	if got := syncedNames(d, "extra_tokens"); len(got) != 1 || got[0] != "Old" {
+		t.Fatalf("extra_tokens = %v, want [Old]", got)
+	}
+}
+
+func TestResourceEntitlementSyncReadRepositoryNotFound(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEntitlementSyncServer{tokens: map[string][]map[string]interface{}{
+		"source": {
+			{"name": "Customer A", "token": "aaa", "slug_perm": "a"},
+		},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	for _, config := range []map[string]interface{}{
+		{"namespace": "org", "repository": "gone", "source_repository": "source"},
+		{"namespace": "org", "repository": "source", "source_repository": "gone"},
+	} {
+		d := schema.TestResourceDataRaw(t, resourceEntitlementSync().Schema, config)
+		d.SetId("org.id")
+
+		if diags := resourceEntitlementSyncRead(context.Background(), d, pc); diags.HasError() {
+			t.Fatalf("resourceEntitlementSyncRead() error = %v", diags)
+		}
+		if d.Id() != "" {
+			t.Fatalf("id = %q, want the resource to be removed from state when a repository is gone", d.Id())
+		}
+	}
+}
@@ -4,9 +4,12 @@ package cloudsmith
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -161,3 +164,70 @@ resource "cloudsmith_entitlement" "test" {
 }
 `, repositoryName, os.Getenv("CLOUDSMITH_NAMESPACE"))
 }
+
+func TestCheckDateRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(s string) *time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &v
+	}
+
+	tests := []struct {
+		name      string
+		from, to  *time.Time
+		toChanged bool
+		wantErr   string
+	}{
+		{name: "unset"},
+		{name: "valid range", from: at("2026-01-01T00:00:00Z"), to: at("2027-01-01T00:00:00Z"), toChanged: true},
+		{name: "from only", from: at("2027-01-01T00:00:00Z")},
+		{name: "from after to", from: at("2027-01-01T00:00:00Z"), to: at("2026-07-01T00:00:00Z"), wantErr: "must be before"},
+		{name: "from equals to", from: at("2027-01-01T00:00:00Z"), to: at("2027-01-01T01:00:00+01:00"), wantErr: "must be before"},
+		{name: "new end in the past", to: at("2026-01-01T00:00:00Z"), toChanged: true, wantErr: "is in the past"},
+		{name: "unchanged end in the past", to: at("2026-01-01T00:00:00Z")},
+	}
+
+	for _, tc := range tests {
+		err := checkDateRange(tc.from, tc.to, tc.toChanged, now)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: checkDateRange() error = %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: checkDateRange() error = %v, want error containing %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSuppressLimitDateRangeTo(t *testing.T) {
+	t.Parallel()
+
+	withDuration := schema.TestResourceDataRaw(t, resourceEntitlement().Schema, map[string]interface{}{
+		"name":           "Test",
+		"namespace":      "org",
+		"repository":     "repo",
+		"limit_duration": "720h",
+	})
+	withoutDuration := schema.TestResourceDataRaw(t, resourceEntitlement().Schema, map[string]interface{}{
+		"name":       "Test",
+		"namespace":  "org",
+		"repository": "repo",
+	})
+
+	if !suppressLimitDateRangeTo("limit_date_range_to", "2026-07-01T00:00:00Z", "", withDuration) {
+		t.Error("expected the end set by limit_duration not to diff")
+	}
+	if suppressLimitDateRangeTo("limit_date_range_to", "2026-07-01T00:00:00Z", "", withoutDuration) {
+		t.Error("expected removing limit_date_range_to to diff")
+	}
+	if !suppressLimitDateRangeTo("limit_date_range_to", "2026-07-01T00:00:00Z", "2026-07-01T02:00:00+02:00", withoutDuration) {
+		t.Error("expected equivalent timestamps not to diff")
+	}
+}
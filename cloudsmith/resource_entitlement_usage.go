@@ -0,0 +1,60 @@
+package cloudsmith
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// entitlementUsageKeys are the attributes holding the usage counters of an
+// entitlement, which are reset by reset_usage_trigger.
+var entitlementUsageKeys = []string{"bandwidth_used", "bandwidth_used_unit", "clients", "downloads", "usage"}
+
+type entitlementBandwidth struct {
+	bandwidth float64
+	unit      string
+}
+
+// readEntitlementBandwidth returns the bandwidth used by an entitlement token,
+// as reported by the entitlement metrics.
+func readEntitlementBandwidth(pc *providerConfig, namespace, repository, entitlement string) (entitlementBandwidth, error) {
+	req := pc.APIClient.MetricsApi.MetricsEntitlementsRepoList(pc.Auth, namespace, repository).
+		Tokens(entitlement)
+	metrics, _, err := pc.APIClient.MetricsApi.MetricsEntitlementsRepoListExecute(req)
+	if err != nil {
+		return entitlementBandwidth{}, fmt.Errorf("error reading metrics for entitlement (%s): %w", entitlement, formatAPIError(err))
+	}
+
+	tokens := metrics.GetTokens()
+	bandwidth := tokens.GetBandwidth()
+	bandwidthTotal := bandwidth.GetTotal()
+
+	return entitlementBandwidth{
+		bandwidth: bandwidthTotal.GetValue(),
+		unit:      bandwidthTotal.GetUnits(),
+	}, nil
+}
+
+// setEntitlementBandwidth sets bandwidth_used and bandwidth_used_unit on the
+// entitlement. Metrics aren't essential to managing the entitlement, so if they
+// can't be read the previous values are kept rather than failing the read.
+func setEntitlementBandwidth(pc *providerConfig, d *schema.ResourceData, namespace, repository string) {
+	bandwidth, err := readEntitlementBandwidth(pc, namespace, repository, d.Id())
+	if err != nil {
+		log.Printf("[WARN] not updating bandwidth used by entitlement: %s", err)
+		return
+	}
+
+	d.Set("bandwidth_used", bandwidth.bandwidth)
+	d.Set("bandwidth_used_unit", bandwidth.unit)
+}
+
+// resetEntitlementUsage resets the usage counters of an entitlement to zero.
+func resetEntitlementUsage(pc *providerConfig, namespace, repository, entitlement string) error {
+	req := pc.APIClient.EntitlementsApi.EntitlementsReset(pc.Auth, namespace, repository, entitlement)
+	if _, err := pc.APIClient.EntitlementsApi.EntitlementsResetExecute(req); err != nil {
+		return fmt.Errorf("error resetting usage of entitlement (%s): %w", entitlement, formatAPIError(err))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadEntitlementBandwidth(t *testing.T) {
+	t.Parallel()
+
+	var requestPath, requestTokens string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		requestTokens = r.URL.Query().Get("tokens")
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tokens":{"bandwidth":{"total":{"value":1.5,"units":"GB","display":"1.5 GB"}}}}`))
+	}))
+	defer server.Close()
+
+	bandwidth, err := readEntitlementBandwidth(testPrivilegesProviderConfig(server), "org", "repo", "abc")
+	if err != nil {
+		t.Fatalf("readEntitlementBandwidth() error = %v", err)
+	}
+
+	if requestPath != "/metrics/entitlements/org/repo/" {
+		t.Fatalf("unexpected request path %q", requestPath)
+	}
+	if requestTokens != "abc" {
+		t.Fatalf("unexpected tokens query %q", requestTokens)
+	}
+	if bandwidth.bandwidth != 1.5 || bandwidth.unit != "GB" {
+		t.Fatalf("bandwidth = %+v, want 1.5 GB", bandwidth)
+	}
+}
+
+func TestResetEntitlementUsage(t *testing.T) {
+	t.Parallel()
+
+	var requestMethod, requestPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestMethod = r.Method
+		requestPath = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := resetEntitlementUsage(testPrivilegesProviderConfig(server), "org", "repo", "abc"); err != nil {
+		t.Fatalf("resetEntitlementUsage() error = %v", err)
+	}
+
+	if requestMethod != http.MethodPost || requestPath != "/entitlements/org/repo/abc/reset/" {
+		t.Fatalf("unexpected request %s %s", requestMethod, requestPath)
+	}
+}
+
+func TestResetEntitlementUsage_Error(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if err := resetEntitlementUsage(testPrivilegesProviderConfig(server), "org", "repo", "abc"); err == nil {
+		t.Fatal("resetEntitlementUsage() error = nil, want an error")
+	}
+}
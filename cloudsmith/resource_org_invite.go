@@ -0,0 +1,380 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	orgInviteStatusExpired = "expired"
+	orgInviteStatusPending = "pending"
+)
+
+var orgRoles = []string{
+	"Owner",
+	"Manager",
+	"Member",
+	"ReadOnly",
+}
+
+func importOrgInvite(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idParts := strings.Split(d.Id(), ".")
+	if len(idParts) != 2 {
+		return nil, fmt.Errorf(
+			"invalid import ID, must be of the form <organization_slug>.<invite_slug_perm>, got: %s", d.Id(),
+		)
+	}
+
+	d.Set("organization", idParts[0])
+	d.SetId(idParts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandOrgInviteTeams(d *schema.ResourceData) []cloudsmith.OrganizationTeamInvite {
+	set := d.Get("team").(*schema.Set)
+
+	teams := make([]cloudsmith.OrganizationTeamInvite, 0, set.Len())
+	for _, x := range set.List() {
+		m := x.(map[string]interface{})
+		team := cloudsmith.OrganizationTeamInvite{Team: m["slug"].(string)}
+		if role := m["role"].(string); role != "" {
+			team.SetRole(role)
+		}
+		teams = append(teams, team)
+	}
+	return teams
+}
+
+func flattenOrgInviteTeams(teams []cloudsmith.OrganizationTeamInvite) *schema.Set {
+	teamSchema := resourceOrgInvite().Schema["team"].Elem.(*schema.Resource)
+	set := schema.NewSet(schema.HashResource(teamSchema), []interface{}{})
+	for _, team := range teams {
+		set.Add(map[string]interface{}{
+			"role": team.GetRole(),
+			"slug": team.GetTeam(),
+		})
+	}
+	return set
+}
+
+// orgInviteStatus returns the status of an invite that hasn't been accepted.
+func orgInviteStatus(invite cloudsmith.OrganizationInvite, now time.Time) string {
+	if expiresAt, ok := invite.GetExpiresAtOk(); ok && expiresAt != nil && expiresAt.Before(now) {
+		return orgInviteStatusExpired
+	}
+	return orgInviteStatusPending
+}
+
+// findOrgInvite returns the open invite with the given slug_perm, or nil if
+// there is no such invite. Invites can't be read individually, so this lists
+// every open invite of the organization.
+func findOrgInvite(pc *providerConfig, organization, slugPerm string) (*cloudsmith.OrganizationInvite, error) {
+	exec := func(page, ps int64) ([]cloudsmith.OrganizationInvite, *http.Response, error) {
+		req := pc.APIClient.OrgsApi.OrgsInvitesList(pc.Auth, organization).
+			Page(page).
+			PageSize(ps)
+		return pc.APIClient.OrgsApi.OrgsInvitesListExecute(req)
+	}
+	invites, err := PaginateAllHTTP[cloudsmith.OrganizationInvite](exec, PaginationOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving invites of organization %s: %w", organization, formatAPIError(err))
+	}
+
+	for i := range invites {
+		if invites[i].GetSlugPerm() == slugPerm {
+			return &invites[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// findOrgMemberByEmail returns the active member of the organization with the
+// given email address, or nil if there is none.
+func findOrgMemberByEmail(pc *providerConfig, organization, email string) (*cloudsmith.OrganizationMembership, error) {
+	exec := func(page, ps int64) ([]cloudsmith.OrganizationMembership, *http.Response, error) {
+		req := pc.APIClient.OrgsApi.OrgsMembersList(pc.Auth, organization).
+			Page(page).
+			PageSize(ps).
+			IsActive(true)
+		return pc.APIClient.OrgsApi.OrgsMembersListExecute(req)
+	}
+	members, err := PaginateAllHTTP[cloudsmith.OrganizationMembership](exec, PaginationOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving members of organization %s: %w", organization, formatAPIError(err))
+	}
+
+	for i := range members {
+		if strings.EqualFold(members[i].GetEmail(), email) {
+			return &members[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func resourceOrgInviteCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+
+	req := pc.APIClient.OrgsApi.OrgsInvitesCreate(pc.Auth, organization)
+	req = req.Data(cloudsmith.OrganizationInviteRequest{
+		Email: optionalString(d, "email"),
+		Role:  optionalString(d, "role"),
+		Teams: expandOrgInviteTeams(d),
+	})
+
+	invite, _, err := pc.APIClient.OrgsApi.OrgsInvitesCreateExecute(req)
+	if err != nil {
+		return diag.Errorf("error inviting %s to organization %s: %s", requiredString(d, "email"), organization, formatAPIError(err))
+	}
+
+	d.SetId(invite.GetSlugPerm())
+
+	checkerFunc := func() error {
+		invite, err := findOrgInvite(pc, organization, d.Id())
+		if err != nil {
+			return err
+		}
+		if invite == nil {
+			return errKeepWaiting
+		}
+		return nil
+	}
+	if err := waiter(checkerFunc, defaultCreationTimeout, defaultCreationInterval); err != nil {
+		return diag.Errorf("error waiting for invite (%s) to be created: %s", d.Id(), err)
+	}
+
+	return resourceOrgInviteRead(ctx, d, m)
+}
+
+// resourceOrgInviteRead reads the invite. Once an invite is accepted, revoked
+// or declined it no longer exists, so it is removed from state and the next
+// plan creates it again. If the invited email address is now a member of the
+// organization, a warning asks for the invite to be removed from the
+// configuration instead.
+func resourceOrgInviteRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+
+	invite, err := findOrgInvite(pc, organization, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if invite == nil {
+		email := requiredString(d, "email")
+		member, err := findOrgMemberByEmail(pc, organization, email)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		id := d.Id()
+		d.SetId("")
+		if member == nil {
+			return nil
+		}
+
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Invite (%s) for %s has been accepted", id, email),
+			Detail: fmt.Sprintf("%s is now a member of %s as %s, so the invite no longer exists and has been removed from state. "+
+				"Remove this resource from the configuration rather than applying the plan to invite them again; "+
+				"doing so doesn't remove the membership.",
+				member.GetUser(), organization, member.GetRole()),
+		}}
+	}
+
+	d.Set("email", invite.GetEmail())
+	d.Set("role", invite.GetRole())
+	d.Set("team", flattenOrgInviteTeams(invite.GetTeams()))
+	d.Set("slug_perm", invite.GetSlugPerm())
+	d.Set("status", orgInviteStatus(*invite, time.Now()))
+	d.Set("expires_at", timeToString(invite.GetExpiresAt()))
+	d.Set("inviter", invite.GetInviter())
+	d.Set("user", invite.GetUser())
+
+	// organization is not returned from the invite endpoints, so we use the
+	// value from state and set it back to be safe.
+	d.Set("organization", organization)
+
+	return nil
+}
+
+func resourceOrgInviteUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+
+	if d.HasChanges("role", "team") {
+		req := pc.APIClient.OrgsApi.OrgsInvitesPartialUpdate(pc.Auth, organization, d.Id())
+		req = req.Data(cloudsmith.OrganizationInviteUpdateRequestPatch{
+			Role:  optionalString(d, "role"),
+			Teams: expandOrgInviteTeams(d),
+		})
+		if _, _, err := pc.APIClient.OrgsApi.OrgsInvitesPartialUpdateExecute(req); err != nil {
+			return diag.Errorf("error updating invite (%s): %s", d.Id(), formatAPIError(err))
+		}
+	}
+
+	if d.HasChange("extend_trigger") && requiredString(d, "extend_trigger") != "" {
+		req := pc.APIClient.OrgsApi.OrgsInvitesExtend(pc.Auth, organization, d.Id())
+		if _, _, err := pc.APIClient.OrgsApi.OrgsInvitesExtendExecute(req); err != nil {
+			return diag.Errorf("error extending invite (%s): %s", d.Id(), formatAPIError(err))
+		}
+	}
+
+	if d.HasChange("resend_trigger") && requiredString(d, "resend_trigger") != "" {
+		req := pc.APIClient.OrgsApi.OrgsInvitesResend(pc.Auth, organization, d.Id())
+		if _, _, err := pc.APIClient.OrgsApi.OrgsInvitesResendExecute(req); err != nil {
+			return diag.Errorf("error resending invite (%s): %s", d.Id(), formatAPIError(err))
+		}
+	}
+
+	if err := waitForUpdate("invite", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceOrgInviteRead(ctx, d, m)
+}
+
+// resourceOrgInviteDelete revokes the invite.
+func resourceOrgInviteDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+
+	req := pc.APIClient.OrgsApi.OrgsInvitesDelete(pc.Auth, organization, d.Id())
+	if resp, err := pc.APIClient.OrgsApi.OrgsInvitesDeleteExecute(req); err != nil && !is404(resp) {
+		return diag.Errorf("error deleting invite (%s): %s", d.Id(), formatAPIError(err))
+	}
+
+	return nil
+}
+
+// customizeDiffOrgInvite plans a new expiry when the invite is extended or
+// resent.
+func customizeDiffOrgInvite(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	if triggerChanged(d, "extend_trigger") || triggerChanged(d, "resend_trigger") {
+		if err := d.SetNewComputed("expires_at"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("status")
+	}
+	return nil
+}
+
+// suppressEmailCase suppresses diffs between email addresses that only differ
+// in case, as Cloudsmith may return the address in a different case than
+// configured, and email is ForceNew.
+func suppressEmailCase(_, old, new string, _ *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
+func resourceOrgInvite() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceOrgInviteCreate,
+		ReadContext:   resourceOrgInviteRead,
+		UpdateContext: resourceOrgInviteUpdate,
+		DeleteContext: resourceOrgInviteDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importOrgInvite,
+		},
+
+		CustomizeDiff: customizeDiffOrgInvite,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Type:         schema.TypeString,
+				Description:  "Organization to which the user is invited.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"email": {
+				Type:             schema.TypeString,
+				Description:      "The email address to send the invite to. Differences in case are ignored.",
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validation.StringIsNotEmpty,
+				DiffSuppressFunc: suppressEmailCase,
+			},
+			"role": {
+				Type:         schema.TypeString,
+				Description:  "The role the user will have in the organization.",
+				Optional:     true,
+				Default:      "Member",
+				ValidateFunc: validation.StringInSlice(orgRoles, false),
+			},
+			"team": {
+				Type:        schema.TypeSet,
+				Description: "Teams the user will be added to when the invite is accepted.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role": {
+							Type:         schema.TypeString,
+							Description:  "The role the user will have in the team.",
+							Optional:     true,
+							Default:      "Member",
+							ValidateFunc: validation.StringInSlice(roles, false),
+						},
+						"slug": {
+							Type:         schema.TypeString,
+							Description:  "The team the user will be added to.",
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+					},
+				},
+			},
+			"extend_trigger": {
+				Type:        schema.TypeString,
+				Description: "Changing this value extends the expiry of the invite.",
+				Optional:    true,
+			},
+			"resend_trigger": {
+				Type:        schema.TypeString,
+				Description: "Changing this value sends the invite email again.",
+				Optional:    true,
+			},
+			"slug_perm": {
+				Type:        schema.TypeString,
+				Description: "The slug_perm immutably identifies the invite.",
+				Computed:    true,
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Description: "The status of the invite: pending or expired.",
+				Computed:    true,
+			},
+			"expires_at": {
+				Type:        schema.TypeString,
+				Description: "ISO 8601 timestamp at which the invite expires.",
+				Computed:    true,
+			},
+			"inviter": {
+				Type:        schema.TypeString,
+				Description: "The user who sent the invite.",
+				Computed:    true,
+			},
+			"user": {
+				Type:        schema.TypeString,
+				Description: "The slug of the invited user, if they have a Cloudsmith account.",
+				Computed:    true,
+			},
+		},
+	}
+}
@@ -0,0 +1,170 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func orgInviteTestServer(t *testing.T, invites, members []map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	writePage := func(w http.ResponseWriter, results []map[string]interface{}) {
+		w.Header().Set("X-Pagination-Count", fmt.Sprint(len(results)))
+		w.Header().Set("X-Pagination-Page", "1")
+		w.Header().Set("X-Pagination-PageTotal", "1")
+		w.Header().Set("X-Pagination-PageSize", "100")
+		_ = json.NewEncoder(w).Encode(results)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/orgs/org/invites/":
+			writePage(w, invites)
+		case r.Method == http.MethodGet && r.URL.Path == "/orgs/org/members/":
+			writePage(w, members)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestOrgInviteStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	server := orgInviteTestServer(t, []map[string]interface{}{
+		{"slug_perm": "future", "email": "a@example.com", "expires_at": "2026-06-08T00:00:00Z"},
+		{"slug_perm": "past", "email": "b@example.com", "expires_at": "2026-05-25T00:00:00Z"},
+		{"slug_perm": "none", "email": "c@example.com"},
+	}, nil)
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	for slugPerm, want := range map[string]string{
+		"future": orgInviteStatusPending,
+		"past":   orgInviteStatusExpired,
+		"none":   orgInviteStatusPending,
+	} {
+		invite, err := findOrgInvite(pc, "org", slugPerm)
+		if err != nil || invite == nil {
+			t.Fatalf("findOrgInvite(%q) = %v, %v", slugPerm, invite, err)
+		}
+		if got := orgInviteStatus(*invite, now); got != want {
+			t.Errorf("orgInviteStatus(%q) = %q, want %q", slugPerm, got, want)
+		}
+	}
+}
+
+func TestResourceOrgInviteRead(t *testing.T) {
+	t.Parallel()
+
+	invites := []map[string]interface{}{
+		{
+			"slug_perm":  "pending",
+			"email":      "new@example.com",
+			"role":       "Manager",
+			"expires_at": time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+			"inviter":    "admin",
+			"teams":      []map[string]interface{}{{"team": "engineering", "role": "Member"}},
+		},
+	}
+	members := []map[string]interface{}{
+		{"email": "Joined@Example.com", "user": "joined", "role": "Member", "is_active": true},
+	}
+	server := orgInviteTestServer(t, invites, members)
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	tests := []struct {
+		name       string
+		slugPerm   string
+		email      string
+		wantID     string
+		wantStatus string
+		wantWarn   bool
+	}{
+		{name: "pending", slugPerm: "pending", email: "new@example.com", wantID: "pending", wantStatus: orgInviteStatusPending},
+		{name: "accepted", slugPerm: "accepted", email: "joined@example.com", wantID: "", wantWarn: true},
+		{name: "revoked", slugPerm: "revoked", email: "gone@example.com", wantID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceOrgInvite().Schema, map[string]interface{}{
+				"organization": "org",
+				"email":        tt.email,
+			})
+			d.SetId(tt.slugPerm)
+			d.Set("status", orgInviteStatusPending)
+
+			diags := resourceOrgInviteRead(context.Background(), d, pc)
+			if diags.HasError() {
+				t.Fatalf("resourceOrgInviteRead() error = %v", diags)
+			}
+			if warned := len(diags) == 1 && diags[0].Severity == diag.Warning; warned != tt.wantWarn {
+				t.Fatalf("resourceOrgInviteRead() diagnostics = %v, want warning %v", diags, tt.wantWarn)
+			}
+
+			if d.Id() != tt.wantID {
+				t.Fatalf("id = %q, want %q", d.Id(), tt.wantID)
+			}
+			if tt.wantID == "" {
+				return
+			}
+			if got := d.Get("status").(string); got != tt.wantStatus {
+				t.Fatalf("status = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+
+	t.Run("pending details", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourceOrgInvite().Schema, map[string]interface{}{
+			"organization": "org",
+			"email":        "new@example.com",
+		})
+		d.SetId("pending")
+
+		if diags := resourceOrgInviteRead(context.Background(), d, pc); diags.HasError() {
+			t.Fatalf("resourceOrgInviteRead() error = %v", diags)
+		}
+		if got := d.Get("role").(string); got != "Manager" {
+			t.Fatalf("role = %q, want Manager", got)
+		}
+		if got := d.Get("expires_at").(string); got == "" || !strings.HasSuffix(got, "Z") {
+			t.Fatalf("expires_at = %q, want an RFC 3339 timestamp", got)
+		}
+		teams := d.Get("team").(*schema.Set).List()
+		if len(teams) != 1 || teams[0].(map[string]interface{})["slug"] != "engineering" {
+			t.Fatalf("team = %v, want engineering", teams)
+		}
+	})
+}
+
+func TestSuppressEmailCase(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		old, new string
+		want     bool
+	}{
+		{old: "new@example.com", new: "new@example.com", want: true},
+		{old: "New@Example.com", new: "new@example.com", want: true},
+		{old: "new@example.com", new: "other@example.com", want: false},
+	} {
+		if got := suppressEmailCase("email", tt.old, tt.new, nil); got != tt.want {
+			t.Errorf("suppressEmailCase(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	orgOwnerRole   = "Owner"
+	orgManagerRole = "Manager"
+)
+
+// orgAdminRoles are the roles that can manage the members of an organization.
+var orgAdminRoles = []string{orgOwnerRole, orgManagerRole}
+
+var orgMemberVisibilities = []string{
+	"Public",
+	"Private",
+}
+
+func importOrgMember(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idParts := strings.Split(d.Id(), ".")
+	if len(idParts) != 2 {
+		return nil, fmt.Errorf(
+			"invalid import ID, must be of the form <organization_slug>.<member_slug>, got: %s", d.Id(),
+		)
+	}
+
+	d.Set("organization", idParts[0])
+	d.Set("member", idParts[1])
+	return []*schema.ResourceData{d}, nil
+}
+
+// orgOwners returns the slugs of the active owners of the organization.
+func orgOwners(pc *providerConfig, organization string) ([]string, error) {
+	exec := func(page, ps int64) ([]cloudsmith.OrganizationMembership, *http.Response, error) {
+		req := pc.APIClient.OrgsApi.OrgsMembersList(pc.Auth, organization).
+			Page(page).
+			PageSize(ps).
+			IsActive(true)
+		return pc.APIClient.OrgsApi.OrgsMembersListExecute(req)
+	}
+	members, err := PaginateAllHTTP[cloudsmith.OrganizationMembership](exec, PaginationOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving members of organization %s: %w", organization, formatAPIError(err))
+	}
+
+	var owners []string
+	for _, member := range members {
+		if member.GetRole() == orgOwnerRole {
+			owners = append(owners, member.GetUser())
+		}
+	}
+	return owners, nil
+}
+
+// checkOrgOwnerRemains returns an error if member is the only owner of the
+// organization, as removing or demoting them would leave nobody able to manage
+// it.
+func checkOrgOwnerRemains(pc *providerConfig, organization, member string) error {
+	owners, err := orgOwners(pc, organization)
+	if err != nil {
+		return err
+	}
+	return lastOrgOwnerError(owners, organization, member)
+}
+
+// lastOrgOwnerError returns an error if member is the only one of owners.
+func lastOrgOwnerError(owners []string, organization, member string) error {
+	for _, owner := range owners {
+		if owner != member {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is the last owner of organization %s; make another member an owner first", member, organization)
+}
+
+// authenticatedUser returns the slug of the account Terraform authenticates as.
+func authenticatedUser(pc *providerConfig) (string, error) {
+	req := pc.APIClient.UserApi.UserSelf(pc.Auth)
+	userSelf, _, err := pc.APIClient.UserApi.UserSelfExecute(req)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving authenticated account: %w", err)
+	}
+	return userSelf.GetSlug(), nil
+}
+
+// demotesOrgAdmin reports whether changing a role from oldRole to newRole
+// takes away the ability to manage the organization's members.
+func demotesOrgAdmin(oldRole, newRole string) bool {
+	return newRole != "" && contains(orgAdminRoles, oldRole) && !contains(orgAdminRoles, newRole)
+}
+
+// selfDemotionError returns an error if member is self, the authenticated
+// account, as it would no longer be able to manage the organization.
+func selfDemotionError(self, organization, member, oldRole, newRole string) error {
+	if self != member {
+		return nil
+	}
+	return fmt.Errorf("%s is the authenticated account; it can't change its own role in organization %s from %s to %s", member, organization, oldRole, newRole)
+}
+
+// checkOrgMemberRoleChange returns an error if changing the role of member
+// from oldRole to newRole would lock Terraform or the organization out: the
+// authenticated account can't demote itself from Owner or Manager, and the
+// last owner can't be demoted.
+func checkOrgMemberRoleChange(pc *providerConfig, organization, member, oldRole, newRole string) error {
+	if newRole == "" || newRole == oldRole {
+		return nil
+	}
+
+	if demotesOrgAdmin(oldRole, newRole) {
+		self, err := authenticatedUser(pc)
+		if err != nil {
+			return err
+		}
+		if err := selfDemotionError(self, organization, member, oldRole, newRole); err != nil {
+			return err
+		}
+	}
+
+	if oldRole != orgOwnerRole {
+		return nil
+	}
+	return checkOrgOwnerRemains(pc, organization, member)
+}
+
+// checkOrgMemberRemovable returns an error if removing member with the given
+// role would lock Terraform or the organization out: the authenticated account
+// can't remove itself, and the last owner can't be removed.
+func checkOrgMemberRemovable(pc *providerConfig, organization, member, role string) error {
+	self, err := authenticatedUser(pc)
+	if err != nil {
+		return err
+	}
+	if self == member {
+		return fmt.Errorf("%s is the authenticated account; it can't remove itself from organization %s", member, organization)
+	}
+
+	if role != orgOwnerRole {
+		return nil
+	}
+	return checkOrgOwnerRemains(pc, organization, member)
+}
+
+func updateOrgMember(pc *providerConfig, d *schema.ResourceData, organization, member string) error {
+	if role := requiredString(d, "role"); role != "" && d.HasChange("role") {
+		req := pc.APIClient.OrgsApi.OrgsMembersUpdateRole(pc.Auth, organization, member)
+		req = req.Data(cloudsmith.OrganizationMembershipRoleUpdateRequestPatch{
+			Role: cloudsmith.PtrString(role),
+		})
+		if _, _, err := pc.APIClient.OrgsApi.OrgsMembersUpdateRoleExecute(req); err != nil {
+			return fmt.Errorf("error updating role of member %s: %w", member, formatAPIError(err))
+		}
+	}
+
+	if visibility := requiredString(d, "visibility"); visibility != "" && d.HasChange("visibility") {
+		req := pc.APIClient.OrgsApi.OrgsMembersUpdateVisibility(pc.Auth, organization, member)
+		req = req.Data(cloudsmith.OrganizationMembershipVisibilityUpdateRequestPatch{
+			Visibility: cloudsmith.PtrString(visibility),
+		})
+		if _, _, err := pc.APIClient.OrgsApi.OrgsMembersUpdateVisibilityExecute(req); err != nil {
+			return fmt.Errorf("error updating visibility of member %s: %w", member, formatAPIError(err))
+		}
+	}
+
+	return nil
+}
+
+// resourceOrgMemberCreate takes over management of an existing member. Users
+// join an organization by accepting an invite, so the member must already
+// exist.
+func resourceOrgMemberCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	member := requiredString(d, "member")
+
+	req := pc.APIClient.OrgsApi.OrgsMembersRead(pc.Auth, organization, member)
+	current, resp, err := pc.APIClient.OrgsApi.OrgsMembersReadExecute(req)
+	if err != nil {
+		if is404(resp) {
+			return diag.Errorf("%s is not a member of organization %s; invite them with cloudsmith_org_invite first", member, organization)
+		}
+		return diag.FromErr(formatAPIError(err))
+	}
+
+	if err := checkOrgMemberRoleChange(pc, organization, member, current.GetRole(), requiredString(d, "role")); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := updateOrgMember(pc, d, organization, member); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s", organization, member))
+
+	return resourceOrgMemberRead(ctx, d, m)
+}
+
+func resourceOrgMemberRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	member := requiredString(d, "member")
+
+	req := pc.APIClient.OrgsApi.OrgsMembersRead(pc.Auth, organization, member)
+	membership, resp, err := pc.APIClient.OrgsApi.OrgsMembersReadExecute(req)
+	if err != nil {
+		if is404(resp) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(formatAPIError(err))
+	}
+
+	d.Set("role", membership.GetRole())
+	d.Set("visibility", membership.GetVisibility())
+	d.Set("email", membership.GetEmail())
+	d.Set("has_two_factor", membership.GetHasTwoFactor())
+	d.Set("is_active", membership.GetIsActive())
+	d.Set("joined_at", membership.GetJoinedAt().Format(time.RFC3339))
+	d.Set("user_id", membership.GetUserId())
+
+	d.SetId(fmt.Sprintf("%s.%s", organization, member))
+
+	return nil
+}
+
+func resourceOrgMemberUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	member := requiredString(d, "member")
+
+	if oldRole, newRole := d.GetChange("role"); d.HasChange("role") {
+		if err := checkOrgMemberRoleChange(pc, organization, member, oldRole.(string), newRole.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := updateOrgMember(pc, d, organization, member); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := waitForUpdate("member", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceOrgMemberRead(ctx, d, m)
+}
+
+func resourceOrgMemberDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	member := requiredString(d, "member")
+
+	if err := checkOrgMemberRemovable(pc, organization, member, requiredString(d, "role")); err != nil {
+		return diag.Errorf("refusing to remove %s from organization %s: %s", member, organization, err)
+	}
+
+	req := pc.APIClient.OrgsApi.OrgsMembersDelete(pc.Auth, organization, member)
+	if resp, err := pc.APIClient.OrgsApi.OrgsMembersDeleteExecute(req); err != nil {
+		if is404(resp) {
+			return nil
+		}
+		return diag.Errorf("error removing %s from organization %s: %s", member, organization, formatAPIError(err))
+	}
+
+	if err := waitForDeletion(func() (*http.Response, error) {
+		req := pc.APIClient.OrgsApi.OrgsMembersRead(pc.Auth, organization, member)
+		_, resp, err := pc.APIClient.OrgsApi.OrgsMembersReadExecute(req)
+		return resp, err
+	}, "member", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// customizeDiffOrgMember surfaces the authenticated account demoting itself,
+// and demoting the last owner, at plan time. The same checks run again at
+// apply time, so if the account or members can't be read here they are left
+// to apply.
+func customizeDiffOrgMember(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.HasChange("role") || !d.NewValueKnown("role") {
+		return nil
+	}
+
+	pc := m.(*providerConfig)
+	organization := d.Get("organization").(string)
+	member := d.Get("member").(string)
+	oldRaw, newRaw := d.GetChange("role")
+	oldRole, newRole := oldRaw.(string), newRaw.(string)
+
+	if demotesOrgAdmin(oldRole, newRole) {
+		self, err := authenticatedUser(pc)
+		if err != nil {
+			log.Printf("[WARN] org_member (plan): unable to check whether %s is the authenticated account: %s", member, err)
+		} else if err := selfDemotionError(self, organization, member, oldRole, newRole); err != nil {
+			return err
+		}
+	}
+
+	if oldRole != orgOwnerRole {
+		return nil
+	}
+
+	owners, err := orgOwners(pc, organization)
+	if err != nil {
+		log.Printf("[WARN] org_member (plan): unable to check the remaining owners of organization %s: %s", organization, err)
+		return nil
+	}
+	return lastOrgOwnerError(owners, organization, member)
+}
+
+func resourceOrgMember() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceOrgMemberCreate,
+		ReadContext:   resourceOrgMemberRead,
+		UpdateContext: resourceOrgMemberUpdate,
+		DeleteContext: resourceOrgMemberDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importOrgMember,
+		},
+
+		CustomizeDiff: customizeDiffOrgMember,
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Type:         schema.TypeString,
+				Description:  "Organization to which the member belongs.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"member": {
+				Type:         schema.TypeString,
+				Description:  "The slug of the member's user.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"role": {
+				Type:         schema.TypeString,
+				Description:  "The member's role in the organization.",
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(orgRoles, false),
+			},
+			"visibility": {
+				Type:         schema.TypeString,
+				Description:  "Whether the membership is visible to other members of the organization.",
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(orgMemberVisibilities, false),
+			},
+			"email": {
+				Type:        schema.TypeString,
+				Description: "The member's email address.",
+				Computed:    true,
+			},
+			"has_two_factor": {
+				Type:        schema.TypeBool,
+				Description: "Whether the member has two-factor authentication enabled.",
+				Computed:    true,
+			},
+			"is_active": {
+				Type:        schema.TypeBool,
+				Description: "Whether the membership is active.",
+				Computed:    true,
+			},
+			"joined_at": {
+				Type:        schema.TypeString,
+				Description: "ISO 8601 timestamp at which the member joined the organization.",
+				Computed:    true,
+			},
+			"user_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the member's user.",
+				Computed:    true,
+			},
+		},
+	}
+}
@@ -0,0 +1,244 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func orgMemberTestServer(t *testing.T, self string, members []map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/user/self/":
+			fmt.Fprintf(w, `{"slug":%q}`, self)
+		case r.Method == http.MethodGet && r.URL.Path == "/orgs/org/members/":
+			w.Header().Set("X-Pagination-Count", fmt.Sprint(len(members)))
+			w.Header().Set("X-Pagination-Page", "1")
+			w.Header().Set("X-Pagination-PageTotal", "1")
+			w.Header().Set("X-Pagination-PageSize", "100")
+			_ = json.NewEncoder(w).Encode(members)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/orgs/org/members/"):
+			user := strings.Trim(strings.TrimPrefix(r.URL.Path, "/orgs/org/members/"), "/")
+			for _, member := range members {
+				if member["user"] == user {
+					_ = json.NewEncoder(w).Encode(member)
+					return
+				}
+			}
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestCheckOrgMemberRemovable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		members []map[string]interface{}
+		member  string
+		role    string
+		wantErr string
+	}{
+		{
+			name: "authenticated account",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Owner"},
+				{"user": "alice", "role": "Owner"},
+			},
+			member:  "terraform",
+			role:    "Owner",
+			wantErr: "terraform is the authenticated account",
+		},
+		{
+			name: "last owner",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Manager"},
+				{"user": "alice", "role": "Owner"},
+			},
+			member:  "alice",
+			role:    "Owner",
+			wantErr: "alice is the last owner of organization org",
+		},
+		{
+			name: "one of several owners",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Owner"},
+				{"user": "alice", "role": "Owner"},
+			},
+			member: "alice",
+			role:   "Owner",
+		},
+		{
+			name: "member",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Owner"},
+				{"user": "bob", "role": "Member"},
+			},
+			member: "bob",
+			role:   "Member",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := orgMemberTestServer(t, "terraform", tt.members)
+			defer server.Close()
+
+			err := checkOrgMemberRemovable(testPrivilegesProviderConfig(server), "org", tt.member, tt.role)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkOrgMemberRemovable() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkOrgMemberRemovable() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckOrgMemberRoleChange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		members []map[string]interface{}
+		member  string
+		oldRole string
+		newRole string
+		wantErr string
+	}{
+		{
+			name: "authenticated account demoted from owner",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Owner"},
+				{"user": "alice", "role": "Owner"},
+			},
+			member:  "terraform",
+			oldRole: "Owner",
+			newRole: "Member",
+			wantErr: "terraform is the authenticated account",
+		},
+		{
+			name: "authenticated account demoted from manager",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Manager"},
+				{"user": "alice", "role": "Owner"},
+			},
+			member:  "terraform",
+			oldRole: "Manager",
+			newRole: "ReadOnly",
+			wantErr: "terraform is the authenticated account",
+		},
+		{
+			name: "authenticated account from owner to manager",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Owner"},
+				{"user": "alice", "role": "Owner"},
+			},
+			member:  "terraform",
+			oldRole: "Owner",
+			newRole: "Manager",
+		},
+		{
+			name: "last owner",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Manager"},
+				{"user": "alice", "role": "Owner"},
+			},
+			member:  "alice",
+			oldRole: "Owner",
+			newRole: "Manager",
+			wantErr: "alice is the last owner of organization org",
+		},
+		{
+			name: "another manager demoted",
+			members: []map[string]interface{}{
+				{"user": "terraform", "role": "Owner"},
+				{"user": "bob", "role": "Manager"},
+			},
+			member:  "bob",
+			oldRole: "Manager",
+			newRole: "Member",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := orgMemberTestServer(t, "terraform", tt.members)
+			defer server.Close()
+
+			err := checkOrgMemberRoleChange(testPrivilegesProviderConfig(server), "org", tt.member, tt.oldRole, tt.newRole)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkOrgMemberRoleChange() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkOrgMemberRoleChange() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceOrgMemberRead(t *testing.T) {
+	t.Parallel()
+
+	server := orgMemberTestServer(t, "terraform", []map[string]interface{}{
+		{"user": "alice", "role": "Manager", "visibility": "Private", "email": "alice@example.com", "is_active": true, "user_id": "abc123"},
+	})
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	d := schema.TestResourceDataRaw(t, resourceOrgMember().Schema, map[string]interface{}{
+		"organization": "org",
+		"member":       "alice",
+	})
+	d.SetId("org.alice")
+
+	if diags := resourceOrgMemberRead(context.Background(), d, pc); diags.HasError() {
+		t.Fatalf("resourceOrgMemberRead() error = %v", diags)
+	}
+	if got := d.Get("role").(string); got != "Manager" {
+		t.Fatalf("role = %q, want Manager", got)
+	}
+	if got := d.Get("visibility").(string); got != "Private" {
+		t.Fatalf("visibility = %q, want Private", got)
+	}
+	if got := d.Get("user_id").(string); got != "abc123" {
+		t.Fatalf("user_id = %q, want abc123", got)
+	}
+
+	removed := schema.TestResourceDataRaw(t, resourceOrgMember().Schema, map[string]interface{}{
+		"organization": "org",
+		"member":       "bob",
+	})
+	removed.SetId("org.bob")
+
+	if diags := resourceOrgMemberRead(context.Background(), removed, pc); diags.HasError() {
+		t.Fatalf("resourceOrgMemberRead() error = %v", diags)
+	}
+	if removed.Id() != "" {
+		t.Fatalf("id = %q, want a removed member to be dropped from state", removed.Id())
+	}
+}
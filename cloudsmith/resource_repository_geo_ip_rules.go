@@ -2,10 +2,13 @@ package cloudsmith
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/netip"
 	"strings"
 
 	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -94,8 +97,8 @@ func resourceRepositoryGeoIpRulesUpdate(d *schema.ResourceData, m interface{}) e
 			Deny:  expandStrings(d, CountryCodeDeny),
 		},
 		Cidr: cloudsmith.RepositoryGeoIpCidr{
-			Allow: expandStrings(d, CidrAllow),
-			Deny:  expandStrings(d, CidrDeny),
+			Allow: expandGeoIpCidrs(d, CidrAllow),
+			Deny:  expandGeoIpCidrs(d, CidrDeny),
 		},
 	}
 
@@ -176,6 +179,132 @@ func resourceRepositoryGeoIpRulesDelete(d *schema.ResourceData, m interface{}) e
 	return nil
 }
 
+// normalizeGeoIpCidr returns the canonical form of a CIDR, with host bits
+// cleared. Values that don't parse are returned unchanged.
+func normalizeGeoIpCidr(cidr string) string {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return cidr
+	}
+	return prefix.Masked().String()
+}
+
+func expandGeoIpCidrs(d *schema.ResourceData, key string) []string {
+	cidrs := expandStrings(d, key)
+	for i, cidr := range cidrs {
+		cidrs[i] = normalizeGeoIpCidr(cidr)
+	}
+	return cidrs
+}
+
+// hashGeoIpCidr hashes the canonical form of a CIDR, so entries that only
+// differ in host bits are treated as the same set element.
+func hashGeoIpCidr(v interface{}) int {
+	return schema.HashString(normalizeGeoIpCidr(v.(string)))
+}
+
+func validateGeoIpCidr(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	prefix, err := netip.ParsePrefix(v)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %q is not a valid IPv4 or IPv6 CIDR: %w", k, v, err)}
+	}
+	if normalized := prefix.Masked().String(); normalized != v {
+		return []string{fmt.Sprintf("%s: %q will be sent to the API as %q", k, v, normalized)}, nil
+	}
+	return nil, nil
+}
+
+// geoIpCidrEntry is a configured CIDR along with its parsed prefix.
+type geoIpCidrEntry struct {
+	value  string
+	prefix netip.Prefix
+}
+
+// geoIpCidrEntries returns the parsable CIDRs configured for key. Invalid
+// entries are left to validateGeoIpCidr to report.
+func geoIpCidrEntries(d *schema.ResourceDiff, key string) []geoIpCidrEntry {
+	var entries []geoIpCidrEntry
+	for _, v := range d.Get(key).(*schema.Set).List() {
+		prefix, err := netip.ParsePrefix(v.(string))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, geoIpCidrEntry{value: v.(string), prefix: prefix.Masked()})
+	}
+	return entries
+}
+
+// geoIpRuleError returns an error for the element value of the set attribute
+// key, naming both so that it is reported against that element.
+func geoIpRuleError(key, value, format string, args ...interface{}) error {
+	path := cty.GetAttrPath(key).Index(cty.StringVal(value))
+	return path.NewErrorf("%s: %q %s", key, value, fmt.Sprintf(format, args...))
+}
+
+// prefixContains reports whether inner lies entirely within outer.
+func prefixContains(outer, inner netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// customizeDiffGeoIpRules rejects rules that can never take effect: CIDRs
+// contained in another entry of the same list, and allow entries that are
+// overridden by a deny entry, since deny rules take precedence. A deny entry
+// within a larger allow entry is a normal exception and is left alone.
+func customizeDiffGeoIpRules(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	var errs []error
+
+	if d.NewValueKnown(CidrAllow) && d.NewValueKnown(CidrDeny) {
+		allow := geoIpCidrEntries(d, CidrAllow)
+		deny := geoIpCidrEntries(d, CidrDeny)
+
+		for _, list := range []struct {
+			key     string
+			entries []geoIpCidrEntry
+		}{{CidrAllow, allow}, {CidrDeny, deny}} {
+			for _, inner := range list.entries {
+				for _, outer := range list.entries {
+					if inner.prefix != outer.prefix && prefixContains(outer.prefix, inner.prefix) {
+						errs = append(errs, geoIpRuleError(list.key, inner.value, "is redundant, it is contained in %q", outer.value))
+					}
+				}
+			}
+		}
+
+		for _, a := range allow {
+			for _, dn := range deny {
+				if prefixContains(dn.prefix, a.prefix) {
+					errs = append(errs, geoIpRuleError(CidrAllow, a.value, "has no effect, it is contained in %s entry %q and deny rules take precedence", CidrDeny, dn.value))
+				}
+			}
+		}
+	}
+
+	if d.NewValueKnown(CountryCodeAllow) && d.NewValueKnown(CountryCodeDeny) {
+		deny := make(map[string]string)
+		for _, v := range d.Get(CountryCodeDeny).(*schema.Set).List() {
+			deny[strings.ToUpper(v.(string))] = v.(string)
+		}
+		for _, v := range d.Get(CountryCodeAllow).(*schema.Set).List() {
+			if dn, ok := deny[strings.ToUpper(v.(string))]; ok {
+				errs = append(errs, geoIpRuleError(CountryCodeAllow, v.(string), "has no effect, it is also listed in %s as %q and deny rules take precedence", CountryCodeDeny, dn))
+			}
+		}
+	}
+
+	// A single error keeps its attribute path, so Terraform shows it against
+	// the offending element. Several are joined, each still naming its
+	// attribute and value.
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
 //nolint:funlen
 func resourceRepositoryGeoIpRules() *schema.Resource {
 	return &schema.Resource{
@@ -188,41 +317,45 @@ func resourceRepositoryGeoIpRules() *schema.Resource {
 			StateContext: importRepositoryGeoIpRules,
 		},
 
+		CustomizeDiff: customizeDiffGeoIpRules,
+
 		Schema: map[string]*schema.Schema{
 			CidrAllow: {
 				Type:        schema.TypeSet,
-				Description: "The list of IP Addresses for which to allow access, expressed in CIDR notation.",
+				Description: "The list of IP Addresses for which to allow access, expressed in IPv4 or IPv6 CIDR notation. Host bits are cleared before the rules are sent to the API.",
 				Optional:    true,
+				Set:         hashGeoIpCidr,
 				Elem: &schema.Schema{
 					Type:         schema.TypeString,
-					ValidateFunc: validation.StringIsNotEmpty,
+					ValidateFunc: validateGeoIpCidr,
 				},
 			},
 			CidrDeny: {
 				Type:        schema.TypeSet,
-				Description: "The list of IP Addresses for which to deny access, expressed in CIDR notation.",
+				Description: "The list of IP Addresses for which to deny access, expressed in IPv4 or IPv6 CIDR notation. Host bits are cleared before the rules are sent to the API.",
 				Optional:    true,
+				Set:         hashGeoIpCidr,
 				Elem: &schema.Schema{
 					Type:         schema.TypeString,
-					ValidateFunc: validation.StringIsNotEmpty,
+					ValidateFunc: validateGeoIpCidr,
 				},
 			},
 			CountryCodeAllow: {
 				Type:        schema.TypeSet,
-				Description: "The list of countries for which to allow access, expressed in ISO 3166-1 country codes.",
+				Description: "The list of countries for which to allow access, expressed in ISO 3166-1 alpha-2 country codes.",
 				Optional:    true,
 				Elem: &schema.Schema{
 					Type:         schema.TypeString,
-					ValidateFunc: validation.StringIsNotEmpty,
+					ValidateFunc: validation.StringInSlice(iso3166Alpha2, true),
 				},
 			},
 			CountryCodeDeny: {
 				Type:        schema.TypeSet,
-				Description: "The list of countries for which to deny access, expressed in ISO 3166-1 country codes.",
+				Description: "The list of countries for which to deny access, expressed in ISO 3166-1 alpha-2 country codes.",
 				Optional:    true,
 				Elem: &schema.Schema{
 					Type:         schema.TypeString,
-					ValidateFunc: validation.StringIsNotEmpty,
+					ValidateFunc: validation.StringInSlice(iso3166Alpha2, true),
 				},
 			},
 			Namespace: {
@@ -2,9 +2,13 @@
 package cloudsmith
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -68,6 +72,128 @@ func TestAccRepositoryGeoIpRules_basic(t *testing.T) {
 	})
 }
 
+func TestValidateGeoIpCidr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		warning bool
+		err     bool
+	}{
+		{value: "10.0.0.0/8"},
+		{value: "2001:db8::/32"},
+		{value: "10.1.2.3/8", warning: true},
+		{value: "2001:DB8::/32", warning: true},
+		{value: "10.0.0.1", err: true},
+		{value: "10.0.0.0/33", err: true},
+		{value: "not-a-cidr", err: true},
+	}
+
+	for _, tc := range tests {
+		warnings, errs := validateGeoIpCidr(tc.value, CidrAllow)
+		if (len(warnings) > 0) != tc.warning {
+			t.Errorf("%q: unexpected warnings: %v", tc.value, warnings)
+		}
+		if (len(errs) > 0) != tc.err {
+			t.Errorf("%q: unexpected errors: %v", tc.value, errs)
+		}
+	}
+
+	if got := normalizeGeoIpCidr("10.1.2.3/8"); got != "10.0.0.0/8" {
+		t.Errorf("unexpected normalized CIDR: %q", got)
+	}
+}
+
+func TestCustomizeDiffGeoIpRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		errors []string
+		path   cty.Path
+	}{
+		{
+			name: "deny exception within allow",
+			config: map[string]interface{}{
+				CidrAllow:        []interface{}{"10.0.0.0/8", "2001:db8::/32"},
+				CidrDeny:         []interface{}{"10.1.0.0/16"},
+				CountryCodeAllow: []interface{}{"GB"},
+				CountryCodeDeny:  []interface{}{"FR"},
+			},
+		},
+		{
+			name: "redundant cidr",
+			config: map[string]interface{}{
+				CidrAllow: []interface{}{"10.0.0.0/8", "10.1.0.0/16"},
+			},
+			errors: []string{`cidr_allow: "10.1.0.0/16" is redundant, it is contained in "10.0.0.0/8"`},
+			path:   cty.GetAttrPath(CidrAllow).Index(cty.StringVal("10.1.0.0/16")),
+		},
+		{
+			name: "allow within deny",
+			config: map[string]interface{}{
+				CidrAllow: []interface{}{"192.168.1.0/24"},
+				CidrDeny:  []interface{}{"192.168.0.0/16"},
+			},
+			errors: []string{`cidr_allow: "192.168.1.0/24" has no effect, it is contained in cidr_deny entry "192.168.0.0/16"`},
+		},
+		{
+			name: "country in both lists",
+			config: map[string]interface{}{
+				CountryCodeAllow: []interface{}{"gb"},
+				CountryCodeDeny:  []interface{}{"GB"},
+			},
+			errors: []string{`country_code_allow: "gb" has no effect, it is also listed in country_code_deny as "GB"`},
+			path:   cty.GetAttrPath(CountryCodeAllow).Index(cty.StringVal("gb")),
+		},
+		{
+			name: "several problems",
+			config: map[string]interface{}{
+				CidrAllow:        []interface{}{"10.0.0.0/8", "10.1.0.0/16"},
+				CountryCodeAllow: []interface{}{"FR"},
+				CountryCodeDeny:  []interface{}{"FR"},
+			},
+			errors: []string{
+				`cidr_allow: "10.1.0.0/16" is redundant`,
+				`country_code_allow: "FR" has no effect`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.config[Namespace] = "example-org"
+			tc.config[Repository] = "example-repo"
+
+			_, err := resourceRepositoryGeoIpRules().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(tc.config), nil)
+			if len(tc.errors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tc.errors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q, got: %v", want, err)
+				}
+			}
+			if tc.path != nil {
+				var pathErr cty.PathError
+				if !errors.As(err, &pathErr) || !pathErr.Path.Equals(tc.path) {
+					t.Errorf("expected error to be reported against %#v, got: %#v", tc.path, err)
+				}
+			}
+		})
+	}
+}
+
 func testAccRepositoryGeoIpRulesConfigDefault(repositoryName string) string {
 	return fmt.Sprintf(`
 resource "cloudsmith_repository" "test" {
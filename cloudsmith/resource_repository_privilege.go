@@ -0,0 +1,435 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	privilegeTypeService = "service"
+	privilegeTypeTeam    = "team"
+	privilegeTypeUser    = "user"
+)
+
+var (
+	// repositoryPrivilegeDeleteAttempts is how many times Delete re-reads the
+	// privileges when they change between reads before giving up.
+	repositoryPrivilegeDeleteAttempts = 3
+
+	repositoryPrivilegeTypes = []string{
+		privilegeTypeService,
+		privilegeTypeTeam,
+		privilegeTypeUser,
+	}
+
+	// repositoryPrivilegeLocks serializes read-modify-write cycles against the
+	// privileges of a single repository, so that several
+	// cloudsmith_repository_privilege resources for the same repository don't
+	// drop each other's grants when they are applied in parallel.
+	repositoryPrivilegeLocks sync.Map
+)
+
+func lockRepositoryPrivileges(organization, repository string) func() {
+	lock, _ := repositoryPrivilegeLocks.LoadOrStore(organization+"."+repository, &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// privilegeMatches returns true if the privilege entry grants access to the
+// principal identified by principalType and slug.
+func privilegeMatches(p cloudsmith.RepositoryPrivilegeDict, principalType, slug string) bool {
+	switch principalType {
+	case privilegeTypeService:
+		return p.HasService() && p.GetService() == slug
+	case privilegeTypeTeam:
+		return p.HasTeam() && p.GetTeam() == slug
+	case privilegeTypeUser:
+		return p.HasUser() && p.GetUser() == slug
+	}
+	return false
+}
+
+// findRepositoryPrivilege returns the privilege entry for a single principal.
+func findRepositoryPrivilege(privileges []cloudsmith.RepositoryPrivilegeDict, principalType, slug string) (cloudsmith.RepositoryPrivilegeDict, bool) {
+	for _, p := range privileges {
+		if privilegeMatches(p, principalType, slug) {
+			return p, true
+		}
+	}
+	return cloudsmith.RepositoryPrivilegeDict{}, false
+}
+
+// repositoryPrivilegesEqual returns true if a and b contain the same grants,
+// in any order.
+func repositoryPrivilegesEqual(a, b []cloudsmith.RepositoryPrivilegeDict) bool {
+	key := func(p cloudsmith.RepositoryPrivilegeDict) string {
+		return fmt.Sprintf("%s|%s|%s|%s", p.GetUser(), p.GetService(), p.GetTeam(), p.GetPrivilege())
+	}
+	x := make([]string, len(a))
+	for i, p := range a {
+		x[i] = key(p)
+	}
+	y := make([]string, len(b))
+	for i, p := range b {
+		y[i] = key(p)
+	}
+	return stringSlicesAreEqual(x, y, true)
+}
+
+func newRepositoryPrivilege(principalType, slug, privilege string) cloudsmith.RepositoryPrivilegeDict {
+	p := cloudsmith.RepositoryPrivilegeDict{}
+	p.SetPrivilege(privilege)
+	switch principalType {
+	case privilegeTypeService:
+		p.SetService(slug)
+	case privilegeTypeTeam:
+		p.SetTeam(slug)
+	case privilegeTypeUser:
+		p.SetUser(slug)
+	}
+	return p
+}
+
+func importRepositoryPrivilege(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idParts := strings.Split(d.Id(), ".")
+	if len(idParts) != 4 {
+		return nil, fmt.Errorf(
+			"invalid import ID, must be of the form <organization_slug>.<repository_slug>.<type>.<slug>, got: %s", d.Id(),
+		)
+	}
+	if !contains(repositoryPrivilegeTypes, idParts[2]) {
+		return nil, fmt.Errorf("invalid import ID, type must be one of %s, got: %s", strings.Join(repositoryPrivilegeTypes, ", "), idParts[2])
+	}
+
+	d.Set("organization", idParts[0])
+	d.Set("repository", idParts[1])
+	d.Set("type", idParts[2])
+	d.Set("slug", idParts[3])
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceRepositoryPrivilegeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	repository := requiredString(d, "repository")
+	principalType := requiredString(d, "type")
+	slug := requiredString(d, "slug")
+
+	unlock := lockRepositoryPrivileges(organization, repository)
+	defer unlock()
+
+	privileges, notFound, err := retrieveRepositoryPrivilegePages(pc, organization, repository)
+	if err != nil {
+		return diag.Errorf("error retrieving repository privileges: %s", err)
+	}
+	if notFound {
+		return diag.Errorf("repository %s.%s not found", organization, repository)
+	}
+
+	// Refuse to take over a grant that already exists. It is either managed by
+	// an authoritative cloudsmith_repository_privileges resource, in which case
+	// the two resources would keep overwriting each other, or it was created
+	// outside Terraform and should be imported instead.
+	if existing, ok := findRepositoryPrivilege(privileges, principalType, slug); ok {
+		return diag.Errorf(
+			"repository_privilege (%s.%s): %s '%s' already has %s privilege on this repository; "+
+				"import it as %s.%s.%s.%s, or remove it from any cloudsmith_repository_privileges resource managing this repository",
+			organization, repository, principalType, slug, existing.GetPrivilege(),
+			organization, repository, principalType, slug,
+		)
+	}
+
+	if err := patchRepositoryPrivilege(pc, organization, repository, principalType, slug, requiredString(d, "privilege")); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s.%s.%s", organization, repository, principalType, slug))
+
+	return resourceRepositoryPrivilegeRead(ctx, d, m)
+}
+
+// resourceRepositoryPrivilegeRead reads the grant, warning if it was removed
+// outside this resource.
+func resourceRepositoryPrivilegeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	id := d.Id()
+	removed, err := readRepositoryPrivilege(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !removed {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Repository privilege (%s) was removed outside of this resource", id),
+		Detail: fmt.Sprintf("The %s '%s' no longer has a grant on repository %s.%s, so it will be granted again on the next apply. "+
+			"If a cloudsmith_repository_privileges resource manages this repository, it removes every grant it doesn't list, "+
+			"and the two resources will keep undoing each other. Add the grant to that resource instead.",
+			requiredString(d, "type"), requiredString(d, "slug"), requiredString(d, "organization"), requiredString(d, "repository")),
+	}}
+}
+
+// readRepositoryPrivilege reads the grant, reporting whether it no longer
+// exists while its repository still does.
+func readRepositoryPrivilege(d *schema.ResourceData, m interface{}) (bool, error) {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	repository := requiredString(d, "repository")
+	principalType := requiredString(d, "type")
+	slug := requiredString(d, "slug")
+
+	privileges, notFound, err := retrieveRepositoryPrivilegePages(pc, organization, repository)
+	if err != nil {
+		return false, err
+	}
+	if notFound {
+		d.SetId("")
+		return false, nil
+	}
+
+	privilege, ok := findRepositoryPrivilege(privileges, principalType, slug)
+	if !ok {
+		d.SetId("")
+		return true, nil
+	}
+
+	d.Set("privilege", privilege.GetPrivilege())
+
+	// organization, repository, type and slug are not returned from the
+	// privileges read endpoint as separate fields, so we use the values stored
+	// in resource state. We rely on ForceNew to ensure if any of them change a
+	// new resource is created.
+	d.Set("organization", organization)
+	d.Set("repository", repository)
+	d.Set("type", principalType)
+	d.Set("slug", slug)
+
+	return false, nil
+}
+
+func resourceRepositoryPrivilegeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	repository := requiredString(d, "repository")
+
+	unlock := lockRepositoryPrivileges(organization, repository)
+	defer unlock()
+
+	err := patchRepositoryPrivilege(
+		pc, organization, repository,
+		requiredString(d, "type"), requiredString(d, "slug"), requiredString(d, "privilege"),
+	)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceRepositoryPrivilegeRead(ctx, d, m)
+}
+
+// otherRepositoryPrivileges returns privileges without the entry of the
+// principal identified by principalType and slug.
+func otherRepositoryPrivileges(privileges []cloudsmith.RepositoryPrivilegeDict, principalType, slug string) []cloudsmith.RepositoryPrivilegeDict {
+	others := make([]cloudsmith.RepositoryPrivilegeDict, 0, len(privileges))
+	for _, p := range privileges {
+		if !privilegeMatches(p, principalType, slug) {
+			others = append(others, p)
+		}
+	}
+	return others
+}
+
+func resourceRepositoryPrivilegeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	repository := requiredString(d, "repository")
+	principalType := requiredString(d, "type")
+	slug := requiredString(d, "slug")
+
+	if principalType != privilegeTypeTeam {
+		userReq := pc.APIClient.UserApi.UserSelf(pc.Auth)
+		userSelf, _, err := pc.APIClient.UserApi.UserSelfExecute(userReq)
+		if err != nil {
+			return diag.Errorf("error retrieving authenticated account while deleting repository privilege: %s", err)
+		}
+		if userSelf.GetSlug() == slug {
+			// Same lockout prevention as cloudsmith_repository_privileges: never
+			// revoke the authenticated account's own access.
+			log.Printf("[WARN] repository_privilege (%s): not revoking the authenticated account's own grant, removing it from state only", d.Id())
+			return nil
+		}
+	}
+
+	unlock := lockRepositoryPrivileges(organization, repository)
+	defer unlock()
+
+	privileges, notFound, err := retrieveRepositoryPrivilegePages(pc, organization, repository)
+	if err != nil {
+		return diag.Errorf("error retrieving repository privileges before deletion: %s", err)
+	}
+	if notFound {
+		return nil
+	}
+
+	// The lock above only covers this provider, so read the privileges again
+	// right before writing and start over if another client changed them, to
+	// avoid dropping grants it added.
+	for attempt := 1; ; attempt++ {
+		current, notFound, err := retrieveRepositoryPrivilegePages(pc, organization, repository)
+		if err != nil {
+			return diag.Errorf("error retrieving repository privileges before deletion: %s", err)
+		}
+		if notFound {
+			return nil
+		}
+		if repositoryPrivilegesEqual(privileges, current) {
+			break
+		}
+		if attempt == repositoryPrivilegeDeleteAttempts {
+			return diag.Errorf("repository_privilege (%s): privileges of repository %s.%s kept changing while deleting, try again", d.Id(), organization, repository)
+		}
+		privileges = current
+	}
+
+	// The partial update endpoint can only add or change grants, so removal
+	// replaces the full list with everything except this principal's entry.
+	remaining := otherRepositoryPrivileges(privileges, principalType, slug)
+	if len(remaining) == len(privileges) {
+		return nil
+	}
+
+	req := pc.APIClient.ReposApi.ReposPrivilegesUpdate(pc.Auth, organization, repository)
+	req = req.Data(cloudsmith.RepositoryPrivilegeInputRequest{
+		Privileges: remaining,
+	})
+	if _, err := pc.APIClient.ReposApi.ReposPrivilegesUpdateExecute(req); err != nil {
+		return diag.FromErr(formatAPIError(err))
+	}
+
+	var after []cloudsmith.RepositoryPrivilegeDict
+	checkerFunc := func() error {
+		privileges, notFound, err := retrieveRepositoryPrivilegePages(pc, organization, repository)
+		if err != nil {
+			return err
+		}
+		if notFound {
+			return nil
+		}
+		if _, ok := findRepositoryPrivilege(privileges, principalType, slug); ok {
+			return errKeepWaiting
+		}
+		after = privileges
+		return nil
+	}
+	if err := waiter(checkerFunc, defaultUpdateTimeout, defaultUpdateInterval); err != nil {
+		return diag.Errorf("error waiting for privilege (%s) to be deleted: %s", d.Id(), err)
+	}
+
+	// The write replaced the full list, so a grant changed by another client
+	// between the last read and the write was overwritten. Compare the list
+	// read back afterwards with what was written to surface it.
+	if after != nil && !repositoryPrivilegesEqual(remaining, after) {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Privileges of repository %s.%s changed while deleting repository privilege (%s)", organization, repository, d.Id()),
+			Detail: "Removing a grant replaces the repository's full privilege list, and another client changed the list at about the same time. " +
+				"Check the repository's privileges, as a grant it added or changed may have been overwritten.",
+		}}
+	}
+
+	return nil
+}
+
+// patchRepositoryPrivilege adds or changes the grant for a single principal
+// without touching any other entries, then waits for it to become visible.
+func patchRepositoryPrivilege(pc *providerConfig, organization, repository, principalType, slug, privilege string) error {
+	req := pc.APIClient.ReposApi.ReposPrivilegesPartialUpdate(pc.Auth, organization, repository)
+	req = req.Data(cloudsmith.RepositoryPrivilegeInputRequestPatch{
+		Privileges: []cloudsmith.RepositoryPrivilegeDict{newRepositoryPrivilege(principalType, slug, privilege)},
+	})
+	if _, err := pc.APIClient.ReposApi.ReposPrivilegesPartialUpdateExecute(req); err != nil {
+		return formatAPIError(err)
+	}
+
+	checkerFunc := func() error {
+		privileges, notFound, err := retrieveRepositoryPrivilegePages(pc, organization, repository)
+		if err != nil {
+			return err
+		}
+		if notFound {
+			return errKeepWaiting
+		}
+		if p, ok := findRepositoryPrivilege(privileges, principalType, slug); !ok || p.GetPrivilege() != privilege {
+			return errKeepWaiting
+		}
+		return nil
+	}
+	if err := waiter(checkerFunc, defaultUpdateTimeout, defaultUpdateInterval); err != nil {
+		return fmt.Errorf("error waiting for privilege (%s.%s.%s.%s) to be updated: %w", organization, repository, principalType, slug, err)
+	}
+
+	return nil
+}
+
+func resourceRepositoryPrivilege() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRepositoryPrivilegeCreate,
+		ReadContext:   resourceRepositoryPrivilegeRead,
+		UpdateContext: resourceRepositoryPrivilegeUpdate,
+		DeleteContext: resourceRepositoryPrivilegeDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importRepositoryPrivilege,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Type:         schema.TypeString,
+				Description:  "Organization to which this repository belongs.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"repository": {
+				Type:         schema.TypeString,
+				Description:  "Repository to which this privilege belongs.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Description:  "The kind of principal the privilege is granted to: user, service or team.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(repositoryPrivilegeTypes, false),
+			},
+			"slug": {
+				Type:         schema.TypeString,
+				Description:  "The slug of the user, service or team the privilege is granted to.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"privilege": {
+				Type:         schema.TypeString,
+				Description:  "The privilege level granted to the principal.",
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(repositoryPrivileges, false),
+			},
+		},
+	}
+}
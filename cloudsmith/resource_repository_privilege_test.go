@@ -0,0 +1,310 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// fakePrivilegesServer serves a single repository's privilege list and
+// records the write requests made against it.
+type fakePrivilegesServer struct {
+	mu         sync.Mutex
+	privileges []map[string]string
+	puts       int
+	patches    int
+	gets       int
+	// afterGet and afterPut, if set, are called after each list and full
+	// update request, to simulate another client changing the privileges
+	// between requests.
+	afterGet func(f *fakePrivilegesServer)
+	afterPut func(f *fakePrivilegesServer)
+}
+
+func (f *fakePrivilegesServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+	case path == "/user/self":
+		fmt.Fprint(w, `{"slug":"terraform-sa","slug_perm":"terraform-sa"}`)
+	case path == "/repos/example-org/example-repo/privileges":
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"privileges": f.privileges})
+			f.gets++
+			if f.afterGet != nil {
+				f.afterGet(f)
+			}
+		case http.MethodPut, http.MethodPatch:
+			var body struct {
+				Privileges []map[string]string `json:"privileges"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if r.Method == http.MethodPut {
+				f.puts++
+				f.privileges = body.Privileges
+				if f.afterPut != nil {
+					f.afterPut(f)
+				}
+			} else {
+				f.patches++
+				for _, p := range body.Privileges {
+					f.privileges = upsertFakePrivilege(f.privileges, p)
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		http.Error(w, "unexpected request", http.StatusNotFound)
+	}
+}
+
+func upsertFakePrivilege(privileges []map[string]string, p map[string]string) []map[string]string {
+	for i, existing := range privileges {
+		if existing["user"] == p["user"] && existing["service"] == p["service"] && existing["team"] == p["team"] {
+			privileges[i] = p
+			return privileges
+		}
+	}
+	return append(privileges, p)
+}
+
+func testRepositoryPrivilegeResourceData(t *testing.T, principalType, slug, privilege string) *schema.ResourceData {
+	t.Helper()
+
+	return schema.TestResourceDataRaw(t, resourceRepositoryPrivilege().Schema, map[string]interface{}{
+		"organization": "example-org",
+		"repository":   "example-repo",
+		"type":         principalType,
+		"slug":         slug,
+		"privilege":    privilege,
+	})
+}
+
+func TestRepositoryPrivilege_CreateAndDeleteOnlyTouchOwnEntry(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakePrivilegesServer{privileges: []map[string]string{
+		{"privilege": "Admin", "user": "terraform-sa"},
+		{"privilege": "Write", "team": "platform"},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	d := testRepositoryPrivilegeResourceData(t, privilegeTypeService, "ci-bot", "Read")
+	if diags := resourceRepositoryPrivilegeCreate(context.Background(), d, pc); diags.HasError() {
+		t.Fatalf("create: unexpected error: %v", diags)
+	}
+	if d.Id() != "example-org.example-repo.service.ci-bot" {
+		t.Fatalf("unexpected ID %q", d.Id())
+	}
+	if fake.patches != 1 || fake.puts != 0 {
+		t.Fatalf("expected create to issue a single partial update, got %d patches and %d puts", fake.patches, fake.puts)
+	}
+	if len(fake.privileges) != 3 {
+		t.Fatalf("expected 3 privileges after create, got %v", fake.privileges)
+	}
+
+	if diags := resourceRepositoryPrivilegeDelete(context.Background(), d, pc); len(diags) != 0 {
+		t.Fatalf("delete: unexpected diagnostics: %v", diags)
+	}
+	if len(fake.privileges) != 2 {
+		t.Fatalf("expected delete to remove only the managed entry, got %v", fake.privileges)
+	}
+	for _, p := range fake.privileges {
+		if p["service"] == "ci-bot" {
+			t.Fatalf("managed entry still present after delete: %v", fake.privileges)
+		}
+	}
+}
+
+func TestRepositoryPrivilege_CreateConflictsWithExistingGrant(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakePrivilegesServer{privileges: []map[string]string{
+		{"privilege": "Write", "team": "platform"},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := testRepositoryPrivilegeResourceData(t, privilegeTypeTeam, "platform", "Read")
+	diags := resourceRepositoryPrivilegeCreate(context.Background(), d, testPrivilegesProviderConfig(server))
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "already has Write privilege") {
+		t.Fatalf("expected conflict error, got %v", diags)
+	}
+	if fake.patches != 0 || fake.puts != 0 {
+		t.Fatalf("expected no writes on conflict, got %d patches and %d puts", fake.patches, fake.puts)
+	}
+}
+
+func TestRepositoryPrivilege_DeleteKeepsAuthenticatedAccount(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakePrivilegesServer{privileges: []map[string]string{
+		{"privilege": "Admin", "user": "terraform-sa"},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := testRepositoryPrivilegeResourceData(t, privilegeTypeUser, "terraform-sa", "Admin")
+	d.SetId("example-org.example-repo.user.terraform-sa")
+	if diags := resourceRepositoryPrivilegeDelete(context.Background(), d, testPrivilegesProviderConfig(server)); len(diags) != 0 {
+		t.Fatalf("delete: unexpected diagnostics: %v", diags)
+	}
+	if fake.puts != 0 || len(fake.privileges) != 1 {
+		t.Fatalf("expected the authenticated account's grant to be kept, got %v", fake.privileges)
+	}
+}
+
+func TestRepositoryPrivilege_ReadWarnsWhenGrantRemoved(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakePrivilegesServer{privileges: []map[string]string{
+		{"privilege": "Admin", "user": "terraform-sa"},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := testRepositoryPrivilegeResourceData(t, privilegeTypeTeam, "platform", "Read")
+	d.SetId("example-org.example-repo.team.platform")
+	diags := resourceRepositoryPrivilegeRead(context.Background(), d, testPrivilegesProviderConfig(server))
+	if diags.HasError() {
+		t.Fatalf("read: unexpected error: %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Detail, "cloudsmith_repository_privileges") {
+		t.Fatalf("expected a warning naming the conflicting resource, got %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected the removed grant to be dropped from state, got ID %q", d.Id())
+	}
+}
+
+func TestRepositoryPrivilege_DeleteKeepsConcurrentlyAddedGrant(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakePrivilegesServer{privileges: []map[string]string{
+		{"privilege": "Admin", "user": "terraform-sa"},
+		{"privilege": "Read", "service": "ci-bot"},
+	}}
+	// Another client adds a grant right after the first read.
+	fake.afterGet = func(f *fakePrivilegesServer) {
+		if f.gets == 1 {
+			f.privileges = append(f.privileges, map[string]string{"privilege": "Write", "team": "hr-sync"})
+		}
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := testRepositoryPrivilegeResourceData(t, privilegeTypeService, "ci-bot", "Read")
+	d.SetId("example-org.example-repo.service.ci-bot")
+	if diags := resourceRepositoryPrivilegeDelete(context.Background(), d, testPrivilegesProviderConfig(server)); len(diags) != 0 {
+		t.Fatalf("delete: unexpected diagnostics: %v", diags)
+	}
+	if len(fake.privileges) != 2 || fake.privileges[1]["team"] != "hr-sync" {
+		t.Fatalf("expected the concurrently added grant to be kept, got %v", fake.privileges)
+	}
+}
+
+func TestRepositoryPrivilege_DeleteWarnsAboutConcurrentWrite(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakePrivilegesServer{privileges: []map[string]string{
+		{"privilege": "Admin", "user": "terraform-sa"},
+		{"privilege": "Read", "service": "ci-bot"},
+	}}
+	// Another client adds a grant right after the full update.
+	fake.afterPut = func(f *fakePrivilegesServer) {
+		f.privileges = append(f.privileges, map[string]string{"privilege": "Write", "team": "hr-sync"})
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := testRepositoryPrivilegeResourceData(t, privilegeTypeService, "ci-bot", "Read")
+	d.SetId("example-org.example-repo.service.ci-bot")
+	diags := resourceRepositoryPrivilegeDelete(context.Background(), d, testPrivilegesProviderConfig(server))
+	if diags.HasError() {
+		t.Fatalf("delete: unexpected error: %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Summary, "changed while deleting") {
+		t.Fatalf("expected a warning about the concurrent change, got %v", diags)
+	}
+}
+
+// TestAccRepositoryPrivilege_basic grants a service Read access to a
+// repository, changes it to Write and verifies the grant can be imported.
+func TestAccRepositoryPrivilege_basic(t *testing.T) {
+	t.Parallel()
+
+	repositoryName := testAccUniqueRepositoryName("terraform-acc-test-priv")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccRepositoryCheckDestroy("cloudsmith_repository.test"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRepositoryPrivilegeConfig(repositoryName, "Read"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsmith_repository_privilege.test", "privilege", "Read"),
+					resource.TestCheckResourceAttr("cloudsmith_repository_privilege.test", "type", "service"),
+				),
+			},
+			{
+				Config: testAccRepositoryPrivilegeConfig(repositoryName, "Write"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsmith_repository_privilege.test", "privilege", "Write"),
+				),
+			},
+			{
+				ResourceName: "cloudsmith_repository_privilege.test",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					resourceState := s.RootModule().Resources["cloudsmith_repository_privilege.test"]
+					return resourceState.Primary.ID, nil
+				},
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccRepositoryPrivilegeConfig(repositoryName, privilege string) string {
+	return fmt.Sprintf(`
+resource "cloudsmith_repository" "test" {
+	name      = "%s"
+	namespace = "%s"
+}
+
+resource "cloudsmith_service" "test" {
+	name         = "TF Test Service Priv"
+	organization = cloudsmith_repository.test.namespace
+	role         = "Member"
+}
+
+resource "cloudsmith_repository_privilege" "test" {
+	organization = cloudsmith_repository.test.namespace
+	repository   = cloudsmith_repository.test.slug
+	type         = "service"
+	slug         = cloudsmith_service.test.slug
+	privilege    = "%s"
+}
+`, repositoryName, os.Getenv("CLOUDSMITH_NAMESPACE"), privilege)
+}
@@ -2,13 +2,16 @@ package cloudsmith
 
 import (
 	"context"
+	"crypto"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -60,8 +63,36 @@ const (
 	VerifySsl            = "verify_ssl"
 	AuthCertificateKey   = "auth_certificate_key"
 	AuthCertificate      = "auth_certificate"
+
+	UpstreamTrust             = "trust"
+	UpstreamPendingValidation = "pending_validation"
+	UpstreamCache             = "cache"
+
+	AuthSecretWo         = "auth_secret_wo"
+	AuthSecretWoVersion  = "auth_secret_wo_version"
+	ExtraValue1Wo        = "extra_value_1_wo"
+	ExtraValue1WoVersion = "extra_value_1_wo_version"
+	ExtraValue2Wo        = "extra_value_2_wo"
+	ExtraValue2WoVersion = "extra_value_2_wo_version"
+
+	DisableReason       = "disable_reason"
+	ReplacedUpstream    = "replaced_upstream"
+	HealthState         = "health_state"
+	LastSuccessfulFetch = "last_successful_fetch"
+	WaitForActive       = "wait_for_active"
+)
+
+// upstream health states
+const (
+	upstreamHealthActive   = "active"
+	upstreamHealthPending  = "pending"
+	upstreamHealthDisabled = "disabled"
 )
 
+// upstreamActivationTimeout bounds how long applies wait for an upstream to
+// become active.
+const upstreamActivationTimeout = 5 * time.Minute
+
 var (
 	authModes = []string{
 		"None",
@@ -74,33 +105,15 @@ var (
 		"Cache and Proxy",
 		"Cache Only",
 	}
-	upstreamTypes = []string{
-		Alpine,
-		Cargo,
-		Composer,
-		Conda,
-		Cran,
-		Dart,
-		Deb,
-		Docker,
-		Generic,
-		Go,
-		Helm,
-		Hex,
-		HuggingFace,
-		Maven,
-		Npm,
-		NuGet,
-		Python,
-		Rpm,
-		Ruby,
-		Swift,
+	upstreamTrustLevels = []string{
+		"Trusted",
+		"Untrusted",
 	}
+	upstreamTypes = upstreamFormatTypes()
 )
 
 type Upstream interface {
 	GetAuthMode() string
-	GetAuthSecret() string
 	GetAuthUsername() string
 	GetExtraHeader1() string
 	GetExtraHeader2() string
@@ -130,6 +143,11 @@ func importUpstream(_ context.Context, d *schema.ResourceData, _ interface{}) ([
 	_ = d.Set(Namespace, idParts[0])
 	_ = d.Set(Repository, idParts[1])
 	_ = d.Set(UpstreamType, idParts[2])
+	// wait_for_active and certificate_expiry_warning_days aren't returned by
+	// the API, so start from their defaults to avoid planning a change after
+	// import.
+	_ = d.Set(WaitForActive, false)
+	_ = d.Set(CertificateExpiryWarningDays, defaultCertificateExpiryWarningDays)
 	d.SetId(idParts[3])
 	return []*schema.ResourceData{d}, nil
 }
@@ -162,6 +180,74 @@ func normalizeUpstreamDisableReason(reason string) string {
 	return reason
 }
 
+// upstreamFetchStatus is implemented by upstream models that report when
+// Cloudsmith last fetched from the upstream successfully.
+type upstreamFetchStatus interface {
+	GetLastSuccessfulFetchAtOk() (*time.Time, bool)
+}
+
+// upstreamLastSuccessfulFetch returns when the upstream was last fetched from
+// successfully. ok is false if the upstream's model doesn't report fetches.
+func upstreamLastSuccessfulFetch(upstream Upstream) (fetchedAt time.Time, ok bool) {
+	status, ok := upstream.(upstreamFetchStatus)
+	if !ok {
+		return time.Time{}, false
+	}
+	if t, set := status.GetLastSuccessfulFetchAtOk(); set && t != nil {
+		return *t, true
+	}
+	return time.Time{}, true
+}
+
+// upstreamHealthState summarises is_active and the disable reason: an inactive
+// upstream with a disable reason is disabled, one without is still pending
+// activation.
+func upstreamHealthState(upstream Upstream) string {
+	if upstream.GetIsActive() {
+		return upstreamHealthActive
+	}
+	if actionableUpstreamDisableReason(upstream.GetDisableReasonText(), upstream.GetDisableReason()) != "" {
+		return upstreamHealthDisabled
+	}
+	return upstreamHealthPending
+}
+
+// checkUpstreamReachable extends checkUpstreamActivation for wait_for_active:
+// where the API reports fetches, the upstream must also have been fetched from
+// successfully.
+func checkUpstreamReachable(upstream Upstream, slug string) error {
+	if err := checkUpstreamActivation(upstream, slug); err != nil {
+		return err
+	}
+	if fetchedAt, ok := upstreamLastSuccessfulFetch(upstream); ok && fetchedAt.IsZero() {
+		return errKeepWaiting
+	}
+	return nil
+}
+
+// waitForUpstreamActive polls the upstream until checker reports it is ready,
+// returning an upstreamActivationDisabledError if it is disabled meanwhile.
+func waitForUpstreamActive(d *schema.ResourceData, m interface{}, checker func(Upstream, string) error) error {
+	activeChecker := func() error {
+		upstream, resp, err := getUpstream(d, m)
+		if err != nil {
+			if is404(resp) {
+				return errKeepWaiting
+			}
+			return err
+		}
+		return checker(upstream, d.Id())
+	}
+	if err := waiter(activeChecker, upstreamActivationTimeout, 10*time.Second); err != nil {
+		var disabledErr upstreamActivationDisabledError
+		if errors.As(err, &disabledErr) {
+			return disabledErr
+		}
+		return fmt.Errorf("error waiting for upstream (%s) to become active: %w", d.Id(), err)
+	}
+	return nil
+}
+
 func checkUpstreamActivation(upstream Upstream, slug string) error {
 	if upstream.GetIsActive() {
 		return nil
@@ -176,34 +262,28 @@ func checkUpstreamActivation(upstream Upstream, slug string) error {
 }
 
 // readCertificateContent reads and validates certificate content
-func readCertificateContent(content string) error {
-	content = strings.TrimSpace(content)
-	if !strings.HasPrefix(content, "-----BEGIN CERTIFICATE-----") {
-		return fmt.Errorf("invalid certificate format: must be a PEM encoded certificate")
-	}
-	return nil
+func readCertificateContent(content string) (*x509.Certificate, error) {
+	return parseUpstreamCertificate(content)
 }
 
 // readPrivateKeyContent reads and validates private key content
-func readPrivateKeyContent(content string) error {
-	content = strings.TrimSpace(content)
-	if !strings.HasPrefix(content, "-----BEGIN") || !strings.Contains(content, "PRIVATE KEY-----") {
-		return fmt.Errorf("invalid private key format: must be a PEM encoded private key")
-	}
-	return nil
+func readPrivateKeyContent(content string) (crypto.Signer, error) {
+	return parseUpstreamPrivateKey(content)
 }
 
 // readCertificateFiles reads the certificate files and returns their contents
 func readCertificateFiles(d *schema.ResourceData) (cert, key *string, err error) {
+	var parsedCert *x509.Certificate
 	if certContent := optionalString(d, AuthCertificate); certContent != nil {
-		if err := readCertificateContent(*certContent); err != nil {
+		if parsedCert, err = readCertificateContent(*certContent); err != nil {
 			return nil, nil, err
 		}
 		cert = certContent
 	}
 
+	var parsedKey crypto.Signer
 	if keyContent := optionalString(d, AuthCertificateKey); keyContent != nil {
-		if err := readPrivateKeyContent(*keyContent); err != nil {
+		if parsedKey, err = readPrivateKeyContent(*keyContent); err != nil {
 			return nil, nil, err
 		}
 		key = keyContent
@@ -214,460 +294,70 @@ func readCertificateFiles(d *schema.ResourceData) (cert, key *string, err error)
 		return nil, nil, fmt.Errorf("both auth_certificate and auth_certificate_key must be provided when using Certificate and Key authentication")
 	}
 
+	if parsedCert != nil {
+		if err := checkUpstreamCertificateKeyPair(parsedCert, parsedKey); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	return cert, key, nil
 }
 
-func resourceRepositoryUpstreamCreate(d *schema.ResourceData, m interface{}) error {
+func resourceRepositoryUpstreamCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	pc := m.(*providerConfig)
 
 	namespace := requiredString(d, Namespace)
 	repository := requiredString(d, Repository)
-	upstreamType := requiredString(d, UpstreamType)
-
-	authMode := optionalString(d, AuthMode)
-	authSecret := nullableString(d, AuthSecret)
-	authUsername := nullableString(d, AuthUsername)
-	extraHeader1 := nullableString(d, ExtraHeader1)
-	extraHeader2 := nullableString(d, ExtraHeader2)
-	extraValue1 := nullableString(d, ExtraValue1)
-	extraValue2 := nullableString(d, ExtraValue2)
-	isActive := optionalBool(d, IsActive)
-	mode := optionalString(d, Mode)
-	name := requiredString(d, Name)
-	priority := optionalInt64(d, Priority)
-	upstreamUrl := requiredString(d, UpstreamUrl)
-	verifySsl := optionalBool(d, VerifySsl)
-
-	var upstream Upstream
-	var resp *http.Response
-	var err error
-
-	switch upstreamType {
-	case Alpine:
-		req := pc.APIClient.ReposApi.ReposUpstreamAlpineCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.AlpineUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamAlpineCreateExecute(req)
-	case Cargo:
-		req := pc.APIClient.ReposApi.ReposUpstreamCargoCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.CargoUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamCargoCreateExecute(req)
-	case Composer:
-		req := pc.APIClient.ReposApi.ReposUpstreamComposerCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.ComposerUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamComposerCreateExecute(req)
-	case Conda:
-		req := pc.APIClient.ReposApi.ReposUpstreamCondaCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.CondaUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamCondaCreateExecute(req)
-	case Cran:
-		req := pc.APIClient.ReposApi.ReposUpstreamCranCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.CranUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamCranCreateExecute(req)
-	case Dart:
-		req := pc.APIClient.ReposApi.ReposUpstreamDartCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.DartUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamDartCreateExecute(req)
-	case Deb:
-		req := pc.APIClient.ReposApi.ReposUpstreamDebCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.DebUpstreamRequest{
-			AuthMode:             authMode,
-			AuthSecret:           authSecret,
-			AuthUsername:         authUsername,
-			Component:            optionalString(d, Component),
-			DistroVersions:       expandStrings(d, DistroVersions),
-			ExtraHeader1:         extraHeader1,
-			ExtraHeader2:         extraHeader2,
-			ExtraValue1:          extraValue1,
-			ExtraValue2:          extraValue2,
-			IncludeSources:       optionalBool(d, IncludeSources),
-			IsActive:             isActive,
-			Mode:                 mode,
-			Name:                 name,
-			Priority:             priority,
-			UpstreamDistribution: nullableString(d, UpstreamDistribution),
-			UpstreamUrl:          upstreamUrl,
-			VerifySsl:            verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamDebCreateExecute(req)
-	case Docker:
-		req := pc.APIClient.ReposApi.ReposUpstreamDockerCreate(pc.Auth, namespace, repository)
-
-		// Read certificate files for mTLS authentication (Docker only for now)
-		authCert, authCertKey, certErr := readCertificateFiles(d)
-		if certErr != nil {
-			return certErr
-		}
 
-		req = req.Data(cloudsmith.DockerUpstreamRequest{
-			AuthMode:           authMode,
-			AuthSecret:         authSecret,
-			AuthUsername:       authUsername,
-			AuthCertificate:    authCert,
-			AuthCertificateKey: authCertKey,
-			ExtraHeader1:       extraHeader1,
-			ExtraHeader2:       extraHeader2,
-			ExtraValue1:        extraValue1,
-			ExtraValue2:        extraValue2,
-			IsActive:           isActive,
-			Mode:               mode,
-			Name:               name,
-			Priority:           priority,
-			UpstreamUrl:        upstreamUrl,
-			VerifySsl:          verifySsl,
-		})
-		var execErr error
-		upstream, resp, execErr = pc.APIClient.ReposApi.ReposUpstreamDockerCreateExecute(req)
-		if execErr != nil {
-			if resp != nil && resp.StatusCode == http.StatusInternalServerError {
-				// Until we handle this better in API response we have to assume that this is the issue
-				return fmt.Errorf("this `upstream_url` might be already configured for this repository. %w", execErr)
-			}
-			return execErr
-		}
-	case Generic:
-		req := pc.APIClient.ReposApi.ReposUpstreamGenericCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.GenericUpstreamRequest{
-			AuthMode:       authMode,
-			AuthSecret:     authSecret,
-			AuthUsername:   authUsername,
-			ExtraHeader1:   extraHeader1,
-			ExtraHeader2:   extraHeader2,
-			ExtraValue1:    extraValue1,
-			ExtraValue2:    extraValue2,
-			IsActive:       isActive,
-			Mode:           mode,
-			Name:           name,
-			Priority:       priority,
-			UpstreamPrefix: optionalString(d, UpstreamPrefix),
-			UpstreamUrl:    upstreamUrl,
-			VerifySsl:      verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamGenericCreateExecute(req)
-	case Go:
-		req := pc.APIClient.ReposApi.ReposUpstreamGoCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.GoUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamGoCreateExecute(req)
-	case Helm:
-		req := pc.APIClient.ReposApi.ReposUpstreamHelmCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.HelmUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamHelmCreateExecute(req)
-	case Hex:
-		req := pc.APIClient.ReposApi.ReposUpstreamHexCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.HexUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamHexCreateExecute(req)
-	case HuggingFace:
-		req := pc.APIClient.ReposApi.ReposUpstreamHuggingfaceCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.HuggingfaceUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamHuggingfaceCreateExecute(req)
-	case Maven:
-		req := pc.APIClient.ReposApi.ReposUpstreamMavenCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.MavenUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamMavenCreateExecute(req)
-	case Npm:
-		req := pc.APIClient.ReposApi.ReposUpstreamNpmCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.NpmUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamNpmCreateExecute(req)
-	case NuGet:
-		req := pc.APIClient.ReposApi.ReposUpstreamNugetCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.NugetUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamNugetCreateExecute(req)
-	case Python:
-		req := pc.APIClient.ReposApi.ReposUpstreamPythonCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.PythonUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamPythonCreateExecute(req)
-	case Rpm:
-		req := pc.APIClient.ReposApi.ReposUpstreamRpmCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.RpmUpstreamRequest{
-			AuthMode:       authMode,
-			AuthSecret:     authSecret,
-			AuthUsername:   authUsername,
-			DistroVersion:  requiredString(d, DistroVersion),
-			ExtraHeader1:   extraHeader1,
-			ExtraHeader2:   extraHeader2,
-			ExtraValue1:    extraValue1,
-			ExtraValue2:    extraValue2,
-			IncludeSources: optionalBool(d, IncludeSources),
-			IsActive:       isActive,
-			Mode:           mode,
-			Name:           name,
-			Priority:       priority,
-			UpstreamUrl:    upstreamUrl,
-			VerifySsl:      verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamRpmCreateExecute(req)
-	case Ruby:
-		req := pc.APIClient.ReposApi.ReposUpstreamRubyCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.RubyUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamRubyCreateExecute(req)
-	case Swift:
-		req := pc.APIClient.ReposApi.ReposUpstreamSwiftCreate(pc.Auth, namespace, repository)
-		req = req.Data(cloudsmith.SwiftUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamSwiftCreateExecute(req)
-	default:
-		err = fmt.Errorf("invalid upstream type: '%s'", upstreamType)
+	format, err := lookupUpstreamFormat(requiredString(d, UpstreamType))
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
+	diags, err := checkUpstreamDistros(pc, d, requiredString(d, UpstreamType))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	diags = append(diags, upstreamUrlDiagnostics(d)...)
+
+	upstream, resp, err := format.create(pc, namespace, repository, d, expandUpstreamCommon(d))
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusInternalServerError {
 			// Until we handle this better in API response we have to assume that this is the issue
-			return fmt.Errorf("this `upstream_url` might be already configured for this repository. %w", err)
+			return diag.FromErr(fmt.Errorf("this `upstream_url` might be already configured for this repository. %w", err))
 		}
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(upstream.GetSlugPerm())
 
+	if err := waitForNewUpstream(d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(diags, resourceRepositoryUpstreamRead(ctx, d, m)...)
+}
+
+// waitForNewUpstream waits for a newly created upstream to be readable and,
+// unless is_active is false, to become active.
+func waitForNewUpstream(d *schema.ResourceData, m interface{}) error {
 	if err := waitForCreation(upstreamReadFunc(d, m), "upstream", d.Id()); err != nil {
 		return err
 	}
 
 	// Wait for is_active to become true when expected (nil defaults to true, or explicitly set true).
 	// Some upstream types (e.g. deb) can take several minutes to activate after creation.
-	if isActive == nil || *isActive {
-		activeChecker := func() error {
-			var resp *http.Response
-			if upstream, resp, err = getUpstream(d, m); err != nil {
-				if is404(resp) {
-					return errKeepWaiting
-				}
-				return err
-			}
-			return checkUpstreamActivation(upstream, d.Id())
+	if isActive := optionalBool(d, IsActive); isActive == nil || *isActive {
+		checker := checkUpstreamActivation
+		if requiredBool(d, WaitForActive) {
+			checker = checkUpstreamReachable
 		}
-		if err := waiter(activeChecker, 5*time.Minute, 10*time.Second); err != nil {
-			var disabledErr upstreamActivationDisabledError
-			if errors.As(err, &disabledErr) {
-				return disabledErr
-			}
-			return fmt.Errorf("error waiting for upstream (%s) to become active: %w", d.Id(), err)
+		if err := waitForUpstreamActive(d, m, checker); err != nil {
+			return err
 		}
 	}
 
-	return resourceRepositoryUpstreamRead(d, m)
+	return nil
 }
 
 // upstreamReadFunc returns a function suitable for waitForCreation/waitForDeletion
@@ -682,83 +372,15 @@ func upstreamReadFunc(d *schema.ResourceData, m interface{}) func() (*http.Respo
 func getUpstream(d *schema.ResourceData, m interface{}) (Upstream, *http.Response, error) {
 	pc := m.(*providerConfig)
 
-	namespace := requiredString(d, Namespace)
-	repository := requiredString(d, Repository)
-	upstreamType := requiredString(d, UpstreamType)
-
-	var err error
-	var resp *http.Response
-	var upstream Upstream
-
-	switch upstreamType {
-	case Alpine:
-		req := pc.APIClient.ReposApi.ReposUpstreamAlpineRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamAlpineReadExecute(req)
-	case Cargo:
-		req := pc.APIClient.ReposApi.ReposUpstreamCargoRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamCargoReadExecute(req)
-	case Composer:
-		req := pc.APIClient.ReposApi.ReposUpstreamComposerRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamComposerReadExecute(req)
-	case Conda:
-		req := pc.APIClient.ReposApi.ReposUpstreamCondaRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamCondaReadExecute(req)
-	case Cran:
-		req := pc.APIClient.ReposApi.ReposUpstreamCranRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamCranReadExecute(req)
-	case Dart:
-		req := pc.APIClient.ReposApi.ReposUpstreamDartRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamDartReadExecute(req)
-	case Deb:
-		req := pc.APIClient.ReposApi.ReposUpstreamDebRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamDebReadExecute(req)
-	case Docker:
-		req := pc.APIClient.ReposApi.ReposUpstreamDockerRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamDockerReadExecute(req)
-	case Generic:
-		req := pc.APIClient.ReposApi.ReposUpstreamGenericRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamGenericReadExecute(req)
-	case Go:
-		req := pc.APIClient.ReposApi.ReposUpstreamGoRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamGoReadExecute(req)
-	case Helm:
-		req := pc.APIClient.ReposApi.ReposUpstreamHelmRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamHelmReadExecute(req)
-	case Hex:
-		req := pc.APIClient.ReposApi.ReposUpstreamHexRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamHexReadExecute(req)
-	case HuggingFace:
-		req := pc.APIClient.ReposApi.ReposUpstreamHuggingfaceRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamHuggingfaceReadExecute(req)
-	case Maven:
-		req := pc.APIClient.ReposApi.ReposUpstreamMavenRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamMavenReadExecute(req)
-	case Npm:
-		req := pc.APIClient.ReposApi.ReposUpstreamNpmRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamNpmReadExecute(req)
-	case NuGet:
-		req := pc.APIClient.ReposApi.ReposUpstreamNugetRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamNugetReadExecute(req)
-	case Python:
-		req := pc.APIClient.ReposApi.ReposUpstreamPythonRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamPythonReadExecute(req)
-	case Rpm:
-		req := pc.APIClient.ReposApi.ReposUpstreamRpmRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamRpmReadExecute(req)
-	case Ruby:
-		req := pc.APIClient.ReposApi.ReposUpstreamRubyRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamRubyReadExecute(req)
-	case Swift:
-		req := pc.APIClient.ReposApi.ReposUpstreamSwiftRead(pc.Auth, namespace, repository, d.Id())
-		upstream, resp, err = pc.APIClient.ReposApi.ReposUpstreamSwiftReadExecute(req)
-	default:
-		err = fmt.Errorf("invalid upstream_type '%s'", upstreamType)
-	}
-
-	return upstream, resp, err
+	format, err := lookupUpstreamFormat(requiredString(d, UpstreamType))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return format.read(pc, requiredString(d, Namespace), requiredString(d, Repository), d.Id())
 }
 
-func resourceRepositoryUpstreamRead(d *schema.ResourceData, m interface{}) error {
+func resourceRepositoryUpstreamRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	upstream, resp, err := getUpstream(d, m)
 
 	if err != nil {
@@ -767,29 +389,32 @@ func resourceRepositoryUpstreamRead(d *schema.ResourceData, m interface{}) error
 			return nil
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	_ = d.Set(AuthMode, upstream.GetAuthMode())
 
-	// The API no longer returns plaintext secrets for security reasons
-	// So we need to maintain the existing auth_secret value in state
-	// Only set it from API if non-empty (which won't happen anymore)
-	authSecret := upstream.GetAuthSecret()
-	if authSecret == "" {
-		// Don't modify the auth_secret that's already in state
-		// This prevents Terraform from detecting a change when there isn't one
-	} else {
-		_ = d.Set(AuthSecret, authSecret)
-	}
-
+	// auth_secret isn't returned by the API, so it keeps its configured value
+	// in state. auth_secret_wo keeps it out of state altogether.
 	_ = d.Set(AuthUsername, upstream.GetAuthUsername())
 	_ = d.Set(CreatedAt, timeToString(upstream.GetCreatedAt()))
 	_ = d.Set(ExtraHeader1, upstream.GetExtraHeader1())
 	_ = d.Set(ExtraHeader2, upstream.GetExtraHeader2())
-	_ = d.Set(ExtraValue1, upstream.GetExtraValue1())
-	_ = d.Set(ExtraValue2, upstream.GetExtraValue2())
+	// Extra header values configured as write-only aren't kept in state.
+	if d.Get(ExtraValue1WoVersion).(int) == 0 {
+		_ = d.Set(ExtraValue1, upstream.GetExtraValue1())
+	}
+	if d.Get(ExtraValue2WoVersion).(int) == 0 {
+		_ = d.Set(ExtraValue2, upstream.GetExtraValue2())
+	}
 	_ = d.Set(IsActive, upstream.GetIsActive())
+	_ = d.Set(HealthState, upstreamHealthState(upstream))
+	_ = d.Set(DisableReason, actionableUpstreamDisableReason(upstream.GetDisableReasonText(), upstream.GetDisableReason()))
+	if fetchedAt, ok := upstreamLastSuccessfulFetch(upstream); ok && !fetchedAt.IsZero() {
+		_ = d.Set(LastSuccessfulFetch, timeToString(fetchedAt))
+	} else {
+		_ = d.Set(LastSuccessfulFetch, "")
+	}
 	_ = d.Set(Mode, upstream.GetMode())
 	_ = d.Set(Name, upstream.GetName())
 	_ = d.Set(Priority, upstream.GetPriority())
@@ -798,441 +423,50 @@ func resourceRepositoryUpstreamRead(d *schema.ResourceData, m interface{}) error
 	_ = d.Set(UpstreamUrl, upstream.GetUpstreamUrl())
 	_ = d.Set(VerifySsl, upstream.GetVerifySsl())
 
-	switch u := upstream.(type) {
-	case *cloudsmith.DebUpstream:
-		_ = d.Set(Component, u.GetComponent())
-		_ = d.Set(DistroVersions, flattenStrings(u.GetDistroVersions()))
-		_ = d.Set(IncludeSources, u.GetIncludeSources())
-		_ = d.Set(UpstreamDistribution, u.GetUpstreamDistribution())
-	case *cloudsmith.GenericUpstream:
-		_ = d.Set(UpstreamPrefix, u.GetUpstreamPrefix())
-	case *cloudsmith.RpmUpstream:
-		_ = d.Set(DistroVersion, u.GetDistroVersion())
-		_ = d.Set(IncludeSources, u.GetIncludeSources())
+	if format := upstreamFormats[requiredString(d, UpstreamType)]; format.flatten != nil {
+		format.flatten(d, upstream)
 	}
 
 	// namespace, repository and upstream_type are not returned from the read
 	// endpoint, so we can use the values stored in resource state. We rely on
-	// ForceNew to ensure that if any of these change then a new resource is created.
+	// ForceNew to ensure that if namespace or repository change then a new
+	// resource is created, and on replaceUpstream when upstream_type changes.
 	_ = d.Set(Namespace, requiredString(d, Namespace))
 	_ = d.Set(Repository, requiredString(d, Repository))
 	_ = d.Set(UpstreamType, requiredString(d, UpstreamType))
+	// wait_for_active only affects the provider, so keep its value, writing
+	// the default into state saved before it existed.
+	_ = d.Set(WaitForActive, requiredBool(d, WaitForActive))
 
-	return nil
+	return flattenUpstreamCertificate(d, time.Now())
 }
 
-func resourceRepositoryUpstreamUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceRepositoryUpstreamUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	pc := m.(*providerConfig)
 
+	diags, err := checkUpstreamDistros(pc, d, requiredString(d, UpstreamType))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if d.HasChange(UpstreamUrl) {
+		diags = append(diags, upstreamUrlDiagnostics(d)...)
+	}
+
+	if d.HasChange(UpstreamType) {
+		return append(diags, replaceUpstream(ctx, d, m)...)
+	}
+
 	namespace := requiredString(d, Namespace)
 	repository := requiredString(d, Repository)
-	upstreamType := requiredString(d, UpstreamType)
-	slugPerm := d.Id()
-
-	authMode := optionalString(d, AuthMode)
-	authSecret := nullableString(d, AuthSecret)
-	authUsername := nullableString(d, AuthUsername)
-	extraHeader1 := nullableString(d, ExtraHeader1)
-	extraHeader2 := nullableString(d, ExtraHeader2)
-	extraValue1 := nullableString(d, ExtraValue1)
-	extraValue2 := nullableString(d, ExtraValue2)
-	isActive := optionalBool(d, IsActive)
-	mode := optionalString(d, Mode)
-	name := requiredString(d, Name)
-	priority := optionalInt64(d, Priority)
-	upstreamUrl := requiredString(d, UpstreamUrl)
-	verifySsl := optionalBool(d, VerifySsl)
-
-	var upstream Upstream
-	var err error
-
-	switch upstreamType {
-	case Alpine:
-		req := pc.APIClient.ReposApi.ReposUpstreamAlpineUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.AlpineUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamAlpineUpdateExecute(req)
-	case Cargo:
-		req := pc.APIClient.ReposApi.ReposUpstreamCargoUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.CargoUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamCargoUpdateExecute(req)
-	case Composer:
-		req := pc.APIClient.ReposApi.ReposUpstreamComposerUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.ComposerUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamComposerUpdateExecute(req)
-	case Conda:
-		req := pc.APIClient.ReposApi.ReposUpstreamCondaUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.CondaUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamCondaUpdateExecute(req)
-	case Cran:
-		req := pc.APIClient.ReposApi.ReposUpstreamCranUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.CranUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamCranUpdateExecute(req)
-	case Dart:
-		req := pc.APIClient.ReposApi.ReposUpstreamDartUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.DartUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamDartUpdateExecute(req)
-	case Deb:
-		req := pc.APIClient.ReposApi.ReposUpstreamDebUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.DebUpstreamRequest{
-			AuthMode:             authMode,
-			AuthSecret:           authSecret,
-			AuthUsername:         authUsername,
-			Component:            optionalString(d, Component),
-			DistroVersions:       expandStrings(d, DistroVersions),
-			ExtraHeader1:         extraHeader1,
-			ExtraHeader2:         extraHeader2,
-			ExtraValue1:          extraValue1,
-			ExtraValue2:          extraValue2,
-			IncludeSources:       optionalBool(d, IncludeSources),
-			IsActive:             isActive,
-			Mode:                 mode,
-			Name:                 name,
-			Priority:             priority,
-			UpstreamDistribution: nullableString(d, UpstreamDistribution),
-			UpstreamUrl:          upstreamUrl,
-			VerifySsl:            verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamDebUpdateExecute(req)
-	case Docker:
-		req := pc.APIClient.ReposApi.ReposUpstreamDockerUpdate(pc.Auth, namespace, repository, slugPerm)
-
-		// Read certificate files for mTLS authentication (Docker only for now)
-		authCert, authCertKey, certErr := readCertificateFiles(d)
-		if certErr != nil {
-			return certErr
-		}
 
-		req = req.Data(cloudsmith.DockerUpstreamRequest{
-			AuthMode:           authMode,
-			AuthSecret:         authSecret,
-			AuthUsername:       authUsername,
-			AuthCertificate:    authCert,
-			AuthCertificateKey: authCertKey,
-			ExtraHeader1:       extraHeader1,
-			ExtraHeader2:       extraHeader2,
-			ExtraValue1:        extraValue1,
-			ExtraValue2:        extraValue2,
-			IsActive:           isActive,
-			Mode:               mode,
-			Name:               name,
-			Priority:           priority,
-			UpstreamUrl:        upstreamUrl,
-			VerifySsl:          verifySsl,
-		})
-		var execErr error
-		upstream, _, execErr = pc.APIClient.ReposApi.ReposUpstreamDockerUpdateExecute(req)
-		if execErr != nil {
-			return execErr
-		}
-	case Generic:
-		req := pc.APIClient.ReposApi.ReposUpstreamGenericUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.GenericUpstreamRequest{
-			AuthMode:       authMode,
-			AuthSecret:     authSecret,
-			AuthUsername:   authUsername,
-			ExtraHeader1:   extraHeader1,
-			ExtraHeader2:   extraHeader2,
-			ExtraValue1:    extraValue1,
-			ExtraValue2:    extraValue2,
-			IsActive:       isActive,
-			Mode:           mode,
-			Name:           name,
-			Priority:       priority,
-			UpstreamPrefix: optionalString(d, UpstreamPrefix),
-			UpstreamUrl:    upstreamUrl,
-			VerifySsl:      verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamGenericUpdateExecute(req)
-	case Go:
-		req := pc.APIClient.ReposApi.ReposUpstreamGoUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.GoUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamGoUpdateExecute(req)
-	case Helm:
-		req := pc.APIClient.ReposApi.ReposUpstreamHelmUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.HelmUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamHelmUpdateExecute(req)
-	case Hex:
-		req := pc.APIClient.ReposApi.ReposUpstreamHexUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.HexUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamHexUpdateExecute(req)
-	case HuggingFace:
-		req := pc.APIClient.ReposApi.ReposUpstreamHuggingfaceUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.HuggingfaceUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamHuggingfaceUpdateExecute(req)
-	case Maven:
-		req := pc.APIClient.ReposApi.ReposUpstreamMavenUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.MavenUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamMavenUpdateExecute(req)
-	case Npm:
-		req := pc.APIClient.ReposApi.ReposUpstreamNpmUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.NpmUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamNpmUpdateExecute(req)
-	case NuGet:
-		req := pc.APIClient.ReposApi.ReposUpstreamNugetUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.NugetUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamNugetUpdateExecute(req)
-	case Python:
-		req := pc.APIClient.ReposApi.ReposUpstreamPythonUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.PythonUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamPythonUpdateExecute(req)
-	case Rpm:
-		req := pc.APIClient.ReposApi.ReposUpstreamRpmUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.RpmUpstreamRequest{
-			AuthMode:       authMode,
-			AuthSecret:     authSecret,
-			AuthUsername:   authUsername,
-			DistroVersion:  requiredString(d, DistroVersion),
-			ExtraHeader1:   extraHeader1,
-			ExtraHeader2:   extraHeader2,
-			ExtraValue1:    extraValue1,
-			ExtraValue2:    extraValue2,
-			IncludeSources: optionalBool(d, IncludeSources),
-			IsActive:       isActive,
-			Mode:           mode,
-			Name:           name,
-			Priority:       priority,
-			UpstreamUrl:    upstreamUrl,
-			VerifySsl:      verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamRpmUpdateExecute(req)
-	case Ruby:
-		req := pc.APIClient.ReposApi.ReposUpstreamRubyUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.RubyUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamRubyUpdateExecute(req)
-	case Swift:
-		req := pc.APIClient.ReposApi.ReposUpstreamSwiftUpdate(pc.Auth, namespace, repository, slugPerm)
-		req = req.Data(cloudsmith.SwiftUpstreamRequest{
-			AuthMode:     authMode,
-			AuthSecret:   authSecret,
-			AuthUsername: authUsername,
-			ExtraHeader1: extraHeader1,
-			ExtraHeader2: extraHeader2,
-			ExtraValue1:  extraValue1,
-			ExtraValue2:  extraValue2,
-			IsActive:     isActive,
-			Mode:         mode,
-			Name:         name,
-			Priority:     priority,
-			UpstreamUrl:  upstreamUrl,
-			VerifySsl:    verifySsl,
-		})
-		upstream, _, err = pc.APIClient.ReposApi.ReposUpstreamSwiftUpdateExecute(req)
-	default:
-		err = fmt.Errorf("invalid upstream type: '%s'", upstreamType)
+	format, err := lookupUpstreamFormat(requiredString(d, UpstreamType))
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
+	upstream, _, err := format.update(pc, namespace, repository, d.Id(), d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(upstream.GetSlugPerm())
@@ -1247,97 +481,218 @@ func resourceRepositoryUpstreamUpdate(d *schema.ResourceData, m interface{}) err
 		return nil
 	}
 	if err := waiter(checkerFunc, defaultUpdateTimeout, defaultUpdateInterval); err != nil {
-		return fmt.Errorf("error waiting for upstream (%s) to be updated: %w", d.Id(), err)
+		return diag.FromErr(fmt.Errorf("error waiting for upstream (%s) to be updated: %w", d.Id(), err))
 	}
 
-	return resourceRepositoryUpstreamRead(d, m)
+	if requiredBool(d, WaitForActive) && !upstreamDisabledInConfig(d.GetRawConfig()) {
+		if err := waitForUpstreamActive(d, m, checkUpstreamReachable); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return append(diags, resourceRepositoryUpstreamRead(ctx, d, m)...)
 }
 
-func resourceRepositoryUpstreamDelete(d *schema.ResourceData, m interface{}) error {
+// upstreamReplacementName returns the name a replacement upstream is created
+// under while the upstream it replaces still exists.
+func upstreamReplacementName(name, upstreamType string) string {
+	return fmt.Sprintf("%s (%s)", name, upstreamType)
+}
+
+// replaceUpstream moves the upstream to a new upstream_type. The format of an
+// upstream can't be changed, so a new upstream is created in the new format,
+// and the old one is only deleted once the new one is active. The repository
+// is never left without the upstream, and if the new one fails to activate it
+// is deleted and the old one kept. If the name is unchanged, the new upstream
+// is created under a temporary name so that the two don't collide, and
+// renamed once the old one is gone.
+func replaceUpstream(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	pc := m.(*providerConfig)
 
 	namespace := requiredString(d, Namespace)
 	repository := requiredString(d, Repository)
-	upstreamType := requiredString(d, UpstreamType)
-
-	var err error
-
-	switch upstreamType {
-	case Alpine:
-		req := pc.APIClient.ReposApi.ReposUpstreamAlpineDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamAlpineDeleteExecute(req)
-	case Cargo:
-		req := pc.APIClient.ReposApi.ReposUpstreamCargoDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamCargoDeleteExecute(req)
-	case Composer:
-		req := pc.APIClient.ReposApi.ReposUpstreamComposerDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamComposerDeleteExecute(req)
-	case Conda:
-		req := pc.APIClient.ReposApi.ReposUpstreamCondaDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamCondaDeleteExecute(req)
-	case Cran:
-		req := pc.APIClient.ReposApi.ReposUpstreamCranDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamCranDeleteExecute(req)
-	case Dart:
-		req := pc.APIClient.ReposApi.ReposUpstreamDartDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamDartDeleteExecute(req)
-	case Deb:
-		req := pc.APIClient.ReposApi.ReposUpstreamDebDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamDebDeleteExecute(req)
-	case Docker:
-		req := pc.APIClient.ReposApi.ReposUpstreamDockerDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamDockerDeleteExecute(req)
-	case Generic:
-		req := pc.APIClient.ReposApi.ReposUpstreamGenericDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamGenericDeleteExecute(req)
-	case Go:
-		req := pc.APIClient.ReposApi.ReposUpstreamGoDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamGoDeleteExecute(req)
-	case Helm:
-		req := pc.APIClient.ReposApi.ReposUpstreamHelmDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamHelmDeleteExecute(req)
-	case Hex:
-		req := pc.APIClient.ReposApi.ReposUpstreamHexDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamHexDeleteExecute(req)
-	case HuggingFace:
-		req := pc.APIClient.ReposApi.ReposUpstreamHuggingfaceDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamHuggingfaceDeleteExecute(req)
-	case Maven:
-		req := pc.APIClient.ReposApi.ReposUpstreamMavenDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamMavenDeleteExecute(req)
-	case Npm:
-		req := pc.APIClient.ReposApi.ReposUpstreamNpmDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamNpmDeleteExecute(req)
-	case NuGet:
-		req := pc.APIClient.ReposApi.ReposUpstreamNugetDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamNugetDeleteExecute(req)
-	case Python:
-		req := pc.APIClient.ReposApi.ReposUpstreamPythonDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamPythonDeleteExecute(req)
-	case Rpm:
-		req := pc.APIClient.ReposApi.ReposUpstreamRpmDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamRpmDeleteExecute(req)
-	case Ruby:
-		req := pc.APIClient.ReposApi.ReposUpstreamRubyDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamRubyDeleteExecute(req)
-	case Swift:
-		req := pc.APIClient.ReposApi.ReposUpstreamSwiftDelete(pc.Auth, namespace, repository, d.Id())
-		_, err = pc.APIClient.ReposApi.ReposUpstreamSwiftDeleteExecute(req)
-	default:
-		err = fmt.Errorf("invalid upstream_type: '%s'", upstreamType)
+	oldType, newType := d.GetChange(UpstreamType)
+	oldName, _ := d.GetChange(Name)
+	oldSlugPerm := d.Id()
+
+	oldFormat, err := lookupUpstreamFormat(oldType.(string))
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	newFormat, err := lookupUpstreamFormat(newType.(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Until the new upstream is active, state keeps describing the old one
+	// if anything fails.
+	d.Partial(true)
 
+	common := expandUpstreamCommon(d)
+	rename := common.name == oldName.(string)
+	if rename {
+		common.name = upstreamReplacementName(common.name, newType.(string))
+	}
+
+	upstream, _, err := newFormat.create(pc, namespace, repository, d, common)
 	if err != nil {
-		return err
+		return diag.FromErr(fmt.Errorf("error creating %s upstream to replace %s upstream (%s): %w", newType, oldType, oldSlugPerm, formatAPIError(err)))
+	}
+	d.SetId(upstream.GetSlugPerm())
+
+	if err := waitForNewUpstream(d, m); err != nil {
+		d.SetId(oldSlugPerm)
+		if _, deleteErr := newFormat.delete(pc, namespace, repository, upstream.GetSlugPerm()); deleteErr != nil {
+			return diag.FromErr(fmt.Errorf(
+				"error waiting for %s upstream (%s), so %s upstream (%s) was kept; the new upstream could not be deleted and must be deleted manually: %w",
+				newType, upstream.GetSlugPerm(), oldType, oldSlugPerm, errors.Join(err, formatAPIError(deleteErr)),
+			))
+		}
+		return diag.FromErr(fmt.Errorf("error waiting for %s upstream (%s), so it was deleted and %s upstream (%s) was kept: %w", newType, upstream.GetSlugPerm(), oldType, oldSlugPerm, err))
+	}
+
+	d.Partial(false)
+
+	if _, err := oldFormat.delete(pc, namespace, repository, oldSlugPerm); err != nil {
+		return diag.FromErr(fmt.Errorf("error deleting %s upstream (%s), replaced by %s upstream (%s); it must be deleted manually: %w", oldType, oldSlugPerm, newType, d.Id(), formatAPIError(err)))
+	}
+	readOld := func() (*http.Response, error) {
+		_, resp, err := oldFormat.read(pc, namespace, repository, oldSlugPerm)
+		return resp, err
+	}
+	if err := waitForDeletion(readOld, "upstream", oldSlugPerm); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if rename {
+		if _, _, err := newFormat.update(pc, namespace, repository, d.Id(), d); err != nil {
+			return diag.FromErr(fmt.Errorf("error renaming %s upstream (%s) to %q: %w", newType, d.Id(), oldName, formatAPIError(err)))
+		}
+	}
+
+	return resourceRepositoryUpstreamRead(ctx, d, m)
+}
+
+func resourceRepositoryUpstreamDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	format, err := lookupUpstreamFormat(requiredString(d, UpstreamType))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if _, err := format.delete(pc, requiredString(d, Namespace), requiredString(d, Repository), d.Id()); err != nil {
+		return diag.FromErr(err)
 	}
 
 	if err := waitForDeletion(upstreamReadFunc(d, m), "upstream", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// upstreamDisabledInConfig reports whether is_active is explicitly configured
+// as false. The value in state can't be used because is_active is computed and
+// follows the upstream's actual status.
+func upstreamDisabledInConfig(config cty.Value) bool {
+	if config.IsNull() || !config.IsKnown() {
+		return false
+	}
+	isActive := config.GetAttr(IsActive)
+	return isActive.IsKnown() && !isActive.IsNull() && isActive.False()
+}
+
+// customizeDiffUpstream validates the format-specific fields, the
+// distribution versions and the upstream URL, fills in preset values and, when
+// wait_for_active is set, plans an update for an upstream that has stopped
+// being active so the drift is visible in plan.
+func customizeDiffUpstream(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if err := customizeDiffUpstreamFormat(ctx, d, m); err != nil {
+		return err
+	}
+	if err := customizeDiffUpstreamDistros(d, m); err != nil {
+		return err
+	}
+	if err := customizeDiffUpstreamPreset(d); err != nil {
+		return err
+	}
+	if err := customizeDiffUpstreamType(d); err != nil {
 		return err
 	}
 
+	if d.Id() == "" || !d.Get(WaitForActive).(bool) {
+		return nil
+	}
+	if upstreamDisabledInConfig(d.GetRawConfig()) {
+		return nil
+	}
+	if state, _ := d.GetChange(HealthState); state.(string) != "" && state.(string) != upstreamHealthActive {
+		return d.SetNewComputed(HealthState)
+	}
 	return nil
 }
 
+// customizeDiffUpstreamType plans the replacement of the upstream when
+// upstream_type changes. The attributes of the old upstream become unknown,
+// those of the old format are cleared, and replaced_upstream says which
+// upstream is replaced and why.
+func customizeDiffUpstreamType(d *schema.ResourceDiff) error {
+	if d.Id() == "" || !d.HasChange(UpstreamType) {
+		return nil
+	}
+
+	for _, attr := range []string{SlugPerm, CreatedAt, UpdatedAt, HealthState, DisableReason, LastSuccessfulFetch} {
+		if err := d.SetNewComputed(attr); err != nil {
+			return err
+		}
+	}
+	if config := d.GetRawConfig(); !config.IsNull() && config.GetAttr(Priority).IsNull() {
+		if err := d.SetNewComputed(Priority); err != nil {
+			return err
+		}
+	}
+
+	// The computed format-specific attributes that aren't configured are read
+	// from the new upstream if they apply to its format, and cleared otherwise.
+	oldType, newType := d.GetChange(UpstreamType)
+	format, err := lookupUpstreamFormat(newType.(string))
+	if err != nil {
+		return err
+	}
+	for _, field := range upstreamComputedFields {
+		if upstreamFieldConfigured(d, field) {
+			continue
+		}
+		if contains(format.fields, field) {
+			err = d.SetNewComputed(field)
+		} else {
+			err = d.SetNew(field, upstreamFieldZero(field))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	oldName, _ := d.GetChange(Name)
+	return d.SetNew(ReplacedUpstream, fmt.Sprintf(
+		"%s upstream %q (%s), replaced because %s changed from %s to %s",
+		oldType, oldName, d.Id(), UpstreamType, oldType, newType,
+	))
+}
+
+// upstreamFieldZero returns the value of an unset computed format-specific
+// attribute.
+func upstreamFieldZero(field string) interface{} {
+	switch field {
+	case IncludeSources:
+		return false
+	case UpstreamCache, UpstreamPendingValidation, UpstreamTrust:
+		return []interface{}{}
+	}
+	return ""
+}
+
 func validateUpstreamUrl(v interface{}, k string) (warnings []string, errors []error) {
 	valueStr := v.(string)
 	if len(valueStr) > 0 && valueStr[len(valueStr)-1] == '/' {
@@ -1348,15 +703,23 @@ func validateUpstreamUrl(v interface{}, k string) (warnings []string, errors []e
 
 func resourceRepositoryUpstream() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceRepositoryUpstreamCreate,
-		Read:   resourceRepositoryUpstreamRead,
-		Update: resourceRepositoryUpstreamUpdate,
-		Delete: resourceRepositoryUpstreamDelete,
+		CreateContext: resourceRepositoryUpstreamCreate,
+		ReadContext:   resourceRepositoryUpstreamRead,
+		UpdateContext: resourceRepositoryUpstreamUpdate,
+		DeleteContext: resourceRepositoryUpstreamDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: importUpstream,
 		},
 
+		CustomizeDiff: customizeDiffUpstream,
+
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validation.PreferWriteOnlyAttribute(cty.GetAttrPath(AuthSecret), cty.GetAttrPath(AuthSecretWo)),
+			validation.PreferWriteOnlyAttribute(cty.GetAttrPath(ExtraValue1), cty.GetAttrPath(ExtraValue1Wo)),
+			validation.PreferWriteOnlyAttribute(cty.GetAttrPath(ExtraValue2), cty.GetAttrPath(ExtraValue2Wo)),
+		},
+
 		Schema: map[string]*schema.Schema{
 			AuthMode: {
 				Type:         schema.TypeString,
@@ -1372,6 +735,8 @@ func resourceRepositoryUpstream() *schema.Resource {
 				Sensitive:    true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
+			AuthSecretWo:        writeOnlySecretSchema("Secret to provide with requests to upstream.", AuthSecret, AuthSecretWoVersion),
+			AuthSecretWoVersion: writeOnlyVersionSchema(AuthSecretWo),
 			AuthUsername: {
 				Type:         schema.TypeString,
 				Description:  "Username to provide with requests to upstream.",
@@ -1394,27 +759,54 @@ func resourceRepositoryUpstream() *schema.Resource {
 				ValidateFunc: validation.StringIsNotEmpty,
 				ForceNew:     true,
 			},
+			CertificateExpiryWarningDays: {
+				Type:         schema.TypeInt,
+				Description:  "Warn on refresh when `auth_certificate` expires within this many days. Set to 0 to only warn once the certificate has expired.",
+				Optional:     true,
+				Default:      defaultCertificateExpiryWarningDays,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			CertificateFingerprint: {
+				Type:        schema.TypeString,
+				Description: "The SHA-256 fingerprint of `auth_certificate`.",
+				Computed:    true,
+			},
+			CertificateNotAfter: {
+				Type:        schema.TypeString,
+				Description: "ISO 8601 timestamp at which `auth_certificate` expires.",
+				Computed:    true,
+			},
+			CertificateSubject: {
+				Type:        schema.TypeString,
+				Description: "The subject distinguished name of `auth_certificate`.",
+				Computed:    true,
+			},
 			Component: {
 				Type:         schema.TypeString,
 				Description:  "(deb only) The component to fetch from the upstream.",
 				Optional:     true,
 				Computed:     true,
-				ValidateFunc: validation.StringIsNotEmpty,
+				ValidateFunc: validation.All(validation.StringIsNotEmpty, validation.StringDoesNotContainAny(" \t\n")),
 			},
 			CreatedAt: {
 				Type:        schema.TypeString,
 				Description: "ISO 8601 timestamp at which the Upstream was created.",
 				Computed:    true,
 			},
+			DisableReason: {
+				Type:        schema.TypeString,
+				Description: "Why the upstream was disabled. Empty while the upstream is active or pending activation.",
+				Computed:    true,
+			},
 			DistroVersion: {
 				Type:         schema.TypeString,
-				Description:  "(rpm only) The distribution version that packages found on this upstream will be associated with.",
+				Description:  "(rpm only) The distribution version that packages found on this upstream will be associated with, for example `el/9`. Must be one of the `distro_versions` of the `cloudsmith_distros` data source.",
 				Optional:     true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
 			DistroVersions: {
 				Type:        schema.TypeSet,
-				Description: "(deb only) The distribution versions that packages found on this upstream will be associated with.",
+				Description: "(deb only) The distribution versions that packages found on this upstream will be associated with, for example `ubuntu/jammy`. Each must be one of the `distro_versions` of the `cloudsmith_distros` data source.",
 				Optional:    true,
 				Elem: &schema.Schema{
 					Type:         schema.TypeString,
@@ -1445,6 +837,15 @@ func resourceRepositoryUpstream() *schema.Resource {
 				Optional:     true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
+			ExtraValue1Wo:        writeOnlySecretSchema("The value for extra header #1 to send to upstream.", ExtraValue1, ExtraValue1WoVersion),
+			ExtraValue1WoVersion: writeOnlyVersionSchema(ExtraValue1Wo),
+			ExtraValue2Wo:        writeOnlySecretSchema("The value for extra header #2 to send to upstream.", ExtraValue2, ExtraValue2WoVersion),
+			ExtraValue2WoVersion: writeOnlyVersionSchema(ExtraValue2Wo),
+			HealthState: {
+				Type:        schema.TypeString,
+				Description: "The health of the upstream as of the last refresh: `active`, `pending` (not yet activated) or `disabled` (see `disable_reason`).",
+				Computed:    true,
+			},
 			IncludeSources: {
 				Type:        schema.TypeBool,
 				Description: "(deb/rpm only) When true, source packages will be available from this upstream.",
@@ -1457,6 +858,11 @@ func resourceRepositoryUpstream() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
+			LastSuccessfulFetch: {
+				Type:        schema.TypeString,
+				Description: "ISO 8601 timestamp at which Cloudsmith last fetched from the upstream successfully, where the API reports it.",
+				Computed:    true,
+			},
 			Mode: {
 				Type:         schema.TypeString,
 				Description:  "The mode that this upstream should operate in. Upstream sources can be used to proxy resolved packages, as well as operate in a proxy/cache or cache only mode.",
@@ -1477,9 +883,15 @@ func resourceRepositoryUpstream() *schema.Resource {
 				ForceNew:     true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
+			Preset: {
+				Type:         schema.TypeString,
+				Description:  "A well-known public registry to use as the upstream. Fills in `upstream_url` and, unless it is set, `mode`.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(upstreamPresetNames(), false),
+			},
 			Priority: {
 				Type:         schema.TypeInt,
-				Description:  "Upstream sources are selected for resolving requests by sequential order (1..n), followed by creation date.",
+				Description:  "Upstream sources are selected for resolving requests by sequential order (1..n), followed by creation date. Don't set this on upstreams ordered by a cloudsmith_repository_upstream_order resource.",
 				Optional:     true,
 				Computed:     true,
 				ValidateFunc: validation.IntBetween(1, 32767),
@@ -1491,6 +903,11 @@ func resourceRepositoryUpstream() *schema.Resource {
 				ForceNew:     true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
+			ReplacedUpstream: {
+				Type:        schema.TypeString,
+				Description: "The upstream that this one replaced when `upstream_type` last changed, and why.",
+				Computed:    true,
+			},
 			SlugPerm: {
 				Type:        schema.TypeString,
 				Description: "The unique identifier for this Upstream.",
@@ -1501,11 +918,44 @@ func resourceRepositoryUpstream() *schema.Resource {
 				Description: "ISO 8601 timestamp at which the Upstream was updated.",
 				Computed:    true,
 			},
+			UpstreamCache: {
+				Type:        schema.TypeList,
+				Description: "(docker/maven/npm/python only) Cache settings for packages and metadata fetched from this upstream. Removing the block leaves the setting unchanged.",
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"lifetime": {
+							Type:         schema.TypeInt,
+							Description:  "How long, in seconds, metadata cached from this upstream is used before it is fetched again.",
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
 			UpstreamDistribution: {
 				Type:         schema.TypeString,
 				Description:  "(deb only) The distribution to fetch from the upstream.",
 				Optional:     true,
-				ValidateFunc: validation.StringIsNotEmpty,
+				ValidateFunc: validation.All(validation.StringIsNotEmpty, validation.StringDoesNotContainAny(" \t\n")),
+			},
+			UpstreamPendingValidation: {
+				Type:        schema.TypeList,
+				Description: "(maven/npm/python only) Hold packages fetched from this upstream until they have been validated. Removing the block leaves the setting unchanged.",
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:        schema.TypeBool,
+							Description: "When true, packages fetched from this upstream can't be downloaded until they have been validated.",
+							Required:    true,
+						},
+					},
+				},
 			},
 			UpstreamPrefix: {
 				Type:        schema.TypeString,
@@ -1513,22 +963,46 @@ func resourceRepositoryUpstream() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
+			UpstreamTrust: {
+				Type:        schema.TypeList,
+				Description: "(docker/maven/npm/python only) Package trust settings for this upstream. Removing the block leaves the setting unchanged.",
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"level": {
+							Type:         schema.TypeString,
+							Description:  "Whether packages from this upstream are `Trusted` or `Untrusted`. Packages from an untrusted upstream can't shadow packages of the same name from trusted sources, which protects against dependency confusion. For Docker Hub, this controls whether images are trusted.",
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(upstreamTrustLevels, false),
+						},
+					},
+				},
+			},
 			UpstreamType: {
 				Type:         schema.TypeString,
-				Description:  "The type of Upstream (docker, nuget, python, ...)",
+				Description:  "The type of Upstream (docker, nuget, python, ...). Changing this creates a new upstream in the new format and deletes the old one once the new one is active; see `replaced_upstream`.",
 				Required:     true,
-				ForceNew:     true,
 				ValidateFunc: validation.StringInSlice(upstreamTypes, false),
 			},
 			UpstreamUrl: {
-				Type:        schema.TypeString,
-				Description: "The URL for this upstream source. This must be a fully qualified URL including any path elements required to reach the root of the repository.",
-				Required:    true,
+				Type:         schema.TypeString,
+				Description:  "The URL for this upstream source. This must be a fully qualified URL including any path elements required to reach the root of the repository. Filled in from `preset` if that is set instead.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{UpstreamUrl, Preset},
 				ValidateFunc: validation.All(
 					validation.StringIsNotEmpty,
 					validateUpstreamUrl,
 				),
 			},
+			WaitForActive: {
+				Type:        schema.TypeBool,
+				Description: "If true, applies wait until the upstream is active and, where the API reports fetches, has been fetched from successfully. An upstream that is not active is then planned for update so the next apply re-checks it. Has no effect when `is_active` is false.",
+				Optional:    true,
+				Default:     false,
+			},
 			VerifySsl: {
 				Type:        schema.TypeBool,
 				Description: "If enabled, SSL certificates are verified when requests are made to this upstream. It's recommended to leave this enabled for all public sources to help mitigate Man-In-The-Middle (MITM) attacks. Please note this only applies to HTTPS upstreams.",
@@ -0,0 +1,229 @@
+package cloudsmith
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// tf state prop names for the parsed mTLS certificate
+const (
+	CertificateNotAfter          = "certificate_not_after"
+	CertificateSubject           = "certificate_subject"
+	CertificateFingerprint       = "certificate_fingerprint"
+	CertificateExpiryWarningDays = "certificate_expiry_warning_days"
+)
+
+// defaultCertificateExpiryWarningDays is the default of
+// certificate_expiry_warning_days.
+const defaultCertificateExpiryWarningDays = 30
+
+// setDefaultCertificateExpiryWarningDays writes the default of
+// certificate_expiry_warning_days into state that doesn't hold a value, such as
+// state saved before the attribute existed, unless it's configured. The API
+// doesn't return it, so it would otherwise plan a change.
+func setDefaultCertificateExpiryWarningDays(d *schema.ResourceData) {
+	state := d.GetRawState()
+	if state.IsNull() || !state.GetAttr(CertificateExpiryWarningDays).IsNull() {
+		return
+	}
+	if config := d.GetRawConfig(); !config.IsNull() && !config.GetAttr(CertificateExpiryWarningDays).IsNull() {
+		return
+	}
+	_ = d.Set(CertificateExpiryWarningDays, defaultCertificateExpiryWarningDays)
+}
+
+// parseUpstreamCertificate parses the first certificate in PEM encoded
+// content. Any further certificates are treated as the chain and ignored.
+func parseUpstreamCertificate(content string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(content)))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("invalid certificate format: must be a PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// parseUpstreamPrivateKey parses a PEM encoded PKCS #8, PKCS #1 or SEC 1
+// private key.
+func parseUpstreamPrivateKey(content string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(content)))
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return nil, fmt.Errorf("invalid private key format: must be a PEM encoded private key")
+	}
+	if x509.IsEncryptedPEMBlock(block) { //nolint:staticcheck
+		return nil, fmt.Errorf("invalid private key: encrypted private keys are not supported")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			return key, nil
+		case *ecdsa.PrivateKey:
+			return key, nil
+		case ed25519.PrivateKey:
+			return key, nil
+		default:
+			return nil, fmt.Errorf("invalid private key: unsupported key type %T", key)
+		}
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("invalid private key: not a PKCS #8, PKCS #1 or EC private key")
+}
+
+// checkUpstreamCertificateKeyPair checks that key is the private key for cert.
+func checkUpstreamCertificateKeyPair(cert *x509.Certificate, key crypto.Signer) error {
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(cert.PublicKey) {
+		return fmt.Errorf("%s does not match %s: the private key is not for this certificate", AuthCertificateKey, AuthCertificate)
+	}
+	return nil
+}
+
+// checkUpstreamCertificateValidity rejects certificates that have expired or
+// are not yet valid at now.
+func checkUpstreamCertificateValidity(cert *x509.Certificate, now time.Time) error {
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("%s expired at %s", AuthCertificate, timeToString(cert.NotAfter.UTC()))
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("%s is not valid until %s", AuthCertificate, timeToString(cert.NotBefore.UTC()))
+	}
+	return nil
+}
+
+// upstreamCertificateFingerprint returns the SHA-256 fingerprint of cert in
+// the colon-separated form printed by openssl x509 -fingerprint -sha256.
+func upstreamCertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// upstreamCertificateAttributes returns the computed certificate attributes
+// for cert, which may be nil.
+func upstreamCertificateAttributes(cert *x509.Certificate) map[string]string {
+	attrs := map[string]string{
+		CertificateNotAfter:    "",
+		CertificateSubject:     "",
+		CertificateFingerprint: "",
+	}
+	if cert != nil {
+		attrs[CertificateNotAfter] = timeToString(cert.NotAfter.UTC())
+		attrs[CertificateSubject] = cert.Subject.String()
+		attrs[CertificateFingerprint] = upstreamCertificateFingerprint(cert)
+	}
+	return attrs
+}
+
+// customizeDiffUpstreamCertificate parses auth_certificate and
+// auth_certificate_key at plan time, checking that the key matches the
+// certificate and that a new certificate is currently valid, and plans the
+// computed certificate attributes.
+func customizeDiffUpstreamCertificate(d *schema.ResourceDiff, now time.Time) error {
+	if !d.NewValueKnown(AuthCertificate) || !d.NewValueKnown(AuthCertificateKey) {
+		for attr := range upstreamCertificateAttributes(nil) {
+			if err := d.SetNewComputed(attr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var cert *x509.Certificate
+	if content := d.Get(AuthCertificate).(string); content != "" {
+		var err error
+		if cert, err = parseUpstreamCertificate(content); err != nil {
+			return err
+		}
+		if keyContent := d.Get(AuthCertificateKey).(string); keyContent != "" {
+			key, err := parseUpstreamPrivateKey(keyContent)
+			if err != nil {
+				return err
+			}
+			if err := checkUpstreamCertificateKeyPair(cert, key); err != nil {
+				return err
+			}
+		}
+		// A certificate already in state that has since expired is reported
+		// as a warning on refresh, so that it can still be replaced.
+		if d.Id() == "" || d.HasChange(AuthCertificate) {
+			if err := checkUpstreamCertificateValidity(cert, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	for attr, value := range upstreamCertificateAttributes(cert) {
+		if d.Get(attr).(string) == value {
+			continue
+		}
+		if err := d.SetNew(attr, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenUpstreamCertificate sets the computed certificate attributes from
+// auth_certificate, which the API doesn't return, and warns if the certificate
+// has expired or expires within certificate_expiry_warning_days.
+func flattenUpstreamCertificate(d *schema.ResourceData, now time.Time) diag.Diagnostics {
+	setDefaultCertificateExpiryWarningDays(d)
+
+	var cert *x509.Certificate
+	if content := d.Get(AuthCertificate).(string); content != "" {
+		// The certificate was validated at plan time, so a parse error here
+		// means it was edited in state; leave the attributes empty.
+		cert, _ = parseUpstreamCertificate(content)
+	}
+	for attr, value := range upstreamCertificateAttributes(cert) {
+		_ = d.Set(attr, value)
+	}
+	if cert == nil {
+		return nil
+	}
+
+	path := cty.Path{cty.GetAttrStep{Name: AuthCertificate}}
+	if now.After(cert.NotAfter) {
+		return diag.Diagnostics{{
+			Severity:      diag.Warning,
+			Summary:       "Upstream certificate has expired",
+			Detail:        fmt.Sprintf("The mTLS certificate for upstream %s (%s) expired at %s. Requests to the upstream will fail until the certificate is replaced.", d.Id(), cert.Subject, timeToString(cert.NotAfter.UTC())),
+			AttributePath: path,
+		}}
+	}
+
+	window := time.Duration(d.Get(CertificateExpiryWarningDays).(int)) * 24 * time.Hour
+	if window > 0 && cert.NotAfter.Sub(now) <= window {
+		return diag.Diagnostics{{
+			Severity:      diag.Warning,
+			Summary:       "Upstream certificate expires soon",
+			Detail:        fmt.Sprintf("The mTLS certificate for upstream %s (%s) expires at %s, in %d days.", d.Id(), cert.Subject, timeToString(cert.NotAfter.UTC()), int(cert.NotAfter.Sub(now).Hours()/24)),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testCertificatePEM returns a PEM encoded self-signed client certificate for
+// key, valid between notBefore and notAfter.
+func testCertificatePEM(t *testing.T, key crypto.Signer, notBefore, notAfter time.Time) string {
+	t.Helper()
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName:   "test.cloudsmith.io",
+			Organization: []string{"Cloudsmith Test"},
+		},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// testPrivateKeyPEM returns key PEM encoded as PKCS #8.
+func testPrivateKeyPEM(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshalling private key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func testECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	return key
+}
+
+func TestParseUpstreamPrivateKey(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	ecKey := testECDSAKey(t)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("marshalling key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "pkcs1 rsa", content: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))},
+		{name: "sec1 ec", content: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))},
+		{name: "pkcs8 ec", content: testPrivateKeyPEM(t, ecKey)},
+		{name: "pkcs8 ed25519", content: testPrivateKeyPEM(t, edKey)},
+		{name: "not pem", content: "not a key", err: "must be a PEM encoded private key"},
+		{name: "certificate", content: testCertificatePEM(t, ecKey, time.Now(), time.Now().Add(time.Hour)), err: "must be a PEM encoded private key"},
+		{name: "garbage", content: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")})), err: "not a PKCS #8, PKCS #1 or EC private key"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseUpstreamPrivateKey(tc.content)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestCustomizeDiffUpstreamCertificate(t *testing.T) {
+	t.Parallel()
+
+	key := testECDSAKey(t)
+	otherKey := testECDSAKey(t)
+	now := time.Now()
+	valid := testCertificatePEM(t, key, now.Add(-time.Hour), now.Add(90*24*time.Hour))
+
+	config := func(cert string, key crypto.Signer) map[string]interface{} {
+		config := upstreamFormatTestConfig(Docker)
+		config[AuthMode] = "Certificate and Key"
+		config[AuthCertificate] = cert
+		config[AuthCertificateKey] = testPrivateKeyPEM(t, key)
+		return config
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		err    string
+	}{
+		{name: "valid", config: config(valid, key)},
+		{name: "mismatched key", config: config(valid, otherKey), err: "the private key is not for this certificate"},
+		{name: "expired", config: config(testCertificatePEM(t, key, now.Add(-48*time.Hour), now.Add(-24*time.Hour)), key), err: "auth_certificate expired at"},
+		{name: "not yet valid", config: config(testCertificatePEM(t, key, now.Add(24*time.Hour), now.Add(48*time.Hour)), key), err: "auth_certificate is not valid until"},
+		{name: "not a certificate", config: config("-----BEGIN CERTIFICATE-----", key), err: "must be a PEM encoded certificate"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := resourceRepositoryUpstream()
+			state := &terraform.InstanceState{RawConfig: testRawConfig(r, tc.config)}
+			diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(tc.config), nil)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cert, err := parseUpstreamCertificate(valid)
+			if err != nil {
+				t.Fatalf("parsing certificate: %v", err)
+			}
+			for attr, want := range upstreamCertificateAttributes(cert) {
+				if got := diff.Attributes[attr]; got == nil || got.New != want {
+					t.Errorf("unexpected planned %s: %+v, want %q", attr, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFlattenUpstreamCertificate(t *testing.T) {
+	t.Parallel()
+
+	key := testECDSAKey(t)
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		notAfter    time.Time
+		warningDays int
+		warning     string
+	}{
+		{name: "outside window", notAfter: now.Add(60 * 24 * time.Hour), warningDays: 30},
+		{name: "inside window", notAfter: now.Add(10 * 24 * time.Hour), warningDays: 30, warning: "Upstream certificate expires soon"},
+		{name: "window disabled", notAfter: now.Add(10 * 24 * time.Hour), warningDays: 0},
+		{name: "expired", notAfter: now.Add(-time.Hour), warningDays: 0, warning: "Upstream certificate has expired"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cert := testCertificatePEM(t, key, now.Add(-90*24*time.Hour), tc.notAfter)
+			d := schema.TestResourceDataRaw(t, resourceRepositoryUpstream().Schema, map[string]interface{}{
+				UpstreamType:                 Docker,
+				AuthCertificate:              cert,
+				AuthCertificateKey:           testPrivateKeyPEM(t, key),
+				CertificateExpiryWarningDays: tc.warningDays,
+			})
+
+			diagnostics := flattenUpstreamCertificate(d, now)
+			if tc.warning == "" {
+				if len(diagnostics) != 0 {
+					t.Fatalf("unexpected diagnostics: %v", diagnostics)
+				}
+			} else if len(diagnostics) != 1 || diagnostics[0].Severity != diag.Warning || diagnostics[0].Summary != tc.warning {
+				t.Fatalf("expected warning %q, got: %v", tc.warning, diagnostics)
+			}
+
+			if got := d.Get(CertificateNotAfter).(string); got != timeToString(tc.notAfter.UTC()) {
+				t.Errorf("unexpected %s %q", CertificateNotAfter, got)
+			}
+			if got := d.Get(CertificateSubject).(string); got != "CN=test.cloudsmith.io,O=Cloudsmith Test" {
+				t.Errorf("unexpected %s %q", CertificateSubject, got)
+			}
+		})
+	}
+}
+
+func TestSetDefaultCertificateExpiryWarningDays(t *testing.T) {
+	t.Parallel()
+
+	r := resourceRepositoryUpstream()
+
+	// State saved before certificate_expiry_warning_days existed holds null.
+	attributes := map[string]cty.Value{}
+	for name, attrType := range r.CoreConfigSchema().ImpliedType().AttributeTypes() {
+		attributes[name] = cty.NullVal(attrType)
+	}
+	attributes["id"] = cty.StringVal("abcdef123456")
+
+	d := r.Data(&terraform.InstanceState{
+		ID:       "abcdef123456",
+		RawState: cty.ObjectVal(attributes),
+	})
+	setDefaultCertificateExpiryWarningDays(d)
+	if got := d.Get(CertificateExpiryWarningDays).(int); got != defaultCertificateExpiryWarningDays {
+		t.Fatalf("%s = %d, want %d", CertificateExpiryWarningDays, got, defaultCertificateExpiryWarningDays)
+	}
+
+	attributes[CertificateExpiryWarningDays] = cty.NumberIntVal(0)
+	d = r.Data(&terraform.InstanceState{
+		ID:         "abcdef123456",
+		Attributes: map[string]string{CertificateExpiryWarningDays: "0"},
+		RawState:   cty.ObjectVal(attributes),
+	})
+	setDefaultCertificateExpiryWarningDays(d)
+	if got := d.Get(CertificateExpiryWarningDays).(int); got != 0 {
+		t.Fatalf("%s = %d, want the value in state to be kept", CertificateExpiryWarningDays, got)
+	}
+}
@@ -0,0 +1,138 @@
+package cloudsmith
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// checkDistroVersion checks that value, the <distro>/<version> set in key, is
+// a version Cloudsmith supports for format.
+func checkDistroVersion(distros []distro, format, key, value string) error {
+	distroSlug, versionSlug, _ := strings.Cut(value, "/")
+
+	var supported []string
+	for _, dist := range distros {
+		if dist.format != format {
+			continue
+		}
+		supported = append(supported, dist.slug)
+		if dist.slug != distroSlug {
+			continue
+		}
+
+		versions := make([]string, len(dist.versions))
+		for i, version := range dist.versions {
+			if version.slug == versionSlug {
+				return nil
+			}
+			versions[i] = version.slug
+		}
+		return fmt.Errorf("%q %q is not a supported version of %s; supported versions are: %s", key, value, dist.slug, strings.Join(versions, ", "))
+	}
+
+	return fmt.Errorf(
+		"%q %q is not a supported %s distribution; it must be of the form <distribution>/<version>, where the distribution is one of: %s. The cloudsmith_distros data source lists the supported versions",
+		key, value, format, strings.Join(supported, ", "),
+	)
+}
+
+// upstreamChange is implemented by both schema.ResourceDiff and
+// schema.ResourceData, so the distribution versions can be checked when
+// planning and when applying.
+type upstreamChange interface {
+	Get(key string) interface{}
+	GetChange(key string) (interface{}, interface{})
+	HasChange(key string) bool
+}
+
+// upstreamDistroKey returns the attribute holding the distribution versions of
+// upstreamType, or "" if it has none. Alpine upstreams have no distribution
+// attribute, as the distribution of an Alpine package is set when it is
+// uploaded.
+func upstreamDistroKey(upstreamType string) string {
+	switch upstreamType {
+	case Deb:
+		return DistroVersions
+	case Rpm:
+		return DistroVersion
+	}
+	return ""
+}
+
+// newUpstreamDistroVersions returns the values of key that are new in this
+// change. Values already in state aren't returned, so that an upstream keeps
+// planning cleanly after Cloudsmith drops support for its distribution
+// version.
+func newUpstreamDistroVersions(d upstreamChange, key string) []string {
+	switch key {
+	case DistroVersions:
+		o, n := d.GetChange(key)
+		var added []string
+		for _, v := range n.(*schema.Set).Difference(o.(*schema.Set)).List() {
+			added = append(added, v.(string))
+		}
+		return added
+	case DistroVersion:
+		if !d.HasChange(key) {
+			return nil
+		}
+		if value := d.Get(key).(string); value != "" {
+			return []string{value}
+		}
+	}
+	return nil
+}
+
+// checkUpstreamDistros checks the new distribution versions of the upstream
+// against the distributions Cloudsmith supports. If they can't be listed, the
+// check is skipped with a warning rather than failing.
+//
+// upstream_distribution and component aren't checked, as they name a suite and
+// component of the upstream repository itself rather than anything Cloudsmith
+// can list.
+func checkUpstreamDistros(pc *providerConfig, d upstreamChange, upstreamType string) (diag.Diagnostics, error) {
+	key := upstreamDistroKey(upstreamType)
+	values := newUpstreamDistroVersions(d, key)
+	if key == "" || len(values) == 0 {
+		return nil, nil
+	}
+
+	distros, err := listDistros(pc)
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Warning,
+			Summary:       "Distribution versions not validated",
+			Detail:        fmt.Sprintf("The distributions supported by Cloudsmith couldn't be listed, so %s of the %s upstream wasn't checked: %s", key, upstreamType, err),
+			AttributePath: cty.GetAttrPath(key),
+		}}, nil
+	}
+
+	for _, value := range values {
+		if err := checkDistroVersion(distros, upstreamType, key, value); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// customizeDiffUpstreamDistros checks distro_version and distro_versions when
+// planning. CustomizeDiff can't return warnings, so if the distributions can't
+// be listed the check is left to checkUpstreamDistros when applying.
+func customizeDiffUpstreamDistros(d *schema.ResourceDiff, m interface{}) error {
+	pc, ok := m.(*providerConfig)
+	if !ok || !d.NewValueKnown(UpstreamType) {
+		return nil
+	}
+
+	upstreamType := d.Get(UpstreamType).(string)
+	if key := upstreamDistroKey(upstreamType); key == "" || !d.NewValueKnown(key) {
+		return nil
+	}
+
+	_, err := checkUpstreamDistros(pc, d, upstreamType)
+	return err
+}
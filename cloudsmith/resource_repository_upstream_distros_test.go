@@ -0,0 +1,147 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestCheckDistroVersion(t *testing.T) {
+	t.Parallel()
+
+	distros := []distro{
+		{slug: "el", format: Rpm, versions: []distroVersion{{slug: "8"}, {slug: "9"}}},
+		{slug: "ubuntu", format: Deb, versions: []distroVersion{{slug: "focal"}, {slug: "jammy"}}},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		value  string
+		err    string
+	}{
+		{name: "supported", format: Deb, value: "ubuntu/jammy"},
+		{name: "unknown version", format: Deb, value: "ubuntu/jamy", err: "supported versions are: focal, jammy"},
+		{name: "unknown distribution", format: Deb, value: "ubunty/jammy", err: "where the distribution is one of: ubuntu"},
+		{name: "other format", format: Rpm, value: "ubuntu/jammy", err: "is not a supported rpm distribution"},
+		{name: "missing version", format: Rpm, value: "el", err: "supported versions are: 8, 9"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkDistroVersion(distros, tc.format, DistroVersion, tc.value)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestCustomizeDiffUpstreamDistros(t *testing.T) {
+	t.Parallel()
+
+	var requests int
+	server := distrosTestServer(t, &requests)
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		state  map[string]string
+		err    string
+	}{
+		{name: "deb", config: map[string]interface{}{UpstreamType: Deb, DistroVersions: []interface{}{"ubuntu/jammy", "debian/bookworm"}}},
+		{name: "deb typo", config: map[string]interface{}{UpstreamType: Deb, DistroVersions: []interface{}{"ubuntu/jammy", "ubuntu/jamy"}}, err: `"distro_versions" "ubuntu/jamy" is not a supported version of ubuntu`},
+		{name: "rpm typo", config: map[string]interface{}{UpstreamType: Rpm, DistroVersion: "el/10"}, err: `"distro_version" "el/10" is not a supported version of el`},
+		{
+			// Suites and components of the upstream repository aren't listed
+			// by Cloudsmith, so they aren't checked.
+			name: "deb suite and component",
+			config: map[string]interface{}{
+				UpstreamType:         Deb,
+				UpstreamDistribution: "bookworm-updates",
+				Component:            "non-free-firmware",
+			},
+		},
+		{name: "alpine", config: map[string]interface{}{UpstreamType: Alpine}},
+		{
+			name:   "unsupported version already in state",
+			config: map[string]interface{}{UpstreamType: Rpm, DistroVersion: "el/7"},
+			state:  map[string]string{UpstreamType: Rpm, DistroVersion: "el/7"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := upstreamFormatTestConfig(tc.config[UpstreamType].(string))
+			for k, v := range tc.config {
+				config[k] = v
+			}
+			r := resourceRepositoryUpstream()
+			state := &terraform.InstanceState{RawConfig: testRawConfig(r, config)}
+			if tc.state != nil {
+				state.ID = "abcdef123456"
+				state.Attributes = tc.state
+			}
+
+			_, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), pc)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestCheckUpstreamDistrosListFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"detail":"Unavailable."}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	config := upstreamFormatTestConfig(Rpm)
+	config[DistroVersion] = "el/10"
+
+	r := resourceRepositoryUpstream()
+	state := &terraform.InstanceState{RawConfig: testRawConfig(r, config)}
+	if _, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), pc); err != nil {
+		t.Fatalf("expected the plan to succeed when distributions can't be listed, got: %v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRepositoryUpstream().Schema, config)
+	diags, err := checkUpstreamDistros(pc, d, Rpm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Detail, "distro_version of the rpm upstream wasn't checked") {
+		t.Fatalf("expected a warning that distro_version wasn't checked, got: %v", diags)
+	}
+}
@@ -0,0 +1,726 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// upstreamCommon holds the request values shared by every upstream format.
+type upstreamCommon struct {
+	authMode     *string
+	authSecret   *string
+	authUsername *string
+	extraHeader1 *string
+	extraHeader2 *string
+	extraValue1  *string
+	extraValue2  *string
+	isActive     *bool
+	mode         *string
+	name         string
+	priority     *int64
+	upstreamUrl  string
+	verifySsl    *bool
+}
+
+func expandUpstreamCommon(d *schema.ResourceData) upstreamCommon {
+	return upstreamCommon{
+		authMode:     optionalString(d, AuthMode),
+		authSecret:   secretString(d, AuthSecret, AuthSecretWo),
+		authUsername: optionalString(d, AuthUsername),
+		extraHeader1: optionalString(d, ExtraHeader1),
+		extraHeader2: optionalString(d, ExtraHeader2),
+		extraValue1:  secretString(d, ExtraValue1, ExtraValue1Wo),
+		extraValue2:  secretString(d, ExtraValue2, ExtraValue2Wo),
+		isActive:     optionalBool(d, IsActive),
+		mode:         optionalString(d, Mode),
+		name:         requiredString(d, Name),
+		priority:     optionalInt64(d, Priority),
+		upstreamUrl:  requiredString(d, UpstreamUrl),
+		verifySsl:    optionalBool(d, VerifySsl),
+	}
+}
+
+// upstreamRequest is implemented by the request type of every upstream
+// format, so the fields they share are set in one place.
+type upstreamRequest interface {
+	SetAuthMode(v string)
+	SetAuthSecret(v string)
+	SetAuthSecretNil()
+	SetAuthUsername(v string)
+	SetAuthUsernameNil()
+	SetExtraHeader1(v string)
+	SetExtraHeader1Nil()
+	SetExtraHeader2(v string)
+	SetExtraHeader2Nil()
+	SetExtraValue1(v string)
+	SetExtraValue1Nil()
+	SetExtraValue2(v string)
+	SetExtraValue2Nil()
+	SetIsActive(v bool)
+	SetMode(v string)
+	SetName(v string)
+	SetPriority(v int64)
+	SetUpstreamUrl(v string)
+	SetVerifySsl(v bool)
+}
+
+// setNullable sends value, or an explicit null if it isn't set.
+func setNullable(value *string, set func(string), setNil func()) {
+	if value == nil {
+		setNil()
+		return
+	}
+	set(*value)
+}
+
+// apply sets the shared fields of req.
+func (u upstreamCommon) apply(req upstreamRequest) {
+	if u.authMode != nil {
+		req.SetAuthMode(*u.authMode)
+	}
+	setNullable(u.authSecret, req.SetAuthSecret, req.SetAuthSecretNil)
+	setNullable(u.authUsername, req.SetAuthUsername, req.SetAuthUsernameNil)
+	setNullable(u.extraHeader1, req.SetExtraHeader1, req.SetExtraHeader1Nil)
+	setNullable(u.extraHeader2, req.SetExtraHeader2, req.SetExtraHeader2Nil)
+	setNullable(u.extraValue1, req.SetExtraValue1, req.SetExtraValue1Nil)
+	setNullable(u.extraValue2, req.SetExtraValue2, req.SetExtraValue2Nil)
+	if u.isActive != nil {
+		req.SetIsActive(*u.isActive)
+	}
+	if u.mode != nil {
+		req.SetMode(*u.mode)
+	}
+	req.SetName(u.name)
+	if u.priority != nil {
+		req.SetPriority(*u.priority)
+	}
+	req.SetUpstreamUrl(u.upstreamUrl)
+	if u.verifySsl != nil {
+		req.SetVerifySsl(*u.verifySsl)
+	}
+}
+
+// upstreamOptionsRequest is implemented by the request types of formats with
+// the trust and cache blocks. Formats with the pending_validation block also
+// implement upstreamValidationRequest.
+type upstreamOptionsRequest interface {
+	SetTrustLevel(v string)
+	SetCacheLifetime(v int64)
+}
+
+type upstreamValidationRequest interface {
+	SetPendingValidation(v bool)
+}
+
+// upstreamOptionsResponse and upstreamValidationResponse are the response
+// counterparts of upstreamOptionsRequest and upstreamValidationRequest.
+type upstreamOptionsResponse interface {
+	GetTrustLevelOk() (*string, bool)
+	GetCacheLifetimeOk() (*int64, bool)
+}
+
+type upstreamValidationResponse interface {
+	GetPendingValidationOk() (*bool, bool)
+}
+
+// upstreamBlock returns the attributes of a MaxItems: 1 block, or nil if it
+// isn't configured.
+func upstreamBlock(d *schema.ResourceData, key string) map[string]interface{} {
+	blocks, ok := d.Get(key).([]interface{})
+	if !ok || len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+	return blocks[0].(map[string]interface{})
+}
+
+// expandUpstreamOptions sets the request fields of the trust, cache and
+// pending_validation blocks. A block that isn't configured isn't sent, which
+// leaves the setting unchanged in Cloudsmith.
+func expandUpstreamOptions[Req any, PReq interface {
+	*Req
+	upstreamOptionsRequest
+}](d *schema.ResourceData, req *Req) error {
+	if block := upstreamBlock(d, UpstreamTrust); block != nil {
+		PReq(req).SetTrustLevel(block["level"].(string))
+	}
+	if block := upstreamBlock(d, UpstreamCache); block != nil {
+		PReq(req).SetCacheLifetime(int64(block["lifetime"].(int)))
+	}
+	if r, ok := any(req).(upstreamValidationRequest); ok {
+		if block := upstreamBlock(d, UpstreamPendingValidation); block != nil {
+			r.SetPendingValidation(block["enabled"].(bool))
+		}
+	}
+	return nil
+}
+
+// flattenUpstreamOptions sets the trust, cache and pending_validation blocks
+// from the settings the API returned.
+func flattenUpstreamOptions[Resp upstreamOptionsResponse](d *schema.ResourceData, upstream Resp) {
+	if level, ok := upstream.GetTrustLevelOk(); ok {
+		_ = d.Set(UpstreamTrust, []interface{}{map[string]interface{}{"level": *level}})
+	}
+	if lifetime, ok := upstream.GetCacheLifetimeOk(); ok {
+		_ = d.Set(UpstreamCache, []interface{}{map[string]interface{}{"lifetime": *lifetime}})
+	}
+	if u, ok := any(upstream).(upstreamValidationResponse); ok {
+		if enabled, ok := u.GetPendingValidationOk(); ok {
+			_ = d.Set(UpstreamPendingValidation, []interface{}{map[string]interface{}{"enabled": *enabled}})
+		}
+	}
+}
+
+// upstreamSpec declares one upstream format: its API endpoints and the
+// format-specific attributes it reads, writes and validates. The fields shared
+// by every format are set by upstreamCommon, so only api is required.
+type upstreamSpec[Req any, Resp Upstream] struct {
+	// fields lists the attributes that only apply to this format. Setting any
+	// other format's fields is rejected at plan time.
+	fields []string
+	// validate checks the format-specific configuration at plan time.
+	validate func(d *schema.ResourceDiff) error
+	// flatten sets the format-specific attributes from an API response.
+	flatten func(d *schema.ResourceData, upstream Resp)
+	// expand sets the format-specific request fields.
+	expand func(d *schema.ResourceData, req *Req) error
+
+	api upstreamAPI[Req, Resp]
+}
+
+// upstreamAPI holds the typed API calls of one upstream format.
+type upstreamAPI[Req any, Resp Upstream] struct {
+	create func(pc *providerConfig, namespace, repository string, data Req) (Resp, *http.Response, error)
+	read   func(pc *providerConfig, namespace, repository, slugPerm string) (Resp, *http.Response, error)
+	update func(pc *providerConfig, namespace, repository, slugPerm string, data Req) (Resp, *http.Response, error)
+	delete func(pc *providerConfig, namespace, repository, slugPerm string) (*http.Response, error)
+	list   func(pc *providerConfig, namespace, repository string, page, pageSize int64) ([]Upstream, *http.Response, error)
+	// setPriority changes only the priority of an upstream.
+	setPriority func(pc *providerConfig, namespace, repository, slugPerm string, priority int64) (*http.Response, error)
+}
+
+// reposAPI names the ReposApi service in method expressions, e.g.
+// reposAPI.ReposUpstreamAlpineCreate.
+type reposAPI = *cloudsmith.ReposApiService
+
+// upstreamDataCall is a request builder that sends a request body.
+type upstreamDataCall[B, Data, Resp any] interface {
+	Data(data Data) B
+	Execute() (Resp, *http.Response, error)
+}
+
+// upstreamReadCall is a request builder without a request body.
+type upstreamReadCall[Resp any] interface {
+	Execute() (Resp, *http.Response, error)
+}
+
+// upstreamDeleteCall is a request builder without a response body.
+type upstreamDeleteCall interface {
+	Execute() (*http.Response, error)
+}
+
+// upstreamListCall is a request builder for a page of upstreams.
+type upstreamListCall[B, Item any] interface {
+	Page(page int64) B
+	PageSize(pageSize int64) B
+	Execute() ([]Item, *http.Response, error)
+}
+
+// upstreamEndpoints builds the upstreamAPI of a format from its ReposApi
+// methods. The request and response types are inferred from the request
+// builders the methods return.
+func upstreamEndpoints[Req, Patch, Item any, Resp Upstream, PatchResp any,
+	PPatch interface {
+		*Patch
+		SetPriority(v int64)
+	},
+	PItem interface {
+		*Item
+		Upstream
+	},
+	C upstreamDataCall[C, Req, Resp],
+	R upstreamReadCall[Resp],
+	U upstreamDataCall[U, Req, Resp],
+	D upstreamDeleteCall,
+	L upstreamListCall[L, Item],
+	P upstreamDataCall[P, Patch, PatchResp],
+](
+	create func(reposAPI, context.Context, string, string) C,
+	read func(reposAPI, context.Context, string, string, string) R,
+	update func(reposAPI, context.Context, string, string, string) U,
+	del func(reposAPI, context.Context, string, string, string) D,
+	list func(reposAPI, context.Context, string, string) L,
+	partialUpdate func(reposAPI, context.Context, string, string, string) P,
+) upstreamAPI[Req, Resp] {
+	return upstreamAPI[Req, Resp]{
+		create: func(pc *providerConfig, namespace, repository string, data Req) (Resp, *http.Response, error) {
+			return create(pc.APIClient.ReposApi, pc.Auth, namespace, repository).Data(data).Execute()
+		},
+		read: func(pc *providerConfig, namespace, repository, slugPerm string) (Resp, *http.Response, error) {
+			return read(pc.APIClient.ReposApi, pc.Auth, namespace, repository, slugPerm).Execute()
+		},
+		update: func(pc *providerConfig, namespace, repository, slugPerm string, data Req) (Resp, *http.Response, error) {
+			return update(pc.APIClient.ReposApi, pc.Auth, namespace, repository, slugPerm).Data(data).Execute()
+		},
+		delete: func(pc *providerConfig, namespace, repository, slugPerm string) (*http.Response, error) {
+			return del(pc.APIClient.ReposApi, pc.Auth, namespace, repository, slugPerm).Execute()
+		},
+		list: func(pc *providerConfig, namespace, repository string, page, pageSize int64) ([]Upstream, *http.Response, error) {
+			items, resp, err := list(pc.APIClient.ReposApi, pc.Auth, namespace, repository).Page(page).PageSize(pageSize).Execute()
+			upstreams := make([]Upstream, len(items))
+			for i := range items {
+				upstreams[i] = PItem(&items[i])
+			}
+			return upstreams, resp, err
+		},
+		setPriority: func(pc *providerConfig, namespace, repository, slugPerm string, priority int64) (*http.Response, error) {
+			var data Patch
+			PPatch(&data).SetPriority(priority)
+			_, resp, err := partialUpdate(pc.APIClient.ReposApi, pc.Auth, namespace, repository, slugPerm).Data(data).Execute()
+			return resp, err
+		},
+	}
+}
+
+// upstreamFormat is the type-erased form of an upstreamSpec used by the
+// generic CRUD functions of cloudsmith_repository_upstream.
+type upstreamFormat struct {
+	fields   []string
+	validate func(d *schema.ResourceDiff) error
+	flatten  func(d *schema.ResourceData, upstream Upstream)
+	create   func(pc *providerConfig, namespace, repository string, d *schema.ResourceData, common upstreamCommon) (Upstream, *http.Response, error)
+	read     func(pc *providerConfig, namespace, repository, slugPerm string) (Upstream, *http.Response, error)
+	update   func(pc *providerConfig, namespace, repository, slugPerm string, d *schema.ResourceData) (Upstream, *http.Response, error)
+	delete   func(pc *providerConfig, namespace, repository, slugPerm string) (*http.Response, error)
+	list     func(pc *providerConfig, namespace, repository string, page, pageSize int64) ([]Upstream, *http.Response, error)
+
+	setPriority func(pc *providerConfig, namespace, repository, slugPerm string, priority int64) (*http.Response, error)
+}
+
+func newUpstreamFormat[Req any, PReq interface {
+	*Req
+	upstreamRequest
+}, Resp Upstream](spec upstreamSpec[Req, Resp]) upstreamFormat {
+	build := func(d *schema.ResourceData, common upstreamCommon) (Req, error) {
+		var req Req
+		common.apply(PReq(&req))
+		if spec.expand != nil {
+			if err := spec.expand(d, &req); err != nil {
+				return req, err
+			}
+		}
+		return req, nil
+	}
+
+	format := upstreamFormat{
+		fields:   spec.fields,
+		validate: spec.validate,
+		create: func(pc *providerConfig, namespace, repository string, d *schema.ResourceData, common upstreamCommon) (Upstream, *http.Response, error) {
+			data, err := build(d, common)
+			if err != nil {
+				return nil, nil, err
+			}
+			upstream, resp, err := spec.api.create(pc, namespace, repository, data)
+			if err != nil {
+				return nil, resp, err
+			}
+			return upstream, resp, nil
+		},
+		read: func(pc *providerConfig, namespace, repository, slugPerm string) (Upstream, *http.Response, error) {
+			upstream, resp, err := spec.api.read(pc, namespace, repository, slugPerm)
+			if err != nil {
+				return nil, resp, err
+			}
+			return upstream, resp, nil
+		},
+		update: func(pc *providerConfig, namespace, repository, slugPerm string, d *schema.ResourceData) (Upstream, *http.Response, error) {
+			data, err := build(d, expandUpstreamCommon(d))
+			if err != nil {
+				return nil, nil, err
+			}
+			upstream, resp, err := spec.api.update(pc, namespace, repository, slugPerm, data)
+			if err != nil {
+				return nil, resp, err
+			}
+			return upstream, resp, nil
+		},
+		delete:      spec.api.delete,
+		list:        spec.api.list,
+		setPriority: spec.api.setPriority,
+	}
+	if spec.flatten != nil {
+		format.flatten = func(d *schema.ResourceData, upstream Upstream) {
+			if u, ok := upstream.(Resp); ok {
+				spec.flatten(d, u)
+			}
+		}
+	}
+	return format
+}
+
+// lookupUpstreamFormat returns the registered format for upstreamType.
+func lookupUpstreamFormat(upstreamType string) (upstreamFormat, error) {
+	format, ok := upstreamFormats[upstreamType]
+	if !ok {
+		return upstreamFormat{}, fmt.Errorf("invalid upstream_type: '%s'", upstreamType)
+	}
+	return format, nil
+}
+
+// upstreamFormatTypes returns the registered upstream types in order.
+func upstreamFormatTypes() []string {
+	types := make([]string, 0, len(upstreamFormats))
+	for t := range upstreamFormats {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// upstreamFormatFields returns every format-specific attribute, across all
+// formats.
+func upstreamFormatFields() []string {
+	var fields []string
+	for _, format := range upstreamFormats {
+		for _, field := range format.fields {
+			if !contains(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// upstreamComputedFields are the format-specific attributes that are read back
+// from the API. When they aren't configured, the planned value is the one in
+// state, which may belong to a previous upstream_type.
+var upstreamComputedFields = []string{Component, IncludeSources, UpstreamCache, UpstreamPendingValidation, UpstreamPrefix, UpstreamTrust}
+
+// upstreamFieldConfigured reports whether key is set in the configuration,
+// including to a value that isn't known yet. The planned value isn't used, as
+// computed attributes keep their value from state.
+func upstreamFieldConfigured(d *schema.ResourceDiff, key string) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() {
+		return false
+	}
+	value := config.GetAttr(key)
+	switch {
+	case value.IsNull():
+		return false
+	case !value.IsKnown():
+		return true
+	case value.Type().IsListType() || value.Type().IsSetType():
+		return value.LengthInt() > 0
+	}
+	return true
+}
+
+// validateUpstreamRequired returns a validate function requiring key to be set.
+func validateUpstreamRequired(key string) func(d *schema.ResourceDiff) error {
+	return func(d *schema.ResourceDiff) error {
+		if !upstreamFieldConfigured(d, key) {
+			return fmt.Errorf("%q is required for %s upstreams", key, d.Get(UpstreamType))
+		}
+		return nil
+	}
+}
+
+// validateUpstreamCertificate requires the mTLS certificate and key to be set
+// together, then parses and checks them.
+func validateUpstreamCertificate(d *schema.ResourceDiff) error {
+	if upstreamFieldConfigured(d, AuthCertificate) != upstreamFieldConfigured(d, AuthCertificateKey) {
+		return fmt.Errorf("both %s and %s must be provided when using Certificate and Key authentication", AuthCertificate, AuthCertificateKey)
+	}
+	return customizeDiffUpstreamCertificate(d, time.Now())
+}
+
+// customizeDiffUpstreamFormat rejects attributes that don't apply to the
+// configured upstream_type and runs the format's own validation.
+func customizeDiffUpstreamFormat(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.NewValueKnown(UpstreamType) {
+		return nil
+	}
+	upstreamType := d.Get(UpstreamType).(string)
+	format, err := lookupUpstreamFormat(upstreamType)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range upstreamFormatFields() {
+		if !contains(format.fields, field) && upstreamFieldConfigured(d, field) {
+			return fmt.Errorf("%q is not supported for %s upstreams", field, upstreamType)
+		}
+	}
+
+	if format.validate != nil {
+		return format.validate(d)
+	}
+	return nil
+}
+
+// upstreamFormats is the registry of supported upstream types. To support a new
+// format, add an entry declaring its request type, ReposApi methods and any
+// format-specific fields, then add the fields to the resource schema.
+var upstreamFormats = map[string]upstreamFormat{
+	Alpine: newUpstreamFormat(upstreamSpec[cloudsmith.AlpineUpstreamRequest, *cloudsmith.AlpineUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamAlpineCreate,
+			reposAPI.ReposUpstreamAlpineRead,
+			reposAPI.ReposUpstreamAlpineUpdate,
+			reposAPI.ReposUpstreamAlpineDelete,
+			reposAPI.ReposUpstreamAlpineList,
+			reposAPI.ReposUpstreamAlpinePartialUpdate,
+		),
+	}),
+	Cargo: newUpstreamFormat(upstreamSpec[cloudsmith.CargoUpstreamRequest, *cloudsmith.CargoUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamCargoCreate,
+			reposAPI.ReposUpstreamCargoRead,
+			reposAPI.ReposUpstreamCargoUpdate,
+			reposAPI.ReposUpstreamCargoDelete,
+			reposAPI.ReposUpstreamCargoList,
+			reposAPI.ReposUpstreamCargoPartialUpdate,
+		),
+	}),
+	Composer: newUpstreamFormat(upstreamSpec[cloudsmith.ComposerUpstreamRequest, *cloudsmith.ComposerUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamComposerCreate,
+			reposAPI.ReposUpstreamComposerRead,
+			reposAPI.ReposUpstreamComposerUpdate,
+			reposAPI.ReposUpstreamComposerDelete,
+			reposAPI.ReposUpstreamComposerList,
+			reposAPI.ReposUpstreamComposerPartialUpdate,
+		),
+	}),
+	Conda: newUpstreamFormat(upstreamSpec[cloudsmith.CondaUpstreamRequest, *cloudsmith.CondaUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamCondaCreate,
+			reposAPI.ReposUpstreamCondaRead,
+			reposAPI.ReposUpstreamCondaUpdate,
+			reposAPI.ReposUpstreamCondaDelete,
+			reposAPI.ReposUpstreamCondaList,
+			reposAPI.ReposUpstreamCondaPartialUpdate,
+		),
+	}),
+	Cran: newUpstreamFormat(upstreamSpec[cloudsmith.CranUpstreamRequest, *cloudsmith.CranUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamCranCreate,
+			reposAPI.ReposUpstreamCranRead,
+			reposAPI.ReposUpstreamCranUpdate,
+			reposAPI.ReposUpstreamCranDelete,
+			reposAPI.ReposUpstreamCranList,
+			reposAPI.ReposUpstreamCranPartialUpdate,
+		),
+	}),
+	Dart: newUpstreamFormat(upstreamSpec[cloudsmith.DartUpstreamRequest, *cloudsmith.DartUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamDartCreate,
+			reposAPI.ReposUpstreamDartRead,
+			reposAPI.ReposUpstreamDartUpdate,
+			reposAPI.ReposUpstreamDartDelete,
+			reposAPI.ReposUpstreamDartList,
+			reposAPI.ReposUpstreamDartPartialUpdate,
+		),
+	}),
+	Deb: newUpstreamFormat(upstreamSpec[cloudsmith.DebUpstreamRequest, *cloudsmith.DebUpstream]{
+		fields: []string{Component, DistroVersions, IncludeSources, UpstreamDistribution},
+		flatten: func(d *schema.ResourceData, u *cloudsmith.DebUpstream) {
+			_ = d.Set(Component, u.GetComponent())
+			_ = d.Set(DistroVersions, flattenStrings(u.GetDistroVersions()))
+			_ = d.Set(IncludeSources, u.GetIncludeSources())
+			_ = d.Set(UpstreamDistribution, u.GetUpstreamDistribution())
+		},
+		expand: func(d *schema.ResourceData, req *cloudsmith.DebUpstreamRequest) error {
+			req.Component = optionalString(d, Component)
+			req.DistroVersions = expandStrings(d, DistroVersions)
+			req.IncludeSources = optionalBool(d, IncludeSources)
+			req.UpstreamDistribution = nullableString(d, UpstreamDistribution)
+			return nil
+		},
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamDebCreate,
+			reposAPI.ReposUpstreamDebRead,
+			reposAPI.ReposUpstreamDebUpdate,
+			reposAPI.ReposUpstreamDebDelete,
+			reposAPI.ReposUpstreamDebList,
+			reposAPI.ReposUpstreamDebPartialUpdate,
+		),
+	}),
+	Docker: newUpstreamFormat(upstreamSpec[cloudsmith.DockerUpstreamRequest, *cloudsmith.DockerUpstream]{
+		fields:   []string{AuthCertificate, AuthCertificateKey, UpstreamTrust, UpstreamCache},
+		validate: validateUpstreamCertificate,
+		flatten:  flattenUpstreamOptions[*cloudsmith.DockerUpstream],
+		expand: func(d *schema.ResourceData, req *cloudsmith.DockerUpstreamRequest) error {
+			authCert, authCertKey, err := readCertificateFiles(d)
+			if err != nil {
+				return err
+			}
+			req.AuthCertificate = authCert
+			req.AuthCertificateKey = authCertKey
+			return expandUpstreamOptions(d, req)
+		},
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamDockerCreate,
+			reposAPI.ReposUpstreamDockerRead,
+			reposAPI.ReposUpstreamDockerUpdate,
+			reposAPI.ReposUpstreamDockerDelete,
+			reposAPI.ReposUpstreamDockerList,
+			reposAPI.ReposUpstreamDockerPartialUpdate,
+		),
+	}),
+	Generic: newUpstreamFormat(upstreamSpec[cloudsmith.GenericUpstreamRequest, *cloudsmith.GenericUpstream]{
+		fields: []string{UpstreamPrefix},
+		flatten: func(d *schema.ResourceData, u *cloudsmith.GenericUpstream) {
+			_ = d.Set(UpstreamPrefix, u.GetUpstreamPrefix())
+		},
+		expand: func(d *schema.ResourceData, req *cloudsmith.GenericUpstreamRequest) error {
+			req.UpstreamPrefix = optionalString(d, UpstreamPrefix)
+			return nil
+		},
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamGenericCreate,
+			reposAPI.ReposUpstreamGenericRead,
+			reposAPI.ReposUpstreamGenericUpdate,
+			reposAPI.ReposUpstreamGenericDelete,
+			reposAPI.ReposUpstreamGenericList,
+			reposAPI.ReposUpstreamGenericPartialUpdate,
+		),
+	}),
+	Go: newUpstreamFormat(upstreamSpec[cloudsmith.GoUpstreamRequest, *cloudsmith.GoUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamGoCreate,
+			reposAPI.ReposUpstreamGoRead,
+			reposAPI.ReposUpstreamGoUpdate,
+			reposAPI.ReposUpstreamGoDelete,
+			reposAPI.ReposUpstreamGoList,
+			reposAPI.ReposUpstreamGoPartialUpdate,
+		),
+	}),
+	Helm: newUpstreamFormat(upstreamSpec[cloudsmith.HelmUpstreamRequest, *cloudsmith.HelmUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamHelmCreate,
+			reposAPI.ReposUpstreamHelmRead,
+			reposAPI.ReposUpstreamHelmUpdate,
+			reposAPI.ReposUpstreamHelmDelete,
+			reposAPI.ReposUpstreamHelmList,
+			reposAPI.ReposUpstreamHelmPartialUpdate,
+		),
+	}),
+	Hex: newUpstreamFormat(upstreamSpec[cloudsmith.HexUpstreamRequest, *cloudsmith.HexUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamHexCreate,
+			reposAPI.ReposUpstreamHexRead,
+			reposAPI.ReposUpstreamHexUpdate,
+			reposAPI.ReposUpstreamHexDelete,
+			reposAPI.ReposUpstreamHexList,
+			reposAPI.ReposUpstreamHexPartialUpdate,
+		),
+	}),
+	HuggingFace: newUpstreamFormat(upstreamSpec[cloudsmith.HuggingfaceUpstreamRequest, *cloudsmith.HuggingfaceUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamHuggingfaceCreate,
+			reposAPI.ReposUpstreamHuggingfaceRead,
+			reposAPI.ReposUpstreamHuggingfaceUpdate,
+			reposAPI.ReposUpstreamHuggingfaceDelete,
+			reposAPI.ReposUpstreamHuggingfaceList,
+			reposAPI.ReposUpstreamHuggingfacePartialUpdate,
+		),
+	}),
+	Maven: newUpstreamFormat(upstreamSpec[cloudsmith.MavenUpstreamRequest, *cloudsmith.MavenUpstream]{
+		fields:  []string{UpstreamTrust, UpstreamPendingValidation, UpstreamCache},
+		flatten: flattenUpstreamOptions[*cloudsmith.MavenUpstream],
+		expand:  expandUpstreamOptions[cloudsmith.MavenUpstreamRequest],
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamMavenCreate,
+			reposAPI.ReposUpstreamMavenRead,
+			reposAPI.ReposUpstreamMavenUpdate,
+			reposAPI.ReposUpstreamMavenDelete,
+			reposAPI.ReposUpstreamMavenList,
+			reposAPI.ReposUpstreamMavenPartialUpdate,
+		),
+	}),
+	Npm: newUpstreamFormat(upstreamSpec[cloudsmith.NpmUpstreamRequest, *cloudsmith.NpmUpstream]{
+		fields:  []string{UpstreamTrust, UpstreamPendingValidation, UpstreamCache},
+		flatten: flattenUpstreamOptions[*cloudsmith.NpmUpstream],
+		expand:  expandUpstreamOptions[cloudsmith.NpmUpstreamRequest],
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamNpmCreate,
+			reposAPI.ReposUpstreamNpmRead,
+			reposAPI.ReposUpstreamNpmUpdate,
+			reposAPI.ReposUpstreamNpmDelete,
+			reposAPI.ReposUpstreamNpmList,
+			reposAPI.ReposUpstreamNpmPartialUpdate,
+		),
+	}),
+	NuGet: newUpstreamFormat(upstreamSpec[cloudsmith.NugetUpstreamRequest, *cloudsmith.NugetUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamNugetCreate,
+			reposAPI.ReposUpstreamNugetRead,
+			reposAPI.ReposUpstreamNugetUpdate,
+			reposAPI.ReposUpstreamNugetDelete,
+			reposAPI.ReposUpstreamNugetList,
+			reposAPI.ReposUpstreamNugetPartialUpdate,
+		),
+	}),
+	Python: newUpstreamFormat(upstreamSpec[cloudsmith.PythonUpstreamRequest, *cloudsmith.PythonUpstream]{
+		fields:  []string{UpstreamTrust, UpstreamPendingValidation, UpstreamCache},
+		flatten: flattenUpstreamOptions[*cloudsmith.PythonUpstream],
+		expand:  expandUpstreamOptions[cloudsmith.PythonUpstreamRequest],
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamPythonCreate,
+			reposAPI.ReposUpstreamPythonRead,
+			reposAPI.ReposUpstreamPythonUpdate,
+			reposAPI.ReposUpstreamPythonDelete,
+			reposAPI.ReposUpstreamPythonList,
+			reposAPI.ReposUpstreamPythonPartialUpdate,
+		),
+	}),
+	Rpm: newUpstreamFormat(upstreamSpec[cloudsmith.RpmUpstreamRequest, *cloudsmith.RpmUpstream]{
+		fields:   []string{DistroVersion, IncludeSources},
+		validate: validateUpstreamRequired(DistroVersion),
+		flatten: func(d *schema.ResourceData, u *cloudsmith.RpmUpstream) {
+			_ = d.Set(DistroVersion, u.GetDistroVersion())
+			_ = d.Set(IncludeSources, u.GetIncludeSources())
+		},
+		expand: func(d *schema.ResourceData, req *cloudsmith.RpmUpstreamRequest) error {
+			req.DistroVersion = requiredString(d, DistroVersion)
+			req.IncludeSources = optionalBool(d, IncludeSources)
+			return nil
+		},
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamRpmCreate,
+			reposAPI.ReposUpstreamRpmRead,
+			reposAPI.ReposUpstreamRpmUpdate,
+			reposAPI.ReposUpstreamRpmDelete,
+			reposAPI.ReposUpstreamRpmList,
+			reposAPI.ReposUpstreamRpmPartialUpdate,
+		),
+	}),
+	Ruby: newUpstreamFormat(upstreamSpec[cloudsmith.RubyUpstreamRequest, *cloudsmith.RubyUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamRubyCreate,
+			reposAPI.ReposUpstreamRubyRead,
+			reposAPI.ReposUpstreamRubyUpdate,
+			reposAPI.ReposUpstreamRubyDelete,
+			reposAPI.ReposUpstreamRubyList,
+			reposAPI.ReposUpstreamRubyPartialUpdate,
+		),
+	}),
+	Swift: newUpstreamFormat(upstreamSpec[cloudsmith.SwiftUpstreamRequest, *cloudsmith.SwiftUpstream]{
+		api: upstreamEndpoints(
+			reposAPI.ReposUpstreamSwiftCreate,
+			reposAPI.ReposUpstreamSwiftRead,
+			reposAPI.ReposUpstreamSwiftUpdate,
+			reposAPI.ReposUpstreamSwiftDelete,
+			reposAPI.ReposUpstreamSwiftList,
+			reposAPI.ReposUpstreamSwiftPartialUpdate,
+		),
+	}),
+}
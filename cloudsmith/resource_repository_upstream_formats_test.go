@@ -0,0 +1,327 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// upstreamFormatTestConfig returns a minimal valid configuration for
+// upstreamType, including any fields the format requires.
+func upstreamFormatTestConfig(upstreamType string) map[string]interface{} {
+	config := map[string]interface{}{
+		Namespace:    "example-org",
+		Repository:   "example-repo",
+		UpstreamType: upstreamType,
+		Name:         "example-upstream",
+		UpstreamUrl:  "https://upstream.example.com",
+	}
+	switch upstreamType {
+	case Deb:
+		config[DistroVersions] = []interface{}{"ubuntu/jammy"}
+		config[UpstreamDistribution] = "jammy"
+		config[Component] = "main"
+	case Generic:
+		config[UpstreamPrefix] = "example"
+	case Rpm:
+		config[DistroVersion] = "el/9"
+	}
+	if contains(upstreamFormats[upstreamType].fields, UpstreamTrust) {
+		config[UpstreamTrust] = []interface{}{map[string]interface{}{"level": "Untrusted"}}
+		config[UpstreamCache] = []interface{}{map[string]interface{}{"lifetime": 3600}}
+	}
+	if contains(upstreamFormats[upstreamType].fields, UpstreamPendingValidation) {
+		config[UpstreamPendingValidation] = []interface{}{map[string]interface{}{"enabled": true}}
+	}
+	return config
+}
+
+// testRawConfig returns config as the raw configuration value Terraform would
+// send for r. NewResourceConfigRaw doesn't populate it, so tests that exercise
+// GetRawConfig pass it in through the prior state's RawConfig.
+func testRawConfig(r *schema.Resource, config map[string]interface{}) cty.Value {
+	return testCtyValue(config, r.CoreConfigSchema().ImpliedType())
+}
+
+func testCtyValue(v interface{}, ty cty.Type) cty.Value {
+	if v == nil {
+		return cty.NullVal(ty)
+	}
+	switch {
+	case ty.IsObjectType():
+		fields := v.(map[string]interface{})
+		attrs := make(map[string]cty.Value, len(ty.AttributeTypes()))
+		for name, attrType := range ty.AttributeTypes() {
+			attrs[name] = testCtyValue(fields[name], attrType)
+		}
+		return cty.ObjectVal(attrs)
+	case ty.IsListType(), ty.IsSetType():
+		items := v.([]interface{})
+		if len(items) == 0 {
+			if ty.IsSetType() {
+				return cty.SetValEmpty(ty.ElementType())
+			}
+			return cty.ListValEmpty(ty.ElementType())
+		}
+		vals := make([]cty.Value, len(items))
+		for i, item := range items {
+			vals[i] = testCtyValue(item, ty.ElementType())
+		}
+		if ty.IsSetType() {
+			return cty.SetVal(vals)
+		}
+		return cty.ListVal(vals)
+	case ty == cty.Bool:
+		return cty.BoolVal(v.(bool))
+	case ty == cty.Number:
+		return cty.NumberIntVal(int64(v.(int)))
+	default:
+		return cty.StringVal(v.(string))
+	}
+}
+
+// upstreamOptionRequestFields maps the format-specific blocks to the request
+// fields they set.
+var upstreamOptionRequestFields = map[string]string{
+	UpstreamTrust:             "trust_level",
+	UpstreamPendingValidation: "pending_validation",
+	UpstreamCache:             "cache_lifetime",
+}
+
+// fakeUpstreamServer serves the upstream endpoints for a single format and
+// records the requests made to it.
+type fakeUpstreamServer struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   []map[string]interface{}
+}
+
+func (s *fakeUpstreamServer) handler(t *testing.T, upstreamType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, fmt.Sprintf("%s %s", r.Method, strings.TrimSuffix(r.URL.Path, "/")))
+		if r.Body != nil && (r.Method == http.MethodPost || r.Method == http.MethodPut) {
+			body := make(map[string]interface{})
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding request body: %v", err)
+			}
+			s.bodies = append(s.bodies, body)
+		}
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		upstream := map[string]interface{}{
+			"slug_perm":    "abcdef123456",
+			"name":         "example-upstream",
+			"upstream_url": "https://upstream.example.com",
+			"is_active":    true,
+		}
+		switch upstreamType {
+		case Deb:
+			upstream["distro_versions"] = []string{"ubuntu/jammy"}
+			upstream["upstream_distribution"] = "jammy"
+			upstream["component"] = "main"
+		case Generic:
+			upstream["upstream_prefix"] = "example"
+		case Rpm:
+			upstream["distro_version"] = "el/9"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_ = json.NewEncoder(w).Encode(upstream)
+	}
+}
+
+func TestUpstreamFormatsRegistered(t *testing.T) {
+	t.Parallel()
+
+	if len(upstreamTypes) != len(upstreamFormats) {
+		t.Fatalf("expected %d upstream types, got %d", len(upstreamFormats), len(upstreamTypes))
+	}
+
+	schemaMap := resourceRepositoryUpstream().Schema
+	for upstreamType, format := range upstreamFormats {
+		if format.create == nil || format.read == nil || format.update == nil || format.delete == nil || format.list == nil || format.setPriority == nil {
+			t.Errorf("%s: upstream format is missing an API call", upstreamType)
+		}
+		for _, field := range format.fields {
+			if _, ok := schemaMap[field]; !ok {
+				t.Errorf("%s: field %q is not in the resource schema", upstreamType, field)
+			}
+		}
+	}
+
+	if _, err := lookupUpstreamFormat("not-a-format"); err == nil {
+		t.Error("expected an error for an unknown upstream type")
+	}
+}
+
+// TestUpstreamFormatEndpoints runs each registered format through create, read,
+// update, priority update and delete against a fake API, checking the endpoints called and the
+// request bodies sent.
+func TestUpstreamFormatEndpoints(t *testing.T) {
+	t.Parallel()
+
+	for _, upstreamType := range upstreamTypes {
+		upstreamType := upstreamType
+		t.Run(upstreamType, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeUpstreamServer{}
+			server := httptest.NewServer(fake.handler(t, upstreamType))
+			defer server.Close()
+			pc := testPrivilegesProviderConfig(server)
+
+			format := upstreamFormats[upstreamType]
+			d := schema.TestResourceDataRaw(t, resourceRepositoryUpstream().Schema, upstreamFormatTestConfig(upstreamType))
+
+			upstream, _, err := format.create(pc, "example-org", "example-repo", d, expandUpstreamCommon(d))
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			if upstream.GetSlugPerm() != "abcdef123456" {
+				t.Fatalf("create: unexpected slug_perm %q", upstream.GetSlugPerm())
+			}
+			d.SetId(upstream.GetSlugPerm())
+
+			upstream, _, err = format.read(pc, "example-org", "example-repo", d.Id())
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if format.flatten != nil {
+				format.flatten(d, upstream)
+			}
+			if _, _, err := format.update(pc, "example-org", "example-repo", d.Id(), d); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			if _, err := format.setPriority(pc, "example-org", "example-repo", d.Id(), 2); err != nil {
+				t.Fatalf("set priority: %v", err)
+			}
+			if _, err := format.delete(pc, "example-org", "example-repo", d.Id()); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+
+			base := fmt.Sprintf("/repos/example-org/example-repo/upstream/%s", upstreamType)
+			expected := []string{
+				"POST " + base,
+				"GET " + base + "/abcdef123456",
+				"PUT " + base + "/abcdef123456",
+				"PATCH " + base + "/abcdef123456",
+				"DELETE " + base + "/abcdef123456",
+			}
+			if strings.Join(fake.requests, "\n") != strings.Join(expected, "\n") {
+				t.Fatalf("unexpected requests:\n%s\nwant:\n%s", strings.Join(fake.requests, "\n"), strings.Join(expected, "\n"))
+			}
+
+			for _, body := range fake.bodies {
+				for key, value := range upstreamFormatTestConfig(upstreamType) {
+					if field, ok := upstreamOptionRequestFields[key]; ok {
+						key = field
+					}
+					if body[key] == nil && key != Namespace && key != Repository && key != UpstreamType {
+						t.Errorf("request body is missing %s (%v): %v", key, value, body)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCustomizeDiffUpstreamFormat(t *testing.T) {
+	t.Parallel()
+
+	withFields := func(upstreamType string, fields map[string]interface{}) map[string]interface{} {
+		config := upstreamFormatTestConfig(upstreamType)
+		for k, v := range fields {
+			if v == nil {
+				delete(config, k)
+				continue
+			}
+			config[k] = v
+		}
+		return config
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		err    string
+	}{
+		{name: "python", config: withFields(Python, nil)},
+		{name: "deb", config: withFields(Deb, nil)},
+		{name: "rpm", config: withFields(Rpm, nil)},
+		{
+			name:   "rpm without distro_version",
+			config: withFields(Rpm, map[string]interface{}{DistroVersion: nil}),
+			err:    `"distro_version" is required for rpm upstreams`,
+		},
+		{
+			name:   "deb field on python",
+			config: withFields(Python, map[string]interface{}{DistroVersions: []interface{}{"ubuntu/jammy"}}),
+			err:    `"distro_versions" is not supported for python upstreams`,
+		},
+		{
+			name:   "rpm field on deb",
+			config: withFields(Deb, map[string]interface{}{DistroVersion: "el/9"}),
+			err:    `"distro_version" is not supported for deb upstreams`,
+		},
+		{
+			name:   "certificate on npm",
+			config: withFields(Npm, map[string]interface{}{AuthCertificate: "-----BEGIN CERTIFICATE-----"}),
+			err:    `"auth_certificate" is not supported for npm upstreams`,
+		},
+		{
+			name:   "trust on helm",
+			config: withFields(Helm, map[string]interface{}{UpstreamTrust: []interface{}{map[string]interface{}{"level": "Trusted"}}}),
+			err:    `"trust" is not supported for helm upstreams`,
+		},
+		{
+			name:   "pending validation on docker",
+			config: withFields(Docker, map[string]interface{}{UpstreamPendingValidation: []interface{}{map[string]interface{}{"enabled": true}}}),
+			err:    `"pending_validation" is not supported for docker upstreams`,
+		},
+		{
+			name:   "docker certificate without key",
+			config: withFields(Docker, map[string]interface{}{AuthCertificate: "-----BEGIN CERTIFICATE-----"}),
+			err:    "both auth_certificate and auth_certificate_key must be provided",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := resourceRepositoryUpstream()
+			state := &terraform.InstanceState{RawConfig: testRawConfig(r, tc.config)}
+			_, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(tc.config), nil)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got: %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const Upstreams = "upstreams"
+
+// upstreamPriority is the priority of a single upstream.
+type upstreamPriority struct {
+	slugPerm string
+	priority int64
+}
+
+// planUpstreamPriorities returns the priority changes that give the upstreams
+// in order priorities 1..n and move any other upstreams after them, keeping
+// their relative order. Only upstreams whose priority changes are updated.
+//
+// Changes are ordered so that no update takes a priority another upstream
+// still holds. Where upstreams need to swap priorities, one of them is first
+// moved to an unused priority to break the cycle.
+func planUpstreamPriorities(current []upstreamPriority, order []string) []upstreamPriority {
+	sorted := make([]upstreamPriority, len(current))
+	copy(sorted, current)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].priority != sorted[j].priority {
+			return sorted[i].priority < sorted[j].priority
+		}
+		return sorted[i].slugPerm < sorted[j].slugPerm
+	})
+
+	desired := make(map[string]int64, len(sorted))
+	for i, slugPerm := range order {
+		desired[slugPerm] = int64(i + 1)
+	}
+	next := int64(len(order))
+	for _, u := range sorted {
+		if _, ok := desired[u.slugPerm]; !ok {
+			next++
+			desired[u.slugPerm] = next
+		}
+	}
+
+	priorities := make(map[string]int64, len(sorted))
+	held := make(map[int64]int)
+	var pending []string
+	for _, u := range sorted {
+		priorities[u.slugPerm] = u.priority
+		held[u.priority]++
+		if u.priority != desired[u.slugPerm] {
+			pending = append(pending, u.slugPerm)
+		}
+		if u.priority > next {
+			next = u.priority
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return desired[pending[i]] < desired[pending[j]] })
+
+	var updates []upstreamPriority
+	move := func(slugPerm string, priority int64) {
+		held[priorities[slugPerm]]--
+		held[priority]++
+		priorities[slugPerm] = priority
+		updates = append(updates, upstreamPriority{slugPerm: slugPerm, priority: priority})
+	}
+
+	for len(pending) > 0 {
+		var remaining []string
+		for _, slugPerm := range pending {
+			if held[desired[slugPerm]] == 0 {
+				move(slugPerm, desired[slugPerm])
+				continue
+			}
+			remaining = append(remaining, slugPerm)
+		}
+		if len(remaining) == len(pending) {
+			next++
+			move(remaining[0], next)
+		}
+		pending = remaining
+	}
+	return updates
+}
+
+// upstreamOrderFromPriorities returns the order that read reports: the
+// upstreams in priority order, up to the last of the managed upstreams. Any
+// unmanaged upstream placed among the managed ones is included so that the
+// change shows as drift. If managed is empty, as on import, every upstream is
+// included.
+func upstreamOrderFromPriorities(current []upstreamPriority, managed []string) []string {
+	sorted := make([]upstreamPriority, len(current))
+	copy(sorted, current)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].priority != sorted[j].priority {
+			return sorted[i].priority < sorted[j].priority
+		}
+		return sorted[i].slugPerm < sorted[j].slugPerm
+	})
+
+	last := -1
+	for i, u := range sorted {
+		if contains(managed, u.slugPerm) {
+			last = i
+		}
+	}
+	if len(managed) == 0 {
+		last = len(sorted) - 1
+	}
+
+	order := make([]string, 0, last+1)
+	for _, u := range sorted[:last+1] {
+		order = append(order, u.slugPerm)
+	}
+	return order
+}
+
+func importRepositoryUpstreamOrder(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	idParts := strings.Split(d.Id(), ".")
+	if len(idParts) != 3 {
+		return nil, fmt.Errorf(
+			"invalid import ID, must be of the form <namespace_slug>.<repository_slug>.<upstream_type>, got: %s", d.Id(),
+		)
+	}
+
+	_ = d.Set(Namespace, idParts[0])
+	_ = d.Set(Repository, idParts[1])
+	_ = d.Set(UpstreamType, idParts[2])
+	return []*schema.ResourceData{d}, nil
+}
+
+// readUpstreamPriorities returns the priority of every upstream of the
+// configured type.
+func readUpstreamPriorities(d *schema.ResourceData, m interface{}) ([]upstreamPriority, error) {
+	pc := m.(*providerConfig)
+
+	listed, err := listUpstreams(pc, requiredString(d, Namespace), requiredString(d, Repository), []string{requiredString(d, UpstreamType)})
+	if err != nil {
+		return nil, err
+	}
+
+	current := make([]upstreamPriority, len(listed))
+	for i, l := range listed {
+		current[i] = upstreamPriority{slugPerm: l.upstream.GetSlugPerm(), priority: l.upstream.GetPriority()}
+	}
+	return current, nil
+}
+
+func resourceRepositoryUpstreamOrderUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, Namespace)
+	repository := requiredString(d, Repository)
+	upstreamType := requiredString(d, UpstreamType)
+
+	format, err := lookupUpstreamFormat(upstreamType)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	current, err := readUpstreamPriorities(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	order := convertInterfaceListToStrings(d.Get(Upstreams).([]interface{}))
+
+	// Check every upstream exists before changing any priorities.
+	existing := make([]string, len(current))
+	for i, u := range current {
+		existing[i] = u.slugPerm
+	}
+	var missing []string
+	for _, slugPerm := range order {
+		if !contains(existing, slugPerm) {
+			missing = append(missing, slugPerm)
+		}
+	}
+	if len(missing) > 0 {
+		return diag.Errorf("%s upstreams not found in repository %s/%s: %s", upstreamType, namespace, repository, strings.Join(missing, ", "))
+	}
+
+	for _, update := range planUpstreamPriorities(current, order) {
+		if _, err := format.setPriority(pc, namespace, repository, update.slugPerm, update.priority); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting priority of upstream (%s) to %d: %w", update.slugPerm, update.priority, formatAPIError(err)))
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s.%s", namespace, repository, upstreamType))
+
+	return resourceRepositoryUpstreamOrderRead(ctx, d, m)
+}
+
+func resourceRepositoryUpstreamOrderRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	current, err := readUpstreamPriorities(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set(Upstreams, upstreamOrderFromPriorities(current, convertInterfaceListToStrings(d.Get(Upstreams).([]interface{})))); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// resourceRepositoryUpstreamOrderDelete only removes the resource from state;
+// the upstreams keep their priorities.
+func resourceRepositoryUpstreamOrderDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// customizeDiffUpstreamOrder rejects upstreams listed more than once.
+func customizeDiffUpstreamOrder(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if !d.NewValueKnown(Upstreams) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range d.Get(Upstreams).([]interface{}) {
+		slugPerm, _ := v.(string)
+		if seen[slugPerm] {
+			return fmt.Errorf("upstream %q is listed more than once in %s", slugPerm, Upstreams)
+		}
+		seen[slugPerm] = true
+	}
+	return nil
+}
+
+func resourceRepositoryUpstreamOrder() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRepositoryUpstreamOrderUpdate,
+		ReadContext:   resourceRepositoryUpstreamOrderRead,
+		UpdateContext: resourceRepositoryUpstreamOrderUpdate,
+		DeleteContext: resourceRepositoryUpstreamOrderDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importRepositoryUpstreamOrder,
+		},
+
+		CustomizeDiff: customizeDiffUpstreamOrder,
+
+		Schema: map[string]*schema.Schema{
+			Namespace: {
+				Type:         schema.TypeString,
+				Description:  "The Organization to which the upstreams belong.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			Repository: {
+				Type:         schema.TypeString,
+				Description:  "The Repository to which the upstreams belong.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			UpstreamType: {
+				Type:         schema.TypeString,
+				Description:  "The type of the upstreams to order.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(upstreamTypes, false),
+			},
+			Upstreams: {
+				Type:        schema.TypeList,
+				Description: "The slug_perms of the upstreams, highest priority first. Other upstreams of the same type are ordered after these. The listed upstreams must not set priority.",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsNotEmpty,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,255 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestPlanUpstreamPriorities(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current []upstreamPriority
+		order   []string
+		want    map[string]int64
+		updates int
+	}{
+		{
+			name:    "already ordered",
+			current: []upstreamPriority{{"a", 1}, {"b", 2}, {"c", 3}},
+			order:   []string{"a", "b", "c"},
+			want:    map[string]int64{"a": 1, "b": 2, "c": 3},
+		},
+		{
+			name:    "gaps are closed",
+			current: []upstreamPriority{{"a", 1}, {"b", 5}},
+			order:   []string{"a", "b"},
+			want:    map[string]int64{"a": 1, "b": 2},
+			updates: 1,
+		},
+		{
+			name:    "swap",
+			current: []upstreamPriority{{"a", 1}, {"b", 2}},
+			order:   []string{"b", "a"},
+			want:    map[string]int64{"a": 2, "b": 1},
+			updates: 3,
+		},
+		{
+			name:    "rotation",
+			current: []upstreamPriority{{"a", 1}, {"b", 2}, {"c", 3}},
+			order:   []string{"c", "a", "b"},
+			want:    map[string]int64{"a": 2, "b": 3, "c": 1},
+			updates: 4,
+		},
+		{
+			name:    "move to front of a free range",
+			current: []upstreamPriority{{"a", 2}, {"b", 3}, {"c", 4}},
+			order:   []string{"c", "a", "b"},
+			want:    map[string]int64{"a": 2, "b": 3, "c": 1},
+			updates: 1,
+		},
+		{
+			name:    "unlisted upstreams move after listed ones",
+			current: []upstreamPriority{{"x", 1}, {"a", 2}, {"y", 3}, {"b", 4}},
+			order:   []string{"a", "b"},
+			want:    map[string]int64{"a": 1, "b": 2, "x": 3, "y": 4},
+			updates: 5,
+		},
+		{
+			name:    "duplicate priorities",
+			current: []upstreamPriority{{"a", 1}, {"b", 1}, {"c", 1}},
+			order:   []string{"a", "b", "c"},
+			want:    map[string]int64{"a": 1, "b": 2, "c": 3},
+			updates: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			priorities := make(map[string]int64)
+			for _, u := range tc.current {
+				priorities[u.slugPerm] = u.priority
+			}
+
+			updates := planUpstreamPriorities(tc.current, tc.order)
+			for _, update := range updates {
+				for slugPerm, priority := range priorities {
+					if slugPerm != update.slugPerm && priority == update.priority {
+						t.Fatalf("update %v takes the priority of %s", update, slugPerm)
+					}
+				}
+				priorities[update.slugPerm] = update.priority
+			}
+
+			if !reflect.DeepEqual(priorities, tc.want) {
+				t.Errorf("got priorities %v, want %v", priorities, tc.want)
+			}
+			if len(updates) != tc.updates {
+				t.Errorf("got %d updates, want %d: %v", len(updates), tc.updates, updates)
+			}
+		})
+	}
+}
+
+func TestUpstreamOrderFromPriorities(t *testing.T) {
+	t.Parallel()
+
+	current := []upstreamPriority{{"c", 3}, {"a", 1}, {"x", 2}, {"y", 4}}
+
+	tests := []struct {
+		name    string
+		managed []string
+		want    []string
+	}{
+		{name: "import", want: []string{"a", "x", "c", "y"}},
+		{name: "unmanaged upstream among managed ones", managed: []string{"a", "c"}, want: []string{"a", "x", "c"}},
+		{name: "managed upstreams first", managed: []string{"a"}, want: []string{"a"}},
+		{name: "deleted upstream", managed: []string{"a", "deleted"}, want: []string{"a"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := upstreamOrderFromPriorities(current, tc.managed); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// fakeUpstreamOrderServer serves the python upstream list and records
+// priority changes.
+type fakeUpstreamOrderServer struct {
+	mu         sync.Mutex
+	priorities map[string]int64
+	patches    []string
+}
+
+func (s *fakeUpstreamOrderServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	const base = "/repos/example-org/example-repo/upstream/python"
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	switch {
+	case r.Method == http.MethodGet && path == base:
+		upstreams := make([]map[string]interface{}, 0, len(s.priorities))
+		for slugPerm, priority := range s.priorities {
+			upstreams = append(upstreams, map[string]interface{}{"slug_perm": slugPerm, "priority": priority})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Pagination-Count", fmt.Sprint(len(upstreams)))
+		w.Header().Set("X-Pagination-Page", "1")
+		w.Header().Set("X-Pagination-PageTotal", "1")
+		w.Header().Set("X-Pagination-PageSize", "100")
+		_ = json.NewEncoder(w).Encode(upstreams)
+	case r.Method == http.MethodPatch && strings.HasPrefix(path, base+"/"):
+		slugPerm := strings.TrimPrefix(path, base+"/")
+		var body struct {
+			Priority int64 `json:"priority"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.priorities[slugPerm] = body.Priority
+		s.patches = append(s.patches, fmt.Sprintf("%s=%d", slugPerm, body.Priority))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"slug_perm": slugPerm, "priority": body.Priority})
+	default:
+		http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+	}
+}
+
+func TestResourceRepositoryUpstreamOrderUpdate(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeUpstreamOrderServer{priorities: map[string]int64{"pypi": 1, "mirror": 2, "internal": 3}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceRepositoryUpstreamOrder().Schema, map[string]interface{}{
+		Namespace:    "example-org",
+		Repository:   "example-repo",
+		UpstreamType: Python,
+		Upstreams:    []interface{}{"internal", "pypi", "mirror"},
+	})
+	if diagnostics := resourceRepositoryUpstreamOrderUpdate(context.Background(), d, testPrivilegesProviderConfig(server)); diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	want := map[string]int64{"internal": 1, "pypi": 2, "mirror": 3}
+	if !reflect.DeepEqual(fake.priorities, want) {
+		t.Errorf("got priorities %v, want %v", fake.priorities, want)
+	}
+	if d.Id() != "example-org.example-repo.python" {
+		t.Errorf("unexpected ID %q", d.Id())
+	}
+	if got := convertInterfaceListToStrings(d.Get(Upstreams).([]interface{})); !reflect.DeepEqual(got, []string{"internal", "pypi", "mirror"}) {
+		t.Errorf("unexpected upstreams %v", got)
+	}
+
+	// Reordering in the UI shows as drift.
+	fake.priorities["mirror"], fake.priorities["pypi"] = 2, 3
+	if diagnostics := resourceRepositoryUpstreamOrderRead(context.Background(), d, testPrivilegesProviderConfig(server)); diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+	if got := convertInterfaceListToStrings(d.Get(Upstreams).([]interface{})); !reflect.DeepEqual(got, []string{"internal", "mirror", "pypi"}) {
+		t.Errorf("unexpected upstreams after reorder %v", got)
+	}
+}
+
+func TestResourceRepositoryUpstreamOrderMissingUpstream(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeUpstreamOrderServer{priorities: map[string]int64{"pypi": 2, "mirror": 1}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceRepositoryUpstreamOrder().Schema, map[string]interface{}{
+		Namespace:    "example-org",
+		Repository:   "example-repo",
+		UpstreamType: Python,
+		Upstreams:    []interface{}{"pypi", "deleted"},
+	})
+	diagnostics := resourceRepositoryUpstreamOrderUpdate(context.Background(), d, testPrivilegesProviderConfig(server))
+	if !diagnostics.HasError() || !strings.Contains(diagnostics[0].Summary, "python upstreams not found in repository example-org/example-repo: deleted") {
+		t.Fatalf("expected a missing upstream error, got: %v", diagnostics)
+	}
+	if len(fake.patches) != 0 {
+		t.Errorf("expected no priority changes, got %v", fake.patches)
+	}
+}
+
+func TestCustomizeDiffUpstreamOrder(t *testing.T) {
+	t.Parallel()
+
+	config := map[string]interface{}{
+		Namespace:    "example-org",
+		Repository:   "example-repo",
+		UpstreamType: Python,
+		Upstreams:    []interface{}{"pypi", "mirror", "pypi"},
+	}
+	_, err := resourceRepositoryUpstreamOrder().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), nil)
+	if err == nil || !strings.Contains(err.Error(), `upstream "pypi" is listed more than once`) {
+		t.Fatalf("expected a duplicate upstream error, got: %v", err)
+	}
+}
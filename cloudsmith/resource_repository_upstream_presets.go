@@ -0,0 +1,255 @@
+package cloudsmith
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const Preset = "preset"
+
+// upstreamPreset describes a well-known public registry.
+type upstreamPreset struct {
+	upstreamType string
+	// urls are the accepted upstream URLs for the registry. The first is the
+	// one the preset fills in.
+	urls []string
+	mode string
+	// hosts are the hostnames that belong to the registry. An upstream URL on
+	// one of these hosts must be one of urls.
+	hosts []string
+}
+
+var upstreamPresets = map[string]upstreamPreset{
+	"conda_forge": {
+		upstreamType: Conda,
+		urls:         []string{"https://conda.anaconda.org/conda-forge"},
+		mode:         "Cache and Proxy",
+	},
+	"crates_io": {
+		upstreamType: Cargo,
+		urls:         []string{"https://index.crates.io"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"index.crates.io", "crates.io", "static.crates.io"},
+	},
+	"cran": {
+		upstreamType: Cran,
+		urls:         []string{"https://cran.r-project.org", "https://cloud.r-project.org"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"cran.r-project.org", "cloud.r-project.org"},
+	},
+	"dockerhub": {
+		upstreamType: Docker,
+		urls:         []string{"https://index.docker.io"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"index.docker.io", "registry-1.docker.io", "registry.hub.docker.com", "hub.docker.com", "docker.io"},
+	},
+	"go_proxy": {
+		upstreamType: Go,
+		urls:         []string{"https://proxy.golang.org"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"proxy.golang.org", "pkg.go.dev"},
+	},
+	"hex_pm": {
+		upstreamType: Hex,
+		urls:         []string{"https://repo.hex.pm"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"repo.hex.pm", "hex.pm"},
+	},
+	"huggingface": {
+		upstreamType: HuggingFace,
+		urls:         []string{"https://huggingface.co"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"huggingface.co"},
+	},
+	"maven_central": {
+		upstreamType: Maven,
+		urls:         []string{"https://repo1.maven.org/maven2", "https://repo.maven.apache.org/maven2"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"repo1.maven.org", "repo.maven.apache.org", "central.sonatype.com", "search.maven.org"},
+	},
+	"npmjs": {
+		upstreamType: Npm,
+		urls:         []string{"https://registry.npmjs.org"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"registry.npmjs.org", "registry.npmjs.com", "www.npmjs.com", "npmjs.com"},
+	},
+	"nuget_org": {
+		upstreamType: NuGet,
+		urls:         []string{"https://api.nuget.org/v3/index.json"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"api.nuget.org", "www.nuget.org", "nuget.org"},
+	},
+	"packagist": {
+		upstreamType: Composer,
+		urls:         []string{"https://packagist.org", "https://repo.packagist.org"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"repo.packagist.org", "packagist.org"},
+	},
+	"pub_dev": {
+		upstreamType: Dart,
+		urls:         []string{"https://pub.dev"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"pub.dev", "pub.dartlang.org"},
+	},
+	"pypi": {
+		upstreamType: Python,
+		urls:         []string{"https://pypi.org"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"pypi.org", "pypi.python.org", "files.pythonhosted.org"},
+	},
+	"rubygems": {
+		upstreamType: Ruby,
+		urls:         []string{"https://rubygems.org"},
+		mode:         "Cache and Proxy",
+		hosts:        []string{"rubygems.org", "index.rubygems.org"},
+	},
+}
+
+// upstreamPresetNames returns the supported presets in sorted order.
+func upstreamPresetNames() []string {
+	names := make([]string, 0, len(upstreamPresets))
+	for name := range upstreamPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// upstreamPresetForHost returns the preset a hostname belongs to, if any.
+func upstreamPresetForHost(host string) (string, upstreamPreset, bool) {
+	host = strings.ToLower(host)
+	for _, name := range upstreamPresetNames() {
+		if contains(upstreamPresets[name].hosts, host) {
+			return name, upstreamPresets[name], true
+		}
+	}
+	return "", upstreamPreset{}, false
+}
+
+// checkUpstreamUrlShape checks that rawURL is a plausible upstream URL for
+// upstreamType. A URL on the host of a well-known registry must be for a
+// registry of upstreamType. Trailing slashes are ignored.
+func checkUpstreamUrlShape(upstreamType, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL: %w", UpstreamUrl, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must be an http or https URL, got %q", UpstreamUrl, rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q must be an absolute URL including the host, got %q", UpstreamUrl, rawURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%q must not include credentials; use %s, %s and %s instead", UpstreamUrl, AuthMode, AuthUsername, AuthSecret)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%q must not include a query string or fragment, got %q", UpstreamUrl, rawURL)
+	}
+
+	if _, preset, ok := upstreamPresetForHost(u.Hostname()); ok {
+		if preset.upstreamType != upstreamType {
+			return fmt.Errorf("%q points at %s, which is a %s registry, not %s", UpstreamUrl, u.Hostname(), preset.upstreamType, upstreamType)
+		}
+		return nil
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	switch upstreamType {
+	case Python:
+		if strings.HasSuffix(path, "/simple") {
+			return fmt.Errorf("%q for python upstreams must be the index root, without the /simple suffix, got %q", UpstreamUrl, rawURL)
+		}
+	case Docker:
+		if path == "/v2" || strings.HasPrefix(path, "/v2/") {
+			return fmt.Errorf("%q for docker upstreams must be the registry root, without the /v2 API path, got %q", UpstreamUrl, rawURL)
+		}
+	}
+	return nil
+}
+
+// upstreamUrlPresetMismatch returns a message if rawURL is on the host of a
+// well-known registry but isn't one of the URLs it's usually configured with.
+// Mirrors and paths Cloudsmith doesn't know about may still work, so this is
+// only a warning. Trailing slashes are ignored.
+func upstreamUrlPresetMismatch(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	name, preset, ok := upstreamPresetForHost(u.Hostname())
+	if !ok {
+		return "", false
+	}
+	normalized := strings.TrimRight(rawURL, "/")
+	for _, presetURL := range preset.urls {
+		if strings.TrimRight(presetURL, "/") == normalized {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("%q for %s is usually %q, got %q; alternatively set preset = %q", UpstreamUrl, name, preset.urls[0], rawURL, name), true
+}
+
+// upstreamUrlDiagnostics warns when upstream_url is on the host of a
+// well-known registry but isn't one of its usual URLs. CustomizeDiff can't
+// return warnings, so this is reported when the upstream is created or its URL
+// changes.
+func upstreamUrlDiagnostics(d *schema.ResourceData) diag.Diagnostics {
+	if d.Get(Preset).(string) != "" {
+		return nil
+	}
+	message, ok := upstreamUrlPresetMismatch(d.Get(UpstreamUrl).(string))
+	if !ok {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity:      diag.Warning,
+		Summary:       "Unexpected URL for a well-known registry",
+		Detail:        message,
+		AttributePath: cty.GetAttrPath(UpstreamUrl),
+	}}
+}
+
+// customizeDiffUpstreamPreset fills in upstream_url and, unless it is
+// configured, mode from the preset. Otherwise it checks the shape of
+// upstream_url when the upstream is created or its URL or type changes.
+func customizeDiffUpstreamPreset(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown(UpstreamType) || !d.NewValueKnown(Preset) {
+		return nil
+	}
+	upstreamType := d.Get(UpstreamType).(string)
+
+	if name := d.Get(Preset).(string); name != "" {
+		preset, ok := upstreamPresets[name]
+		if !ok {
+			return fmt.Errorf("unknown preset %q", name)
+		}
+		if preset.upstreamType != upstreamType {
+			return fmt.Errorf("preset %q is for %s upstreams, not %s", name, preset.upstreamType, upstreamType)
+		}
+		if d.Get(UpstreamUrl).(string) != preset.urls[0] {
+			if err := d.SetNew(UpstreamUrl, preset.urls[0]); err != nil {
+				return err
+			}
+		}
+		if config := d.GetRawConfig(); !config.IsNull() && config.GetAttr(Mode).IsNull() && d.Get(Mode).(string) != preset.mode {
+			if err := d.SetNew(Mode, preset.mode); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Only new URLs are checked, so an existing upstream keeps planning
+	// cleanly if the checks are tightened.
+	if !d.NewValueKnown(UpstreamUrl) || (d.Id() != "" && !d.HasChange(UpstreamUrl) && !d.HasChange(UpstreamType)) {
+		return nil
+	}
+	return checkUpstreamUrlShape(upstreamType, d.Get(UpstreamUrl).(string))
+}
@@ -2,6 +2,7 @@
 package cloudsmith
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -10,12 +11,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"net/http"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -26,7 +27,6 @@ type testUpstream struct {
 }
 
 func (u testUpstream) GetAuthMode() string          { return "" }
-func (u testUpstream) GetAuthSecret() string        { return "" }
 func (u testUpstream) GetAuthUsername() string      { return "" }
 func (u testUpstream) GetExtraHeader1() string      { return "" }
 func (u testUpstream) GetExtraHeader2() string      { return "" }
@@ -44,6 +44,16 @@ func (u testUpstream) GetUpdatedAt() time.Time      { return time.Time{} }
 func (u testUpstream) GetUpstreamUrl() string       { return "" }
 func (u testUpstream) GetVerifySsl() bool           { return false }
 
+// testFetchedUpstream is a testUpstream whose model reports fetches.
+type testFetchedUpstream struct {
+	testUpstream
+	lastSuccessfulFetchAt *time.Time
+}
+
+func (u testFetchedUpstream) GetLastSuccessfulFetchAtOk() (*time.Time, bool) {
+	return u.lastSuccessfulFetchAt, u.lastSuccessfulFetchAt != nil
+}
+
 func TestActionableUpstreamDisableReason(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -130,6 +140,343 @@ func TestCheckUpstreamActivation(t *testing.T) {
 	}
 }
 
+func TestUpstreamHealthState(t *testing.T) {
+	tests := []struct {
+		name     string
+		upstream Upstream
+		want     string
+	}{
+		{name: "active", upstream: testUpstream{isActive: true}, want: upstreamHealthActive},
+		{name: "inactive without disable reason", upstream: testUpstream{disableReason: "N/A"}, want: upstreamHealthPending},
+		{name: "inactive with disable reason", upstream: testUpstream{disableReasonText: "Upstream returned 401"}, want: upstreamHealthDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := upstreamHealthState(tt.upstream); got != tt.want {
+				t.Fatalf("upstreamHealthState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckUpstreamReachable(t *testing.T) {
+	fetchedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		upstream Upstream
+		wantErr  error
+	}{
+		{
+			name:     "active without fetch reporting",
+			upstream: testUpstream{isActive: true},
+		},
+		{
+			name:     "active and fetched",
+			upstream: testFetchedUpstream{testUpstream: testUpstream{isActive: true}, lastSuccessfulFetchAt: &fetchedAt},
+		},
+		{
+			name:     "active but never fetched keeps waiting",
+			upstream: testFetchedUpstream{testUpstream: testUpstream{isActive: true}},
+			wantErr:  errKeepWaiting,
+		},
+		{
+			name:     "inactive keeps waiting",
+			upstream: testFetchedUpstream{lastSuccessfulFetchAt: &fetchedAt},
+			wantErr:  errKeepWaiting,
+		},
+		{
+			name:     "disabled fails fast",
+			upstream: testFetchedUpstream{testUpstream: testUpstream{disableReasonText: "Upstream returned 401"}},
+			wantErr:  upstreamActivationDisabledError{slug: "test-upstream", reason: "Upstream returned 401"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkUpstreamReachable(tt.upstream, "test-upstream"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkUpstreamReachable() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomizeDiffUpstreamWaitForActive(t *testing.T) {
+	state := func(healthState string) *terraform.InstanceState {
+		return &terraform.InstanceState{
+			ID: "abcdef123456",
+			Attributes: map[string]string{
+				Namespace:     "example-org",
+				Repository:    "example-repo",
+				UpstreamType:  Python,
+				Name:          "example-upstream",
+				UpstreamUrl:   "https://upstream.example.com",
+				IsActive:      fmt.Sprint(healthState == upstreamHealthActive),
+				HealthState:   healthState,
+				WaitForActive: "true",
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		healthState string
+		config      map[string]interface{}
+		wantUpdate  bool
+	}{
+		{name: "active", healthState: upstreamHealthActive},
+		{name: "disabled", healthState: upstreamHealthDisabled, wantUpdate: true},
+		{name: "pending", healthState: upstreamHealthPending, wantUpdate: true},
+		{name: "disabled without wait_for_active", healthState: upstreamHealthDisabled, config: map[string]interface{}{WaitForActive: false}},
+		{name: "disabled on purpose", healthState: upstreamHealthDisabled, config: map[string]interface{}{IsActive: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := upstreamFormatTestConfig(Python)
+			config[WaitForActive] = true
+			for k, v := range tt.config {
+				config[k] = v
+			}
+
+			r := resourceRepositoryUpstream()
+			prior := state(tt.healthState)
+			prior.RawConfig = testRawConfig(r, config)
+			diff, err := r.Diff(context.Background(), prior, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var attr *terraform.ResourceAttrDiff
+			if diff != nil {
+				attr = diff.Attributes[HealthState]
+			}
+			if gotUpdate := attr != nil && attr.NewComputed; gotUpdate != tt.wantUpdate {
+				t.Fatalf("health_state planned for update = %t, want %t", gotUpdate, tt.wantUpdate)
+			}
+		})
+	}
+}
+
+func TestCustomizeDiffUpstreamTypeChange(t *testing.T) {
+	t.Parallel()
+
+	state := &terraform.InstanceState{
+		ID: "abcdef123456",
+		Attributes: map[string]string{
+			Namespace:    "example-org",
+			Repository:   "example-repo",
+			UpstreamType: Python,
+			Name:         "example-upstream",
+			UpstreamUrl:  "https://upstream.example.com",
+			SlugPerm:     "abcdef123456",
+			Priority:     "3",
+		},
+	}
+
+	tests := []struct {
+		name         string
+		upstreamType string
+		replaced     string
+	}{
+		{name: "unchanged", upstreamType: Python},
+		{name: "changed", upstreamType: Npm, replaced: `python upstream "example-upstream" (abcdef123456), replaced because upstream_type changed from python to npm`},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := resourceRepositoryUpstream()
+			config := upstreamFormatTestConfig(tc.upstreamType)
+			prior := state.DeepCopy()
+			prior.RawConfig = testRawConfig(r, config)
+
+			diff, err := r.Diff(context.Background(), prior, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff.RequiresNew() {
+				t.Fatal("changing upstream_type should not force a new resource")
+			}
+
+			if tc.replaced == "" {
+				if diff == nil {
+					return
+				}
+				if attr := diff.Attributes[ReplacedUpstream]; attr != nil {
+					t.Fatalf("unexpected planned %s: %+v", ReplacedUpstream, attr)
+				}
+				return
+			}
+			if attr := diff.Attributes[ReplacedUpstream]; attr == nil || attr.New != tc.replaced {
+				t.Fatalf("unexpected planned %s: %+v, want %q", ReplacedUpstream, attr, tc.replaced)
+			}
+			for _, attr := range []string{SlugPerm, Priority} {
+				if got := diff.Attributes[attr]; got == nil || !got.NewComputed {
+					t.Errorf("expected %s to be unknown, got: %+v", attr, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomizeDiffUpstreamTypeChangeFormatFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		oldType string
+		state   map[string]string
+		newType string
+		// unset lists attributes of the new format's test configuration to
+		// leave out.
+		unset    []string
+		cleared  []string
+		computed []string
+	}{
+		{
+			name:    "deb to rpm",
+			oldType: Deb,
+			state: map[string]string{
+				Component:             "main",
+				IncludeSources:        "true",
+				UpstreamDistribution:  "jammy",
+				DistroVersions + ".#": "1",
+				DistroVersions + ".0": "ubuntu/jammy",
+			},
+			newType:  Rpm,
+			cleared:  []string{Component},
+			computed: []string{IncludeSources},
+		},
+		{
+			name:    "rpm to deb",
+			oldType: Rpm,
+			state: map[string]string{
+				DistroVersion:  "el/9",
+				IncludeSources: "true",
+			},
+			newType:  Deb,
+			computed: []string{IncludeSources},
+		},
+		{
+			name:     "generic to npm",
+			oldType:  Generic,
+			state:    map[string]string{UpstreamPrefix: "example"},
+			newType:  Npm,
+			unset:    []string{UpstreamPendingValidation},
+			cleared:  []string{UpstreamPrefix},
+			computed: []string{UpstreamPendingValidation},
+		},
+		{
+			name:    "docker to python",
+			oldType: Docker,
+			state: map[string]string{
+				UpstreamTrust + ".#":          "1",
+				UpstreamTrust + ".0.level":    "Trusted",
+				UpstreamCache + ".#":          "1",
+				UpstreamCache + ".0.lifetime": "60",
+			},
+			newType:  Python,
+			unset:    []string{UpstreamTrust, UpstreamCache, UpstreamPendingValidation},
+			computed: []string{UpstreamTrust, UpstreamCache, UpstreamPendingValidation},
+		},
+	}
+
+	// planned returns the planned change of attr, or of the length of attr if
+	// it is a block.
+	planned := func(diff *terraform.InstanceDiff, attr string) *terraform.ResourceAttrDiff {
+		if got := diff.Attributes[attr]; got != nil {
+			return got
+		}
+		return diff.Attributes[attr+".#"]
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := resourceRepositoryUpstream()
+			config := upstreamFormatTestConfig(tc.newType)
+			for _, attr := range tc.unset {
+				delete(config, attr)
+			}
+			prior := &terraform.InstanceState{
+				ID: "abcdef123456",
+				Attributes: map[string]string{
+					Namespace:    "example-org",
+					Repository:   "example-repo",
+					UpstreamType: tc.oldType,
+					Name:         "example-upstream",
+					UpstreamUrl:  "https://upstream.example.com",
+					SlugPerm:     "abcdef123456",
+				},
+				RawConfig: testRawConfig(r, config),
+			}
+			for k, v := range tc.state {
+				prior.Attributes[k] = v
+			}
+
+			diff, err := r.Diff(context.Background(), prior, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// The SDK plans an empty computed string as unknown rather than as
+			// "", so either means the old value isn't kept.
+			for _, attr := range tc.cleared {
+				if got := planned(diff, attr); got == nil || (!got.NewComputed && got.New != "" && got.New != "0" && got.New != "false") {
+					t.Errorf("expected %s to be cleared, got: %+v", attr, got)
+				}
+			}
+			for _, attr := range tc.computed {
+				if got := planned(diff, attr); got == nil || !got.NewComputed {
+					t.Errorf("expected %s to be unknown, got: %+v", attr, got)
+				}
+			}
+		})
+	}
+}
+
+func TestUpstreamComputedFields(t *testing.T) {
+	t.Parallel()
+
+	s := resourceRepositoryUpstream().Schema
+	for _, field := range upstreamFormatFields() {
+		if computed := contains(upstreamComputedFields, field); s[field].Computed != computed {
+			t.Errorf("%s: Computed is %t, but listed in upstreamComputedFields is %t", field, s[field].Computed, computed)
+		}
+	}
+}
+
+func TestImportUpstream(t *testing.T) {
+	t.Parallel()
+
+	d := schema.TestResourceDataRaw(t, resourceRepositoryUpstream().Schema, map[string]interface{}{})
+	d.SetId("example-org.example-repo.python.abcdef123456")
+
+	imported, err := importUpstream(context.Background(), d, nil)
+	if err != nil {
+		t.Fatalf("importUpstream() error = %v", err)
+	}
+
+	attributes := imported[0].State().Attributes
+	for key, want := range map[string]string{
+		"id":                         "abcdef123456",
+		Namespace:                    "example-org",
+		Repository:                   "example-repo",
+		UpstreamType:                 Python,
+		WaitForActive:                "false",
+		CertificateExpiryWarningDays: "30",
+	} {
+		if got, ok := attributes[key]; !ok || got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
 func TestAccRepositoryUpstreamAlpine_basic(t *testing.T) {
 	t.Parallel()
 
@@ -2179,74 +2526,12 @@ func testAccRepositoryUpstreamCheckDestroy(resourceName string) resource.TestChe
 		upstreamType := resourceState.Primary.Attributes[UpstreamType]
 		slugPerm := resourceState.Primary.Attributes[SlugPerm]
 
-		var resp *http.Response
-		var err error
-
-		switch upstreamType {
-		case Alpine:
-			req := pc.APIClient.ReposApi.ReposUpstreamAlpineRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamAlpineReadExecute(req)
-		case Cargo:
-			req := pc.APIClient.ReposApi.ReposUpstreamCargoRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamCargoReadExecute(req)
-		case Composer:
-			req := pc.APIClient.ReposApi.ReposUpstreamComposerRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamComposerReadExecute(req)
-		case Conda:
-			req := pc.APIClient.ReposApi.ReposUpstreamCondaRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamCondaReadExecute(req)
-		case Cran:
-			req := pc.APIClient.ReposApi.ReposUpstreamCranRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamCranReadExecute(req)
-		case Dart:
-			req := pc.APIClient.ReposApi.ReposUpstreamDartRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamDartReadExecute(req)
-		case Deb:
-			req := pc.APIClient.ReposApi.ReposUpstreamDebRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamDebReadExecute(req)
-		case Docker:
-			req := pc.APIClient.ReposApi.ReposUpstreamDockerRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamDockerReadExecute(req)
-		case Generic:
-			req := pc.APIClient.ReposApi.ReposUpstreamGenericRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamGenericReadExecute(req)
-		case Go:
-			req := pc.APIClient.ReposApi.ReposUpstreamGoRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamGoReadExecute(req)
-		case Helm:
-			req := pc.APIClient.ReposApi.ReposUpstreamHelmRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamHelmReadExecute(req)
-		case Hex:
-			req := pc.APIClient.ReposApi.ReposUpstreamHexRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamHexReadExecute(req)
-		case HuggingFace:
-			req := pc.APIClient.ReposApi.ReposUpstreamHuggingfaceRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamHuggingfaceReadExecute(req)
-		case Maven:
-			req := pc.APIClient.ReposApi.ReposUpstreamMavenRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamMavenReadExecute(req)
-		case Npm:
-			req := pc.APIClient.ReposApi.ReposUpstreamNpmRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamNpmReadExecute(req)
-		case NuGet:
-			req := pc.APIClient.ReposApi.ReposUpstreamNugetRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamNugetReadExecute(req)
-		case Python:
-			req := pc.APIClient.ReposApi.ReposUpstreamPythonRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamPythonReadExecute(req)
-		case Rpm:
-			req := pc.APIClient.ReposApi.ReposUpstreamRpmRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamRpmReadExecute(req)
-		case Ruby:
-			req := pc.APIClient.ReposApi.ReposUpstreamRubyRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamRubyReadExecute(req)
-		case Swift:
-			req := pc.APIClient.ReposApi.ReposUpstreamSwiftRead(pc.Auth, namespace, repository, slugPerm)
-			_, resp, err = pc.APIClient.ReposApi.ReposUpstreamSwiftReadExecute(req)
-		default:
-			return fmt.Errorf("invalid upstream_type: '%s'", upstreamType)
+		format, err := lookupUpstreamFormat(upstreamType)
+		if err != nil {
+			return err
 		}
 
+		_, resp, err := format.read(pc, namespace, repository, slugPerm)
 		if err != nil && !is404(resp) {
 			return fmt.Errorf("unable to verify upstream deletion: %w", err)
 		} else if is200(resp) {
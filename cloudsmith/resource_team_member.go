@@ -0,0 +1,268 @@
+package cloudsmith
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// The purpose of this resource is to add/remove a single user or service to/from
+// a team in Cloudsmith. Adding a member or changing their role leaves other
+// members alone, but removing one rewrites the full member list. See
+// cloudsmith_manage_team to manage the full member list.
+
+func importTeamMember(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idParts := strings.Split(d.Id(), ".")
+	if len(idParts) != 3 {
+		return nil, fmt.Errorf(
+			"invalid import ID, must be of the form <organization_slug>.<team_slug>.<user_slug>, got: %s", d.Id(),
+		)
+	}
+
+	d.Set("organization", idParts[0])
+	d.Set("team_name", idParts[1])
+	d.Set("user", idParts[2])
+	return []*schema.ResourceData{d}, nil
+}
+
+// listTeamMembers returns the members of a team, and whether the team exists.
+func listTeamMembers(pc *providerConfig, organization, teamName string) ([]cloudsmith.OrganizationTeamServiceMember, bool, error) {
+	req := pc.APIClient.OrgsApi.OrgsTeamsMembersList(pc.Auth, organization, teamName)
+	teamMembers, resp, err := pc.APIClient.OrgsApi.OrgsTeamsMembersListExecute(req)
+	if err != nil {
+		if is404(resp) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("error retrieving members of team %s/%s: %w", organization, teamName, formatAPIError(err))
+	}
+	return teamMembers.GetMembers(), true, nil
+}
+
+// findTeamMember returns the member of members for user, or nil if user isn't
+// a member.
+func findTeamMember(members []cloudsmith.OrganizationTeamServiceMember, user string) *cloudsmith.OrganizationTeamServiceMember {
+	for i := range members {
+		if members[i].User == user {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+// withoutTeamMember returns members without user, keeping every other member
+// as it is.
+func withoutTeamMember(members []cloudsmith.OrganizationTeamServiceMember, user string) []cloudsmith.OrganizationTeamServiceMember {
+	remaining := make([]cloudsmith.OrganizationTeamServiceMember, 0, len(members))
+	for _, member := range members {
+		if member.User != user {
+			remaining = append(remaining, member)
+		}
+	}
+	return remaining
+}
+
+// changedTeamMembers returns the users other than user whose membership in
+// got differs from want, sorted.
+func changedTeamMembers(want, got []cloudsmith.OrganizationTeamServiceMember, user string) []string {
+	roles := func(members []cloudsmith.OrganizationTeamServiceMember) map[string]string {
+		byUser := make(map[string]string, len(members))
+		for _, member := range members {
+			if member.User != user {
+				byUser[member.User] = member.Role
+			}
+		}
+		return byUser
+	}
+	wantRoles, gotRoles := roles(want), roles(got)
+
+	var changed []string
+	for u, role := range wantRoles {
+		if gotRole, ok := gotRoles[u]; !ok || gotRole != role {
+			changed = append(changed, u)
+		}
+	}
+	for u := range gotRoles {
+		if _, ok := wantRoles[u]; !ok {
+			changed = append(changed, u)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// addTeamMember adds user to the team with role, or changes their role if
+// they are already a member. The create endpoint adds to the existing members
+// rather than replacing them, so concurrent changes by others aren't lost.
+func addTeamMember(pc *providerConfig, organization, teamName, user, role string) error {
+	req := pc.APIClient.OrgsApi.OrgsTeamsMembersCreate(pc.Auth, organization, teamName)
+	req = req.Data(cloudsmith.OrganizationTeamMembers{
+		Members: []cloudsmith.OrganizationTeamServiceMember{{
+			Role: role,
+			User: user,
+		}},
+	})
+	if _, _, err := pc.APIClient.OrgsApi.OrgsTeamsMembersCreateExecute(req); err != nil {
+		return formatAPIError(err)
+	}
+	return nil
+}
+
+// removeTeamMember removes user from the team. There is no endpoint to remove
+// a single member, only one replacing the full member list, so the current
+// list is read and written back without user straight away. A change made by
+// someone else between the two is overwritten. The list is read again
+// afterwards, so that a change made after the write is reported rather than
+// going unnoticed.
+func removeTeamMember(pc *providerConfig, organization, teamName, user string) error {
+	members, exists, err := listTeamMembers(pc, organization, teamName)
+	if err != nil {
+		return err
+	}
+	// A deleted team has no members left to remove.
+	if !exists || findTeamMember(members, user) == nil {
+		return nil
+	}
+
+	remaining := withoutTeamMember(members, user)
+	req := pc.APIClient.OrgsApi.OrgsTeamsMembersUpdate(pc.Auth, organization, teamName)
+	req = req.Data(cloudsmith.OrganizationTeamMembers{
+		Members: remaining,
+	})
+	if _, _, err := pc.APIClient.OrgsApi.OrgsTeamsMembersUpdateExecute(req); err != nil {
+		return formatAPIError(err)
+	}
+
+	after, exists, err := listTeamMembers(pc, organization, teamName)
+	if err != nil || !exists {
+		return err
+	}
+	if changed := changedTeamMembers(remaining, after, user); len(changed) > 0 {
+		return fmt.Errorf(
+			"members %s of team %s/%s were changed while removing %s; check the team's members, as a change made just before the removal may have been overwritten",
+			strings.Join(changed, ", "), organization, teamName, user,
+		)
+	}
+	return nil
+}
+
+func resourceTeamMemberCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	teamName := requiredString(d, "team_name")
+	user := requiredString(d, "user")
+
+	if err := addTeamMember(pc, organization, teamName, user, requiredString(d, "role")); err != nil {
+		return diag.Errorf("error adding %s to team %s/%s: %s", user, organization, teamName, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s.%s", organization, teamName, user))
+
+	return resourceTeamMemberRead(ctx, d, m)
+}
+
+func resourceTeamMemberRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	teamName := requiredString(d, "team_name")
+	user := requiredString(d, "user")
+
+	members, exists, err := listTeamMembers(pc, organization, teamName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	member := findTeamMember(members, user)
+	if !exists || member == nil {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("role", member.Role)
+
+	d.SetId(fmt.Sprintf("%s.%s.%s", organization, teamName, user))
+
+	return nil
+}
+
+func resourceTeamMemberUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	teamName := requiredString(d, "team_name")
+	user := requiredString(d, "user")
+
+	if err := addTeamMember(pc, organization, teamName, user, requiredString(d, "role")); err != nil {
+		return diag.Errorf("error updating role of %s in team %s/%s: %s", user, organization, teamName, err)
+	}
+
+	if err := waitForUpdate("team member", d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceTeamMemberRead(ctx, d, m)
+}
+
+func resourceTeamMemberDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pc := m.(*providerConfig)
+
+	organization := requiredString(d, "organization")
+	teamName := requiredString(d, "team_name")
+	user := requiredString(d, "user")
+
+	if err := removeTeamMember(pc, organization, teamName, user); err != nil {
+		return diag.Errorf("error removing %s from team %s/%s: %s", user, organization, teamName, err)
+	}
+
+	return nil
+}
+
+func resourceTeamMember() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceTeamMemberCreate,
+		ReadContext:   resourceTeamMemberRead,
+		UpdateContext: resourceTeamMemberUpdate,
+		DeleteContext: resourceTeamMemberDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: importTeamMember,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Type:         schema.TypeString,
+				Description:  "Organization to which the team belongs.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"team_name": {
+				Type:         schema.TypeString,
+				Description:  "The slug of the team.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"user": {
+				Type:         schema.TypeString,
+				Description:  "The slug of the user or service to add to the team.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"role": {
+				Type:         schema.TypeString,
+				Description:  "The role of the user or service in the team.",
+				Optional:     true,
+				Default:      "Member",
+				ValidateFunc: validation.StringInSlice(roles, false),
+			},
+		},
+	}
+}
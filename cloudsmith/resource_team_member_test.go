@@ -0,0 +1,246 @@
+//nolint:testpackage
+package cloudsmith
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWithoutTeamMember(t *testing.T) {
+	t.Parallel()
+
+	members := []cloudsmith.OrganizationTeamServiceMember{
+		{Role: "Manager", User: "alice"},
+		{Role: "Member", User: "bob"},
+	}
+
+	if got, want := withoutTeamMember(members, "alice"), []cloudsmith.OrganizationTeamServiceMember{{Role: "Member", User: "bob"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("withoutTeamMember() = %v, want %v", got, want)
+	}
+	if got := withoutTeamMember(members, "ci-service"); !reflect.DeepEqual(got, members) {
+		t.Fatalf("withoutTeamMember() = %v, want %v", got, members)
+	}
+}
+
+func TestResourceTeamMemberReadDelete(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	members := []map[string]interface{}{
+		{"role": "Manager", "user": "alice"},
+		{"role": "Member", "user": "hr-sync"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path != "/orgs/org/teams/team/members/" {
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"members": members})
+		case http.MethodPut:
+			var body struct {
+				Members []map[string]interface{} `json:"members"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			members = body.Members
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"members": members})
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	d := schema.TestResourceDataRaw(t, resourceTeamMember().Schema, map[string]interface{}{
+		"organization": "org",
+		"team_name":    "team",
+		"user":         "alice",
+	})
+	d.SetId("org.team.alice")
+
+	if diags := resourceTeamMemberRead(context.Background(), d, pc); diags.HasError() {
+		t.Fatalf("resourceTeamMemberRead() error = %v", diags)
+	}
+	if got := d.Get("role").(string); got != "Manager" {
+		t.Fatalf("role = %q, want Manager", got)
+	}
+
+	if diags := resourceTeamMemberDelete(context.Background(), d, pc); diags.HasError() {
+		t.Fatalf("resourceTeamMemberDelete() error = %v", diags)
+	}
+	mu.Lock()
+	remaining := members
+	mu.Unlock()
+	if len(remaining) != 1 || remaining[0]["user"] != "hr-sync" {
+		t.Fatalf("members = %v, want only hr-sync to remain", remaining)
+	}
+
+	if diags := resourceTeamMemberRead(context.Background(), d, pc); diags.HasError() {
+		t.Fatalf("resourceTeamMemberRead() error = %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("id = %q, want a removed member to be dropped from state", d.Id())
+	}
+
+	missingTeam := schema.TestResourceDataRaw(t, resourceTeamMember().Schema, map[string]interface{}{
+		"organization": "org",
+		"team_name":    "gone",
+		"user":         "alice",
+	})
+	missingTeam.SetId("org.gone.alice")
+	if diags := resourceTeamMemberRead(context.Background(), missingTeam, pc); diags.HasError() {
+		t.Fatalf("resourceTeamMemberRead() error = %v", diags)
+	}
+	if missingTeam.Id() != "" {
+		t.Fatalf("id = %q, want a member of a deleted team to be dropped from state", missingTeam.Id())
+	}
+}
+
+func TestChangedTeamMembers(t *testing.T) {
+	t.Parallel()
+
+	want := []cloudsmith.OrganizationTeamServiceMember{
+		{Role: "Manager", User: "alice"},
+		{Role: "Member", User: "bob"},
+		{Role: "Member", User: "carol"},
+	}
+	got := []cloudsmith.OrganizationTeamServiceMember{
+		{Role: "Member", User: "alice"},
+		{Role: "Member", User: "bob"},
+		{Role: "Member", User: "dave"},
+	}
+
+	// carol's own membership is ignored.
+	if changed := changedTeamMembers(want, got, "carol"); !reflect.DeepEqual(changed, []string{"alice", "dave"}) {
+		t.Fatalf("changedTeamMembers() = %v, want [alice dave]", changed)
+	}
+	if changed := changedTeamMembers(want, want, "carol"); len(changed) != 0 {
+		t.Fatalf("changedTeamMembers() = %v, want none", changed)
+	}
+}
+
+func TestAddTeamMember(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	var body struct {
+		Members []map[string]interface{} `json:"members"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method != http.MethodPost || r.URL.Path != "/orgs/org/teams/team/members/" {
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	// Changing a role only sends this member, so the other members are
+	// neither read nor written.
+	if err := addTeamMember(pc, "org", "team", "alice", "Manager"); err != nil {
+		t.Fatalf("addTeamMember() error = %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "POST /orgs/org/teams/team/members/" {
+		t.Fatalf("requests = %v, want only the create request", requests)
+	}
+	if len(body.Members) != 1 || body.Members[0]["user"] != "alice" || body.Members[0]["role"] != "Manager" {
+		t.Fatalf("members = %v, want only alice as Manager", body.Members)
+	}
+
+	if err := addTeamMember(pc, "org", "gone", "alice", "Manager"); err == nil {
+		t.Fatal("addTeamMember() error = nil, want the deleted team to be reported")
+	}
+}
+
+func TestRemoveTeamMember(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	members := []map[string]interface{}{
+		{"role": "Member", "user": "alice"},
+		{"role": "Member", "user": "carol"},
+		{"role": "Member", "user": "hr-sync"},
+	}
+	// concurrent is added to the team by another tool right after each removal.
+	var concurrent map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path != "/orgs/org/teams/team/members/" {
+			http.Error(w, `{"detail":"Not found."}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"members": members})
+		case http.MethodPut:
+			var body struct {
+				Members []map[string]interface{} `json:"members"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			members = body.Members
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"members": members})
+			if concurrent != nil {
+				members = append(members, concurrent)
+			}
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	pc := testPrivilegesProviderConfig(server)
+
+	if err := removeTeamMember(pc, "org", "team", "alice"); err != nil {
+		t.Fatalf("removeTeamMember() error = %v", err)
+	}
+
+	mu.Lock()
+	concurrent = map[string]interface{}{"role": "Member", "user": "bob"}
+	mu.Unlock()
+	err := removeTeamMember(pc, "org", "team", "carol")
+	if err == nil || !strings.Contains(err.Error(), "members bob of team org/team were changed") {
+		t.Fatalf("removeTeamMember() error = %v, want the concurrent change to bob to be reported", err)
+	}
+
+	if err := removeTeamMember(pc, "org", "team", "alice"); err != nil {
+		t.Fatalf("removeTeamMember() error = %v, want removing a former member to succeed", err)
+	}
+	if err := removeTeamMember(pc, "org", "gone", "alice"); err != nil {
+		t.Fatalf("removeTeamMember() error = %v, want removing a member of a deleted team to succeed", err)
+	}
+}
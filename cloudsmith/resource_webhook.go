@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/cloudsmith-io/cloudsmith-api-go"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/samber/lo"
@@ -168,8 +169,8 @@ func resourceWebhookCreate(d *schema.ResourceData, m interface{}) error {
 		RequestBodyTemplateFormat: expandRequestBodyTemplateFormat(d),
 		RequestContentType:        nullableString(d, "request_content_type"),
 		SecretHeader:              nullableString(d, "secret_header"),
-		SecretValue:               nullableString(d, "secret_value"),
-		SignatureKey:              optionalString(d, "signature_key"),
+		SecretValue:               *cloudsmith.NewNullableString(secretString(d, "secret_value", "secret_value_wo")),
+		SignatureKey:              secretString(d, "signature_key", "signature_key_wo"),
 		TargetUrl:                 requiredString(d, "target_url"),
 		Templates:                 expandTemplates(d),
 		VerifySsl:                 optionalBool(d, "verify_ssl"),
@@ -237,14 +238,35 @@ func resourceWebhookRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceWebhookUpdate(d *schema.ResourceData, m interface{}) error {
-	pc := m.(*providerConfig)
+// webhookSecretForUpdate returns the secret to send in a partial update. A
+// write-only secret is only sent when its version changes, otherwise the
+// stored secret is left as it is. unset reports that the plaintext secret was
+// removed from the configuration without moving to the write-only attribute,
+// so the stored secret should be cleared.
+func webhookSecretForUpdate(d *schema.ResourceData, name, woName, versionName string) (secret *string, unset bool) {
+	if s := optionalString(d, name); s != nil {
+		return s, false
+	}
+	if d.HasChange(versionName) {
+		return writeOnlyString(d, woName), false
+	}
+	return nil, d.HasChange(name) && writeOnlyString(d, woName) == nil
+}
 
-	namespace := requiredString(d, "namespace")
-	repository := requiredString(d, "repository")
+// expandWebhookPatch returns the partial update for the webhook. Secrets that
+// aren't being changed are left out, so the stored values are kept.
+func expandWebhookPatch(d *schema.ResourceData) cloudsmith.RepositoryWebhookRequestPatch {
+	// An unset NullableString is omitted from the request, whereas one set to
+	// nil clears the secret.
+	var secretValue cloudsmith.NullableString
+	if s, unset := webhookSecretForUpdate(d, "secret_value", "secret_value_wo", "secret_value_wo_version"); s != nil || unset {
+		secretValue.Set(s)
+	}
 
-	req := pc.APIClient.WebhooksApi.WebhooksPartialUpdate(pc.Auth, namespace, repository, d.Id())
-	req = req.Data(cloudsmith.RepositoryWebhookRequestPatch{
+	// signature_key isn't nullable in the API, so removing it can't clear it.
+	signatureKey, _ := webhookSecretForUpdate(d, "signature_key", "signature_key_wo", "signature_key_wo_version")
+
+	return cloudsmith.RepositoryWebhookRequestPatch{
 		Events:                    expandEvents(d),
 		IsActive:                  optionalBool(d, "is_active"),
 		PackageQuery:              nullableString(d, "package_query"),
@@ -252,12 +274,22 @@ func resourceWebhookUpdate(d *schema.ResourceData, m interface{}) error {
 		RequestBodyTemplateFormat: expandRequestBodyTemplateFormat(d),
 		RequestContentType:        nullableString(d, "request_content_type"),
 		SecretHeader:              nullableString(d, "secret_header"),
-		SecretValue:               nullableString(d, "secret_value"),
-		SignatureKey:              optionalString(d, "signature_key"),
+		SecretValue:               secretValue,
+		SignatureKey:              signatureKey,
 		TargetUrl:                 optionalString(d, "target_url"),
 		Templates:                 expandTemplates(d),
 		VerifySsl:                 optionalBool(d, "verify_ssl"),
-	})
+	}
+}
+
+func resourceWebhookUpdate(d *schema.ResourceData, m interface{}) error {
+	pc := m.(*providerConfig)
+
+	namespace := requiredString(d, "namespace")
+	repository := requiredString(d, "repository")
+
+	req := pc.APIClient.WebhooksApi.WebhooksPartialUpdate(pc.Auth, namespace, repository, d.Id())
+	req = req.Data(expandWebhookPatch(d))
 
 	webhook, _, err := pc.APIClient.WebhooksApi.WebhooksPartialUpdateExecute(req)
 	if err != nil {
@@ -308,6 +340,11 @@ func resourceWebhook() *schema.Resource {
 			StateContext: importWebhook,
 		},
 
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validation.PreferWriteOnlyAttribute(cty.GetAttrPath("secret_value"), cty.GetAttrPath("secret_value_wo")),
+			validation.PreferWriteOnlyAttribute(cty.GetAttrPath("signature_key"), cty.GetAttrPath("signature_key_wo")),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"created_at": {
 				Type:        schema.TypeString,
@@ -407,6 +444,10 @@ func resourceWebhook() *schema.Resource {
 				Sensitive:    true,
 				ValidateFunc: validation.StringIsNotEmpty,
 			},
+			"secret_value_wo":          writeOnlySecretSchema("The value for the predefined secret. You can use this as a form of authentication on the endpoint side.", "secret_value", "secret_value_wo_version"),
+			"secret_value_wo_version":  writeOnlyVersionSchema("secret_value_wo"),
+			"signature_key_wo":         writeOnlySecretSchema("The value for the signature key, used to generate the X-Cloudsmith-Signature header.", "signature_key", "signature_key_wo_version"),
+			"signature_key_wo_version": writeOnlyVersionSchema("signature_key_wo"),
 			"slug_perm": {
 				Type: schema.TypeString,
 				Description: "The slug_perm immutably identifies the webhook. " +
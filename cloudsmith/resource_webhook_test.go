@@ -2,11 +2,14 @@
 package cloudsmith
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -229,3 +232,54 @@ resource "cloudsmith_webhook" "test" {
 }
 `, repositoryName, os.Getenv("CLOUDSMITH_NAMESPACE"))
 }
+
+func TestExpandWebhookPatchSecretValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		state    map[string]string
+		config   map[string]interface{}
+		wantKey  bool
+		wantNull bool
+	}{
+		{name: "unchanged", config: map[string]interface{}{}, wantKey: false},
+		{name: "set", config: map[string]interface{}{"secret_value": "s3cret"}, wantKey: true},
+		{
+			name:     "removed",
+			state:    map[string]string{"secret_value": "s3cret"},
+			config:   map[string]interface{}{},
+			wantKey:  true,
+			wantNull: true,
+		},
+	}
+
+	for _, tc := range tests {
+		r := resourceWebhook()
+		state := &terraform.InstanceState{ID: "webhook", Attributes: tc.state}
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(tc.config), nil)
+		if err != nil {
+			t.Fatalf("%s: Diff() error = %v", tc.name, err)
+		}
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("%s: Data() error = %v", tc.name, err)
+		}
+
+		body, err := json.Marshal(expandWebhookPatch(d))
+		if err != nil {
+			t.Fatalf("%s: json.Marshal() error = %v", tc.name, err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			t.Fatalf("%s: json.Unmarshal() error = %v", tc.name, err)
+		}
+		value, ok := fields["secret_value"]
+		if ok != tc.wantKey {
+			t.Errorf("%s: PATCH body %s, want secret_value present %v", tc.name, body, tc.wantKey)
+		}
+		if ok && (value == nil) != tc.wantNull {
+			t.Errorf("%s: PATCH body %s, want secret_value null %v", tc.name, body, tc.wantNull)
+		}
+	}
+}
@@ -12,7 +12,9 @@ import (
 
 	"github.com/cloudsmith-io/cloudsmith-api-go"
 	v2apierrors "github.com/cloudsmith-io/cloudsmith-go-v2/models/apierrors"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 var (
@@ -174,18 +176,84 @@ func nullableString(d *schema.ResourceData, name string) cloudsmith.NullableStri
 	return *cloudsmith.NewNullableString(s)
 }
 
-func nullableTime(d *schema.ResourceData, name string) cloudsmith.NullableTime {
+// writeOnlyString retrieves a write-only string from the configuration.
+// Write-only values are never stored in state, so they are only available
+// while the configuration is being applied.
+func writeOnlyString(d *schema.ResourceData, name string) *string {
+	value, diags := d.GetRawConfigAt(cty.GetAttrPath(name))
+	if diags.HasError() || !value.IsKnown() || value.IsNull() || !value.Type().Equals(cty.String) {
+		return nil
+	}
+	if s := value.AsString(); s != "" {
+		return &s
+	}
+	return nil
+}
+
+// secretString retrieves a secret that can be configured either as the
+// plaintext attribute name or as the write-only attribute woName.
+func secretString(d *schema.ResourceData, name, woName string) *string {
+	if s := writeOnlyString(d, woName); s != nil {
+		return s
+	}
+	return optionalString(d, name)
+}
+
+// writeOnlySecretSchema returns the schema for the write-only alternative to
+// the plaintext secret attribute name. Changes to the value aren't detected, so
+// it requires versionName to be set, and rotating it means incrementing that.
+func writeOnlySecretSchema(description, name, versionName string) *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeString,
+		Description:   description + " This value is write-only and is never stored in state. Increment `" + versionName + "` to send a new value. Requires Terraform 1.11 or later.",
+		Optional:      true,
+		Sensitive:     true,
+		WriteOnly:     true,
+		ConflictsWith: []string{name},
+		RequiredWith:  []string{versionName},
+		ValidateFunc:  validation.StringIsNotEmpty,
+	}
+}
+
+// writeOnlyVersionSchema returns the schema for the version that triggers
+// sending the write-only attribute woName.
+func writeOnlyVersionSchema(woName string) *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Description:  "The version of `" + woName + "`. Increment this to send a new value of `" + woName + "`.",
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+	}
+}
+
+// nullableTime retrieves an optional/nullable RFC 3339 timestamp from
+// Terraform state.
+func nullableTime(d *schema.ResourceData, name string) (cloudsmith.NullableTime, error) {
 	s := optionalString(d, name)
 
 	if s == nil {
-		return *cloudsmith.NewNullableTime(nil)
+		return *cloudsmith.NewNullableTime(nil), nil
 	}
 	t, err := time.Parse(time.RFC3339, *s)
 	if err != nil {
-		panic(err)
+		return cloudsmith.NullableTime{}, fmt.Errorf("%q must be an RFC 3339 timestamp such as \"2026-01-01T00:00:00Z\", got: %s", name, *s)
 	}
 
-	return *cloudsmith.NewNullableTime(&t)
+	return *cloudsmith.NewNullableTime(&t), nil
+}
+
+// suppressEquivalentTimes suppresses diffs between RFC 3339 timestamps of the
+// same instant, such as 2026-01-01T00:00:00Z and 2026-01-01T01:00:00+01:00.
+func suppressEquivalentTimes(_, old, new string, _ *schema.ResourceData) bool {
+	o, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+	n, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+	return o.Equal(n)
 }
 
 // optionalBool retrieves an optional/nullable boolean from Terraform state
@@ -265,6 +333,15 @@ func stringSlicesAreEqual(x, y []string, sortSlices bool) bool {
 	return true
 }
 
+// triggerChanged reports whether the trigger attribute key of an existing
+// resource changes to a new value, or to one that isn't known until apply.
+func triggerChanged(d *schema.ResourceDiff, key string) bool {
+	if d.Id() == "" || !d.HasChange(key) {
+		return false
+	}
+	return !d.NewValueKnown(key) || d.Get(key).(string) != ""
+}
+
 // timeToString converts a time.Time object to a string
 func timeToString(t time.Time) string {
 	if t.IsZero() {
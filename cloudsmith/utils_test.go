@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	v2apierrors "github.com/cloudsmith-io/cloudsmith-go-v2/models/apierrors"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestFormatAPIError_Nil(t *testing.T) {
@@ -219,3 +222,118 @@ func TestOptionalInt64Unset(t *testing.T) {
 		t.Fatalf("expected unset value to be nil, got %d", *value)
 	}
 }
+
+func TestSecretString(t *testing.T) {
+	t.Parallel()
+
+	resource := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"secret": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"secret_wo":         writeOnlySecretSchema("A secret.", "secret", "secret_wo_version"),
+			"secret_wo_version": writeOnlyVersionSchema("secret_wo"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		secret   cty.Value
+		secretWo cty.Value
+		want     string
+	}{
+		{name: "write-only", secret: cty.NullVal(cty.String), secretWo: cty.StringVal("wo"), want: "wo"},
+		{name: "plaintext", secret: cty.StringVal("plain"), secretWo: cty.NullVal(cty.String), want: "plain"},
+		{name: "unset", secret: cty.NullVal(cty.String), secretWo: cty.NullVal(cty.String)},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := &terraform.InstanceState{
+				ID:         "secret",
+				Attributes: map[string]string{},
+				RawConfig: cty.ObjectVal(map[string]cty.Value{
+					"id":                cty.NullVal(cty.String),
+					"secret":            tc.secret,
+					"secret_wo":         tc.secretWo,
+					"secret_wo_version": cty.NumberIntVal(1),
+				}),
+			}
+			if !tc.secret.IsNull() {
+				state.Attributes["secret"] = tc.secret.AsString()
+			}
+
+			got := secretString(resource.Data(state), "secret", "secret_wo")
+			if tc.want == "" {
+				if got != nil {
+					t.Fatalf("expected nil, got %q", *got)
+				}
+				return
+			}
+			if got == nil || *got != tc.want {
+				t.Fatalf("expected %q, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNullableTime(t *testing.T) {
+	t.Parallel()
+
+	resource := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"at": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{"at": "2026-01-01T01:00:00+01:00"})
+	got, err := nullableTime(d, "at")
+	if err != nil {
+		t.Fatalf("nullableTime() error = %v", err)
+	}
+	if !got.IsSet() || !got.Get().Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("nullableTime() = %v, want 2026-01-01T00:00:00Z", got.Get())
+	}
+
+	d = schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{})
+	got, err = nullableTime(d, "at")
+	if err != nil {
+		t.Fatalf("nullableTime() error = %v", err)
+	}
+	if got.Get() != nil {
+		t.Fatalf("nullableTime() = %v, want nil", got.Get())
+	}
+
+	d = schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{"at": "2026-01-01"})
+	if _, err := nullableTime(d, "at"); err == nil {
+		t.Fatal("nullableTime() error = nil, want an error for a date without a time")
+	}
+}
+
+func TestSuppressEquivalentTimes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{old: "2026-01-01T00:00:00Z", new: "2026-01-01T01:00:00+01:00", want: true},
+		{old: "2026-01-01T00:00:00Z", new: "2026-01-01T00:00:00Z", want: true},
+		{old: "2026-01-01T00:00:00Z", new: "2026-01-01T00:00:00+01:00", want: false},
+		{old: "2026-01-01T00:00:00Z", new: "", want: false},
+		{old: "", new: "2026-01-01T00:00:00Z", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := suppressEquivalentTimes("at", tc.old, tc.new, nil); got != tc.want {
+			t.Errorf("suppressEquivalentTimes(%q, %q) = %v, want %v", tc.old, tc.new, got, tc.want)
+		}
+	}
+}